@@ -0,0 +1,36 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// requireAdmin wraps an admin endpoint so it only serves requests carrying
+// "Authorization: Bearer <ADMIN_TOKEN>". Admin endpoints are disabled when
+// no token is configured.
+func requireAdmin(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			respondWithAdminError(w, "admin endpoints are disabled, set ADMIN_TOKEN to enable them", http.StatusForbidden)
+			return
+		}
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			respondWithAdminError(w, "invalid or missing admin token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func respondWithAdminError(w http.ResponseWriter, message string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   message,
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// Handling of tool URLs whose host is only known at runtime, see
+// ConversionRequest.TemplatedHosts.
+const (
+	templatedHostsReject = "reject"
+	templatedHostsSkip   = "skip"
+)
+
+var templatedHostsModes = []string{templatedHostsReject, templatedHostsSkip}
+
+// validateAllowedHosts checks the allowed_hosts patterns and the
+// templated_hosts mode.
+func validateAllowedHosts(req ConversionRequest) error {
+	if req.TemplatedHosts != "" {
+		if len(req.AllowedHosts) == 0 {
+			return newAPIError(http.StatusBadRequest, "templated_hosts requires allowed_hosts")
+		}
+		if !containsString(templatedHostsModes, req.TemplatedHosts) {
+			return newAPIError(http.StatusBadRequest, "templated_hosts must be one of: %s", strings.Join(templatedHostsModes, ", "))
+		}
+	}
+	for _, pattern := range req.AllowedHosts {
+		if strings.TrimSpace(pattern) == "" {
+			return newAPIError(http.StatusBadRequest, "allowed_hosts must not contain empty entries")
+		}
+		if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+			return newAPIError(http.StatusBadRequest, "invalid allowed_hosts pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// checkAllowedHosts compares the host of every tool's URL with the
+// allowed_hosts patterns. It returns the tools that call other hosts and
+// the tools whose host is templated or missing, each as "tool (host)".
+func checkAllowedHosts(config *models.MCPConfig, patterns []string) (violations, unknown []string) {
+	for _, tool := range config.Tools {
+		host, ok := toolHost(tool.RequestTemplate.URL)
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("%s (%s)", tool.Name, tool.RequestTemplate.URL))
+			continue
+		}
+		if !hostAllowed(host, patterns) {
+			violations = append(violations, fmt.Sprintf("%s (%s)", tool.Name, host))
+		}
+	}
+	sort.Strings(violations)
+	sort.Strings(unknown)
+	return violations, unknown
+}
+
+// toolHost returns the lower case host of a tool URL, without its port. ok
+// is false when the host holds a template or the URL has no host.
+func toolHost(rawURL string) (host string, ok bool) {
+	authority := rawURL
+	if i := strings.Index(authority, "://"); i >= 0 {
+		authority = authority[i+3:]
+	}
+	if i := strings.IndexAny(authority, "/?#"); i >= 0 {
+		authority = authority[:i]
+	}
+	if strings.ContainsAny(authority, "{}") {
+		return "", false
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return "", false
+	}
+	return strings.ToLower(parsed.Hostname()), true
+}
+
+// hostAllowed reports whether a host matches one of the patterns. Patterns
+// are hostnames or globs such as *.example.com, compared case-insensitively.
+func hostAllowed(host string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(strings.TrimSpace(pattern)), host); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Values of ConversionRequest.OutputFormat
+const (
+	outputFormatJSON              = "json"
+	outputFormatGitHubAnnotations = "github-annotations"
+)
+
+var outputFormats = []string{outputFormatJSON, outputFormatGitHubAnnotations}
+
+// annotationTitle is the title of every annotation, shown above its message
+// on the pull request diff.
+const annotationTitle = "MCP conversion"
+
+var (
+	// operationIDLinePattern matches the operationId of an operation in a
+	// JSON or YAML spec.
+	operationIDLinePattern = regexp.MustCompile(`^\s*"?operationId"?\s*:\s*"?([^",\s]+)`)
+	// pathLinePattern matches a path key of the paths object.
+	pathLinePattern = regexp.MustCompile(`^\s*"?(/[^":\s]*)"?\s*:`)
+	// messageLinePattern matches the line number parse errors carry.
+	messageLinePattern = regexp.MustCompile(`\bline (\d+)\b`)
+)
+
+// specLocations maps the operationIds and paths of a spec to the line they
+// are declared on, so annotations can point at them.
+type specLocations struct {
+	lines  map[string]int
+	prefix string
+}
+
+func newSpecLocations(spec, toolPrefix string) *specLocations {
+	locations := &specLocations{lines: make(map[string]int), prefix: toolPrefix}
+	for i, line := range strings.Split(spec, "\n") {
+		for _, pattern := range []*regexp.Regexp{operationIDLinePattern, pathLinePattern} {
+			if match := pattern.FindStringSubmatch(line); match != nil {
+				if _, ok := locations.lines[match[1]]; !ok {
+					locations.lines[match[1]] = i + 1
+				}
+			}
+		}
+	}
+	return locations
+}
+
+// line returns the spec line a message refers to, 0 when it names no
+// operation or path of the spec. A line number in the message, as parse
+// errors have, takes precedence.
+func (l *specLocations) line(message string) int {
+	if match := messageLinePattern.FindStringSubmatch(message); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return line
+	}
+	for _, word := range strings.Fields(message) {
+		word = strings.Trim(word, "\"'`(),:;")
+		if line, ok := l.lines[word]; ok {
+			return line
+		}
+		if l.prefix == "" {
+			continue
+		}
+		if line, ok := l.lines[strings.TrimPrefix(word, l.prefix)]; ok {
+			return line
+		}
+	}
+	return 0
+}
+
+// workflowCommand formats a GitHub Actions workflow command such as
+// ::warning file=openapi.yaml,line=12,title=MCP conversion::message
+func workflowCommand(command, file string, line int, message string) string {
+	var properties []string
+	if file != "" {
+		properties = append(properties, "file="+escapeAnnotationProperty(file))
+		if line > 0 {
+			properties = append(properties, "line="+strconv.Itoa(line))
+		}
+	}
+	properties = append(properties, "title="+escapeAnnotationProperty(annotationTitle))
+	return fmt.Sprintf("::%s %s::%s", command, strings.Join(properties, ","), escapeAnnotationData(message))
+}
+
+// escapeAnnotationData escapes a workflow command message.
+func escapeAnnotationData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// escapeAnnotationProperty escapes a workflow command property value.
+func escapeAnnotationProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
+
+// respondWithAnnotations writes the warnings and error of a conversion as
+// workflow commands, one per line, for CI to print as they are.
+func respondWithAnnotations(w http.ResponseWriter, status int, req ConversionRequest, spec string, warnings []string, errorMessage string) {
+	locations := newSpecLocations(spec, req.ToolPrefix)
+	var body strings.Builder
+	for _, warning := range warnings {
+		body.WriteString(workflowCommand("warning", req.AnnotationFile, locations.line(warning), warning))
+		body.WriteString("\n")
+	}
+	if errorMessage != "" {
+		body.WriteString(workflowCommand("error", req.AnnotationFile, locations.line(errorMessage), errorMessage))
+		body.WriteString("\n")
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(body.String()))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Response schema versions selected with the X-API-Version request header.
+//
+// Version 1 is the original conversion response: success, error, format,
+// server_name, mcp_config, openapi_file_url and mcp_config_file_url.
+//
+// Version 2 adds warnings, diagnostics_url, bundle_url, dry_run, changelog,
+// changelog_url, coverage and coverage_url.
+//
+// Version 3 adds tool_cache, effective_options, files, package, pipeline,
+// signature, resource_stats, cached and description_languages.
+const (
+	apiVersion1      = 1
+	apiVersion2      = 2
+	apiVersion3      = 3
+	latestAPIVersion = apiVersion3
+)
+
+// requestedAPIVersion returns the response schema version asked for by the
+// client, defaulting to the latest.
+func requestedAPIVersion(r *http.Request) (int, error) {
+	header := r.Header.Get("X-API-Version")
+	if header == "" {
+		return latestAPIVersion, nil
+	}
+	version, err := strconv.Atoi(header)
+	if err != nil || version < apiVersion1 || version > latestAPIVersion {
+		return 0, newAPIError(http.StatusBadRequest, "unsupported X-API-Version %q, supported versions are 1 to %d", header, latestAPIVersion)
+	}
+	return version, nil
+}
+
+// forVersion returns a copy of the response with only the fields that exist
+// in the given schema version.
+func (r ConversionResponse) forVersion(version int) ConversionResponse {
+	if version < apiVersion2 {
+		r.Warnings = nil
+		r.DiagnosticsURL = ""
+		r.BundleURL = ""
+		r.DryRun = false
+		r.Changelog = nil
+		r.ChangelogURL = ""
+		r.Coverage = nil
+		r.CoverageURL = ""
+	}
+	if version < apiVersion3 {
+		r.ToolCache = nil
+		r.EffectiveOptions = nil
+		r.Files = nil
+		r.Package = nil
+		r.Pipeline = nil
+		r.Signature = nil
+		r.ResourceStats = nil
+		r.Cached = false
+		r.DescriptionLanguages = nil
+	}
+	return r
+}
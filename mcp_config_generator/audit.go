@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+)
+
+// AuditRecord is the immutable record of one conversion.
+type AuditRecord struct {
+	Time       string `json:"time"`
+	RequestID  string `json:"request_id,omitempty"`
+	APIKeyID   string `json:"api_key_id,omitempty"`
+	ServerName string `json:"server_name"`
+	SpecSHA256 string `json:"spec_sha256"`
+	SpecBytes  int    `json:"spec_bytes"`
+	// Options is the request without the spec and template bodies
+	Options          ConversionRequest `json:"options"`
+	Success          bool              `json:"success"`
+	Status           int               `json:"status"`
+	Error            string            `json:"error,omitempty"`
+	MCPConfigFileURL string            `json:"mcp_config_file_url,omitempty"`
+	DurationMs       int64             `json:"duration_ms"`
+}
+
+// auditLog writes an AuditRecord for every conversion to a bucket prefix or
+// a webhook. Records are written once and never modified: in a bucket each
+// one is its own object under a daily partition, since storage objects
+// can't be appended to safely from several instances.
+type auditLog struct {
+	// bucket and prefix are set for storage destinations
+	bucket string
+	prefix string
+	// webhookURL is set for HTTP destinations
+	webhookURL string
+	secret     string
+}
+
+// newAuditLogFromEnv parses AUDIT_LOG_DESTINATION: "bucket" for the audit/
+// prefix of the service bucket, gs://<bucket>/<prefix> for another bucket or
+// prefix, or an http(s) URL that receives each record as a POST. It returns
+// nil when audit logging is disabled.
+func newAuditLogFromEnv(serviceBucket, secret string) (*auditLog, error) {
+	destination := os.Getenv("AUDIT_LOG_DESTINATION")
+	switch {
+	case destination == "":
+		return nil, nil
+	case destination == "bucket":
+		return &auditLog{bucket: serviceBucket, prefix: "audit/"}, nil
+	case strings.HasPrefix(destination, "gs://"):
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(destination, "gs://"), "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("AUDIT_LOG_DESTINATION %q has no bucket", destination)
+		}
+		if prefix != "" && !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+		return &auditLog{bucket: bucket, prefix: prefix}, nil
+	case strings.HasPrefix(destination, "https://") || strings.HasPrefix(destination, "http://"):
+		return &auditLog{webhookURL: destination, secret: secret}, nil
+	default:
+		return nil, fmt.Errorf("AUDIT_LOG_DESTINATION must be \"bucket\", a gs:// URI or an http(s) URL, got %q", destination)
+	}
+}
+
+// String describes the destination for the startup log.
+func (a *auditLog) String() string {
+	if a.webhookURL != "" {
+		return a.webhookURL
+	}
+	return fmt.Sprintf("gs://%s/%s", a.bucket, a.prefix)
+}
+
+type apiKeyIDKey struct{}
+
+// withAPIKeyID stores a hash identifying the caller's API key in the request
+// context, taken from X-API-Key or a bearer token. The raw key is never
+// kept.
+func withAPIKeyID(r *http.Request) context.Context {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); token != r.Header.Get("Authorization") {
+			key = token
+		}
+	}
+	if key == "" {
+		return r.Context()
+	}
+	sum := sha256.Sum256([]byte(key))
+	return context.WithValue(r.Context(), apiKeyIDKey{}, hex.EncodeToString(sum[:8]))
+}
+
+// recordAudit builds the audit record of a finished conversion and writes it.
+// Failures are logged and never affect the conversion.
+func (s *ConversionService) recordAudit(ctx context.Context, req ConversionRequest, response *ConversionResponse, err error, started time.Time) {
+	if s.audit == nil {
+		return
+	}
+
+	specHash := sha256.Sum256([]byte(req.OpenAPISpec))
+	record := AuditRecord{
+		Time:       started.UTC().Format(time.RFC3339Nano),
+		RequestID:  requestIDFromContext(ctx),
+		ServerName: req.ServerName,
+		SpecSHA256: hex.EncodeToString(specHash[:]),
+		SpecBytes:  len(req.OpenAPISpec),
+		Options:    effectiveOptions(req),
+		Success:    err == nil,
+		Status:     http.StatusOK,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	record.APIKeyID, _ = ctx.Value(apiKeyIDKey{}).(string)
+	if err != nil {
+		record.Status = errorStatus(err)
+		record.Error = err.Error()
+	}
+	if response != nil {
+		record.MCPConfigFileURL = response.MCPConfigFileURL
+	}
+
+	// The request context may already be canceled once the response is sent
+	writeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.writeAudit(writeCtx, record, started); err != nil {
+		s.stats.Incr("audit.failure")
+		log.Printf("Warning: Failed to write audit record for request %s: %v", record.RequestID, err)
+	}
+}
+
+// writeAudit sends a record to the configured destination.
+func (s *ConversionService) writeAudit(ctx context.Context, record AuditRecord, started time.Time) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if s.audit.webhookURL != "" {
+		return s.audit.post(ctx, data)
+	}
+
+	id := record.RequestID
+	if id == "" {
+		id = newRequestID()
+	}
+	objectName := fmt.Sprintf("%sdate=%s/%s-%s.json", s.audit.prefix, started.UTC().Format("2006-01-02"), started.UTC().Format("150405.000000000"), id)
+	if s.storageDryRun {
+		log.Printf("Dry run: would write audit record gs://%s/%s", s.audit.bucket, objectName)
+		return nil
+	}
+
+	// Records are private and never overwritten
+	writer := s.storageClient.Bucket(s.audit.bucket).Object(objectName).If(storage.Conditions{DoesNotExist: true}).NewWriter(ctx)
+	writer.ContentType = "application/json"
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// post sends a record to the webhook destination, signed like the other
+// webhooks when WEBHOOK_SECRET is set.
+func (a *auditLog) post(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.secret != "" {
+		mac := hmac.New(sha256.New, []byte(a.secret))
+		mac.Write(data)
+		req.Header.Set("X-Signature-SHA256", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit webhook returned %s", resp.Status)
+	}
+	return nil
+}
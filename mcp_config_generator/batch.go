@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type BatchConversionRequest struct {
+	Conversions []ConversionRequest `json:"conversions"`
+	Manifest    bool                `json:"manifest,omitempty"`
+}
+
+type BatchConversionResponse struct {
+	Success           bool                 `json:"success"`
+	Error             string               `json:"error,omitempty"`
+	Results           []ConversionResponse `json:"results"`
+	ManifestURL       string               `json:"manifest_url,omitempty"`
+	ManifestSignature string               `json:"manifest_signature,omitempty"`
+}
+
+// BatchManifest lists every MCP config produced by a batch so consumers can
+// verify the integrity of the files they download.
+type BatchManifest struct {
+	GeneratedAt string          `json:"generated_at"`
+	Entries     []ManifestEntry `json:"entries"`
+}
+
+type ManifestEntry struct {
+	ServerName string `json:"server_name"`
+	ObjectName string `json:"object_name"`
+	URL        string `json:"url"`
+	SHA256     string `json:"sha256"`
+}
+
+func (s *ConversionService) handleBatchConvert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	version, err := requestedAPIVersion(r)
+	if err != nil {
+		respondWithBatchError(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("X-API-Version", strconv.Itoa(version))
+
+	// Parse JSON request
+	var req BatchConversionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithBatchError(w, requestDecodeError(err), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Conversions) == 0 {
+		respondWithBatchError(w, "conversions must contain at least one entry", http.StatusBadRequest)
+		return
+	}
+
+	ctx := withAPIKeyID(r)
+
+	// Convert each entry independently so one bad spec doesn't fail the batch
+	response := BatchConversionResponse{
+		Success: true,
+		Results: make([]ConversionResponse, 0, len(req.Conversions)),
+	}
+	for _, conversion := range req.Conversions {
+		result, err := s.processConversion(ctx, conversion)
+		if err != nil {
+			response.Success = false
+			if result != nil {
+				response.Results = append(response.Results, *result)
+				continue
+			}
+			response.Results = append(response.Results, ConversionResponse{
+				Success:    false,
+				Error:      err.Error(),
+				ServerName: conversion.ServerName,
+			})
+			continue
+		}
+		response.Results = append(response.Results, *result)
+	}
+
+	if req.Manifest {
+		manifestURL, signature, err := s.storeBatchManifest(ctx, response.Results)
+		if err != nil {
+			respondWithBatchError(w, fmt.Sprintf("Failed to save batch manifest: %v", err), storageErrorStatus(err))
+			return
+		}
+		response.ManifestURL = manifestURL
+		response.ManifestSignature = signature
+	}
+
+	for i := range response.Results {
+		response.Results[i] = response.Results[i].forVersion(version)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// manifestSignatureMetadata is the object metadata holding a stored
+// manifest's signature, served as the x-goog-meta-hmac-sha256 header.
+const manifestSignatureMetadata = "hmac-sha256"
+
+// storeBatchManifest writes a manifest of the successful results to the
+// bucket. When WEBHOOK_SECRET is set the stored manifest bytes are signed with
+// HMAC-SHA256; the hex signature is stored in the object's metadata and
+// returned alongside the URL.
+func (s *ConversionService) storeBatchManifest(ctx context.Context, results []ConversionResponse) (string, string, error) {
+	name, data, signature, err := buildBatchManifest(results, time.Now(), s.webhookSecret)
+	if err != nil {
+		return "", "", err
+	}
+
+	var opts storageOptions
+	if signature != "" {
+		opts.Headers = map[string]string{manifestSignatureMetadata: signature}
+	}
+	manifestURL, err := s.saveToStorage(ctx, name, data, "application/json", opts)
+	if err != nil {
+		return "", "", err
+	}
+	return manifestURL, signature, nil
+}
+
+// buildBatchManifest returns the object name, content and signature, empty
+// without a secret, of the manifest of the successful results. The name ends
+// with a hash of the content so batches finishing in the same second don't
+// overwrite each other's manifest.
+func buildBatchManifest(results []ConversionResponse, now time.Time, secret string) (string, []byte, string, error) {
+	manifest := BatchManifest{
+		GeneratedAt: now.UTC().Format(time.RFC3339),
+		Entries:     []ManifestEntry{},
+	}
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+		checksum := sha256.Sum256([]byte(result.MCPConfig))
+		manifest.Entries = append(manifest.Entries, ManifestEntry{
+			ServerName: result.ServerName,
+			ObjectName: result.mcpConfigObject,
+			URL:        result.MCPConfigFileURL,
+			SHA256:     hex.EncodeToString(checksum[:]),
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", nil, "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	checksum := sha256.Sum256(data)
+	name := fmt.Sprintf("manifests/batch-%s-%s.json", now.Format("20060102-150405"), hex.EncodeToString(checksum[:8]))
+
+	var signature string
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(data)
+		signature = hex.EncodeToString(mac.Sum(nil))
+	}
+	return name, data, signature, nil
+}
+
+func respondWithBatchError(w http.ResponseWriter, message string, statusCode int) {
+	response := BatchConversionResponse{
+		Success: false,
+		Error:   message,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestBuildBatchManifest(t *testing.T) {
+	now := time.Date(2024, 5, 7, 9, 30, 0, 0, time.Local)
+	orders := []ConversionResponse{
+		{Success: true, ServerName: "orders", MCPConfig: "server:\n  name: orders\n", MCPConfigFileURL: "https://storage.googleapis.com/bucket/mcp-configs/orders.yaml", mcpConfigObject: "mcp-configs/orders.yaml"},
+		{Success: false, ServerName: "broken", Error: "invalid spec"},
+	}
+	users := []ConversionResponse{
+		{Success: true, ServerName: "users", MCPConfig: "server:\n  name: users\n", mcpConfigObject: "mcp-configs/users.yaml"},
+	}
+
+	name, data, signature, err := buildBatchManifest(orders, now, "secret")
+	if err != nil {
+		t.Fatalf("buildBatchManifest: %v", err)
+	}
+	if !regexp.MustCompile(`^manifests/batch-20240507-093000-[0-9a-f]{16}\.json$`).MatchString(name) {
+		t.Errorf("name = %q, want manifests/batch-<timestamp>-<hash>.json", name)
+	}
+	var manifest BatchManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("failed to decode the manifest: %v", err)
+	}
+	if len(manifest.Entries) != 1 || manifest.Entries[0].ObjectName != "mcp-configs/orders.yaml" {
+		t.Errorf("entries = %+v, want only the successful orders config", manifest.Entries)
+	}
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(data)
+	if expected := hex.EncodeToString(mac.Sum(nil)); signature != expected {
+		t.Errorf("signature = %q, want the HMAC of the manifest %q", signature, expected)
+	}
+
+	// Another batch in the same second gets its own manifest
+	other, _, _, err := buildBatchManifest(users, now, "secret")
+	if err != nil {
+		t.Fatalf("buildBatchManifest: %v", err)
+	}
+	if other == name {
+		t.Errorf("batches in the same second share the manifest %s", name)
+	}
+
+	if _, _, signature, _ := buildBatchManifest(orders, now, ""); signature != "" {
+		t.Errorf("signature without WEBHOOK_SECRET = %q, want none", signature)
+	}
+}
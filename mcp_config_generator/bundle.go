@@ -0,0 +1,112 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// supportedFormats are the MCP config output formats.
+var supportedFormats = []string{"yaml", "json"}
+
+// bundleFile is a single entry of a distribution bundle.
+type bundleFile struct {
+	Name        string
+	Description string
+	Data        []byte
+}
+
+// buildBundleFiles renders the config in every requested format and adds the
+// source spec, in the order they should appear in the bundle.
+func buildBundleFiles(req ConversionRequest, output *conversionOutput) ([]bundleFile, error) {
+	formats := req.BundleFormats
+	if len(formats) == 0 {
+		formats = supportedFormats
+	}
+
+	var files []bundleFile
+	for _, format := range formats {
+		data, err := marshalConfig(output.MCPConfig, format, req.JSONIndent.String())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, bundleFile{
+			Name:        fmt.Sprintf("%s.%s", req.ServerName, format),
+			Description: fmt.Sprintf("MCP server configuration (%s)", strings.ToUpper(format)),
+			Data:        data,
+		})
+	}
+
+	files = append(files, bundleFile{
+		Name:        fmt.Sprintf("openapi.%s", detectMCPFormat(req.OpenAPISpec)),
+		Description: "Source OpenAPI specification",
+		Data:        []byte(req.OpenAPISpec),
+	})
+
+	return files, nil
+}
+
+// writeZipBundle packages the files into a zip archive with a README listing
+// its contents.
+func writeZipBundle(serverName string, files []bundleFile, created time.Time) ([]byte, error) {
+	var readme strings.Builder
+	readme.WriteString(fmt.Sprintf("# %s MCP bundle\n\n", serverName))
+	readme.WriteString(fmt.Sprintf("Generated at %s by the OpenAPI to MCP converter.\n\n", created.UTC().Format(time.RFC3339)))
+	readme.WriteString("## Contents\n\n")
+	for _, file := range files {
+		readme.WriteString(fmt.Sprintf("- `%s` - %s\n", file.Name, file.Description))
+	}
+
+	entries := append([]bundleFile{{Name: "README.md", Data: []byte(readme.String())}}, files...)
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, entry := range entries {
+		writer, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     entry.Name,
+			Method:   zip.Deflate,
+			Modified: created,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to bundle: %w", entry.Name, err)
+		}
+		if _, err := writer.Write(entry.Data); err != nil {
+			return nil, fmt.Errorf("failed to write %s to bundle: %w", entry.Name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// storeBundle builds the zip bundle for a conversion and stores it under
+// bundles/<server>-<timestamp>.zip, or the split bundle under
+// bundles/<server>-<timestamp>.tar.gz, returning its URL.
+func (s *ConversionService) storeBundle(ctx context.Context, req ConversionRequest, output *conversionOutput, timestamp string, opts storageOptions) (string, error) {
+	if req.SplitBy != "" {
+		data, err := writeSplitBundle(req, output.MCPConfig)
+		if err != nil {
+			return "", err
+		}
+		fileName := fmt.Sprintf("bundles/%s-%s.tar.gz", req.ServerName, timestamp)
+		return s.saveToStorage(ctx, fileName, data, "application/gzip", opts)
+	}
+
+	files, err := buildBundleFiles(req, output)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := writeZipBundle(req.ServerName, files, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	fileName := fmt.Sprintf("bundles/%s-%s.zip", req.ServerName, timestamp)
+	return s.saveToStorage(ctx, fileName, data, "application/zip", opts)
+}
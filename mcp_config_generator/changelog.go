@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+	"google.golang.org/api/iterator"
+	"gopkg.in/yaml.v3"
+)
+
+// Changelog lists the tool changes between a conversion and the previous
+// conversion of the same server. All lists are empty for the first
+// conversion of a server.
+type Changelog struct {
+	PreviousConfig string         `json:"previous_config,omitempty"`
+	Added          []string       `json:"added"`
+	Removed        []string       `json:"removed"`
+	Modified       []ToolModified `json:"modified"`
+}
+
+// ToolModified describes which parts of a tool changed.
+type ToolModified struct {
+	Name    string   `json:"name"`
+	Changes []string `json:"changes"`
+}
+
+// previousConfigPattern matches the object names of stored MCP configs for
+// serverName with one of extensions, so other objects such as signatures
+// are never taken for a config.
+func previousConfigPattern(serverName string, extensions []string) *regexp.Regexp {
+	quoted := make([]string, len(extensions))
+	for i, extension := range extensions {
+		quoted[i] = regexp.QuoteMeta(extension)
+	}
+	return regexp.MustCompile(`^mcp-configs/` + regexp.QuoteMeta(serverName) + `-\d{8}-\d{6}\.(` + strings.Join(quoted, "|") + `)$`)
+}
+
+// findPreviousConfig returns the object name of the most recent stored MCP
+// config for serverName with one of extensions, or "" when there is none.
+// Object names end with a sortable timestamp, so the greatest name is the
+// most recent.
+func (s *ConversionService) findPreviousConfig(ctx context.Context, serverName string, extensions []string) (string, error) {
+	pattern := previousConfigPattern(serverName, extensions)
+
+	var latest string
+	it := s.storageClient.Bucket(s.bucketName).Objects(ctx, &storage.Query{Prefix: "mcp-configs/" + serverName + "-"})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to list previous configs: %w", err)
+		}
+		if pattern.MatchString(attrs.Name) && attrs.Name > latest {
+			latest = attrs.Name
+		}
+	}
+	return latest, nil
+}
+
+// loadStoredConfig reads and parses a stored MCP config.
+func (s *ConversionService) loadStoredConfig(ctx context.Context, objectName string) (*models.MCPConfig, error) {
+	reader, err := s.storageClient.Bucket(s.bucketName).Object(objectName).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", objectName, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", objectName, err)
+	}
+
+	// JSON configs are written with the Go field names, so they are decoded
+	// with encoding/json rather than through the YAML tags. The extension
+	// can be overridden, so JSON is recognised by its content.
+	var config models.MCPConfig
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = json.Unmarshal(data, &config)
+	} else {
+		err = yaml.Unmarshal(data, &config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", objectName, err)
+	}
+	return &config, nil
+}
+
+// buildChangelog compares the tools of a conversion with the previous
+// conversion of the same server, stored with one of extensions.
+func (s *ConversionService) buildChangelog(ctx context.Context, serverName string, extensions []string, current *models.MCPConfig) (*Changelog, error) {
+	changelog := &Changelog{
+		Added:    []string{},
+		Removed:  []string{},
+		Modified: []ToolModified{},
+	}
+
+	previousName, err := s.findPreviousConfig(ctx, serverName, extensions)
+	if err != nil {
+		return nil, err
+	}
+	if previousName == "" {
+		return changelog, nil
+	}
+
+	previous, err := s.loadStoredConfig(ctx, previousName)
+	if err != nil {
+		return nil, err
+	}
+	changelog.PreviousConfig = previousName
+
+	diffTools(changelog, previous.Tools, current.Tools)
+	return changelog, nil
+}
+
+// diffTools fills the changelog with the tools added, removed and modified
+// between previous and current.
+func diffTools(changelog *Changelog, previous, current []models.Tool) {
+	previousTools := make(map[string]models.Tool)
+	for _, tool := range previous {
+		previousTools[tool.Name] = tool
+	}
+
+	currentNames := make(map[string]bool)
+	for _, tool := range current {
+		currentNames[tool.Name] = true
+		old, ok := previousTools[tool.Name]
+		if !ok {
+			changelog.Added = append(changelog.Added, tool.Name)
+			continue
+		}
+		if changes := toolChanges(old, tool); len(changes) > 0 {
+			changelog.Modified = append(changelog.Modified, ToolModified{Name: tool.Name, Changes: changes})
+		}
+	}
+	for _, tool := range previous {
+		if !currentNames[tool.Name] {
+			changelog.Removed = append(changelog.Removed, tool.Name)
+		}
+	}
+
+	sort.Strings(changelog.Added)
+	sort.Strings(changelog.Removed)
+	sort.Slice(changelog.Modified, func(i, j int) bool {
+		return changelog.Modified[i].Name < changelog.Modified[j].Name
+	})
+}
+
+// toolChanges names the parts of a tool that differ. Both tools are compared
+// in their YAML form so that configs decoded from either format compare
+// equal when their content is the same.
+func toolChanges(previous, current models.Tool) []string {
+	parts := []struct {
+		name           string
+		previous, next interface{}
+	}{
+		{"description", previous.Description, current.Description},
+		{"arguments", previous.Args, current.Args},
+		{"request template", previous.RequestTemplate, current.RequestTemplate},
+		{"response template", previous.ResponseTemplate, current.ResponseTemplate},
+		{"security", previous.Security, current.Security},
+		{"tags", previous.Tags, current.Tags},
+	}
+
+	var changes []string
+	for _, part := range parts {
+		if !sameYAML(part.previous, part.next) {
+			changes = append(changes, part.name)
+		}
+	}
+	return changes
+}
+
+func sameYAML(a, b interface{}) bool {
+	left, errLeft := yaml.Marshal(a)
+	right, errRight := yaml.Marshal(b)
+	if errLeft != nil || errRight != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return string(left) == string(right)
+}
+
+// Markdown renders the changelog for release notes.
+func (c *Changelog) Markdown(serverName string, created time.Time) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# %s changelog\n\n", serverName))
+	b.WriteString(fmt.Sprintf("Generated at %s.\n\n", created.UTC().Format(time.RFC3339)))
+
+	if c.PreviousConfig == "" {
+		b.WriteString("First conversion of this server, no previous config to compare with.\n")
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("Compared with `%s`.\n\n", c.PreviousConfig))
+
+	if len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0 {
+		b.WriteString("No tool changes.\n")
+		return b.String()
+	}
+
+	writeSection := func(title string, names []string) {
+		if len(names) == 0 {
+			return
+		}
+		b.WriteString(fmt.Sprintf("## %s\n\n", title))
+		for _, name := range names {
+			b.WriteString(fmt.Sprintf("- `%s`\n", name))
+		}
+		b.WriteString("\n")
+	}
+	writeSection("Added", c.Added)
+	writeSection("Removed", c.Removed)
+	if len(c.Modified) > 0 {
+		b.WriteString("## Modified\n\n")
+		for _, tool := range c.Modified {
+			b.WriteString(fmt.Sprintf("- `%s`: %s\n", tool.Name, strings.Join(tool.Changes, ", ")))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// storeChangelog writes the Markdown changelog to
+// changelogs/<server>-<timestamp>.md and returns its URL.
+func (s *ConversionService) storeChangelog(ctx context.Context, serverName string, changelog *Changelog, timestamp string, opts storageOptions) (string, error) {
+	fileName := fmt.Sprintf("changelogs/%s-%s.md", serverName, timestamp)
+	return s.saveToStorage(ctx, fileName, []byte(changelog.Markdown(serverName, time.Now())), "text/markdown", opts)
+}
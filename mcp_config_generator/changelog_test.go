@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestPreviousConfigPattern(t *testing.T) {
+	service := &ConversionService{outputExtensions: map[string]string{"yaml": "yml"}}
+	pattern := previousConfigPattern("petstore", service.configExtensions(ConversionRequest{Format: "json", OutputExtension: "mcp.json"}))
+
+	for name, matches := range map[string]bool{
+		"mcp-configs/petstore-20261015-120000.yaml":     true,
+		"mcp-configs/petstore-20261015-120000.json":     true,
+		"mcp-configs/petstore-20261015-120000.yml":      true,
+		"mcp-configs/petstore-20261015-120000.mcp.json": true,
+		"mcp-configs/petstore-20261015-120000.yaml.sig": false,
+		"signatures/petstore-20261015-120000.yaml.sig":  false,
+		"mcp-configs/petstore-20261015-120000.txt":      false,
+		"mcp-configs/petstore-v2-20261015-120000.yaml":  false,
+		"mcp-configs/petstore-20261015.yaml":            false,
+	} {
+		if got := pattern.MatchString(name); got != matches {
+			t.Errorf("pattern matches %s = %v, want %v", name, got, matches)
+		}
+	}
+
+	if object := signatureObject("mcp-configs/petstore-20261015-120000.yaml"); object != "signatures/petstore-20261015-120000.yaml.sig" {
+		t.Errorf("signatureObject = %q", object)
+	}
+}
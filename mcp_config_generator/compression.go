@@ -0,0 +1,173 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressionEncodings are the response encodings offered, in order of
+// preference when the client accepts several equally.
+var compressionEncodings = []string{"zstd", "gzip"}
+
+// incompressibleTypes are content types that are already compressed.
+var incompressibleTypes = []string{"application/zip", "application/gzip", "application/x-gzip", "application/zstd", "image/", "video/", "audio/"}
+
+// compressor is the interface shared by the gzip and zstd writers.
+type compressor interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// Encoders are pooled since a zstd encoder allocates its window up front.
+var compressorPools = map[string]*sync.Pool{
+	"gzip": {New: func() interface{} {
+		return gzip.NewWriter(nil)
+	}},
+	"zstd": {New: func() interface{} {
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			panic(err)
+		}
+		return encoder
+	}},
+}
+
+// negotiateEncoding picks the response encoding for an Accept-Encoding
+// header: the supported encoding with the highest q-value, or "" for
+// identity when none is accepted or identity is explicitly preferred.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				parsed = 0
+			}
+			quality = parsed
+		}
+		qualities[name] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, encoding := range compressionEncodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	if identity, ok := qualities["identity"]; ok && identity > bestQuality {
+		return ""
+	}
+	return best
+}
+
+// withCompression compresses response bodies with zstd or gzip, whichever
+// the client's Accept-Encoding prefers, and leaves them as they are for
+// clients that accept neither.
+func withCompression(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressResponseWriter compresses the body once the response headers show
+// it is worth compressing. Headers are shared with the underlying writer.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	compressor  compressor
+	wroteHeader bool
+}
+
+func (c *compressResponseWriter) WriteHeader(status int) {
+	if c.wroteHeader {
+		return
+	}
+	c.wroteHeader = true
+
+	header := c.Header()
+	if status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified &&
+		header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", c.encoding)
+		header.Del("Content-Length")
+		c.compressor = compressorPools[c.encoding].Get().(compressor)
+		c.compressor.Reset(c.ResponseWriter)
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *compressResponseWriter) Write(data []byte) (int, error) {
+	if !c.wroteHeader {
+		// Sniff the type as net/http would, before the body is compressed
+		if c.Header().Get("Content-Type") == "" {
+			c.Header().Set("Content-Type", http.DetectContentType(data))
+		}
+		c.WriteHeader(http.StatusOK)
+	}
+	if c.compressor == nil {
+		return c.ResponseWriter.Write(data)
+	}
+	return c.compressor.Write(data)
+}
+
+// Flush sends what has been compressed so far, for streamed responses.
+func (c *compressResponseWriter) Flush() {
+	if c.compressor != nil {
+		c.compressor.Flush()
+	}
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// close finishes the compressed stream and returns its encoder to the pool.
+func (c *compressResponseWriter) close() {
+	if c.compressor == nil {
+		return
+	}
+	c.compressor.Close()
+	c.compressor.Reset(nil)
+	compressorPools[c.encoding].Put(c.compressor)
+	c.compressor = nil
+}
+
+// compressible reports whether a body of the content type gains from
+// compression.
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/converter"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// converterOptionSetters maps the converter_options keys to the
+// models.ConvertOptions fields they set. ServerName, ToolNamePrefix and
+// TemplatePath are not listed: they have dedicated request fields, and
+// TemplatePath names a file on the server.
+var converterOptionSetters = map[string]func(opts *models.ConvertOptions, value json.RawMessage) error{
+	"server_config": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.ServerConfig)
+	},
+	"require_success_response": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.RequireSuccessResponse)
+	},
+	"description_template": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			return err
+		}
+		tmpl, err := template.New("description").Parse(text)
+		if err != nil {
+			return err
+		}
+		opts.DescriptionTemplate = tmpl
+		return nil
+	},
+	"secret_placeholder_template": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			return err
+		}
+		tmpl, err := template.New("secret_placeholder").Parse(text)
+		if err != nil {
+			return err
+		}
+		opts.SecretPlaceholderTemplate = tmpl
+		return nil
+	},
+	"description_source": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.DescriptionSource); err != nil {
+			return err
+		}
+		if !containsString(converter.DescriptionSources, opts.DescriptionSource) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.DescriptionSources, ", "))
+		}
+		return nil
+	},
+	"path_prefix": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.PathPrefix); err != nil {
+			return err
+		}
+		if opts.PathPrefix != "" && !strings.HasPrefix(opts.PathPrefix, "/") {
+			return fmt.Errorf("must start with /")
+		}
+		return nil
+	},
+	"strip_path_prefix": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.StripPathPrefix); err != nil {
+			return err
+		}
+		if opts.StripPathPrefix != "" && !strings.HasPrefix(opts.StripPathPrefix, "/") {
+			return fmt.Errorf("must start with /")
+		}
+		return nil
+	},
+	"default_request_content_type": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.DefaultRequestContentType); err != nil {
+			return err
+		}
+		return validateContentType(opts.DefaultRequestContentType)
+	},
+	"preferred_request_media": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.PreferredRequestMedia)
+	},
+	"max_schema_depth": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.MaxSchemaDepth); err != nil {
+			return err
+		}
+		if opts.MaxSchemaDepth < 0 {
+			return fmt.Errorf("must not be negative")
+		}
+		return nil
+	},
+	"propagate_tags": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.PropagateTags)
+	},
+	"strict_names": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.StrictNames)
+	},
+	"strict_operation_ids": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.StrictOperationIDs)
+	},
+	"safe_mode": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.SafeMode)
+	},
+	"operation_id_strip_prefix": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return unmarshalRegexp(value, &opts.OperationIDStripPrefix)
+	},
+	"include_path_regex": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return unmarshalRegexp(value, &opts.IncludePathRegex)
+	},
+	"exclude_path_regex": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return unmarshalRegexp(value, &opts.ExcludePathRegex)
+	},
+	"include_path_globs": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.IncludePathGlobs)
+	},
+	"exclude_path_globs": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.ExcludePathGlobs)
+	},
+	"annotate_source": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.AnnotateSource)
+	},
+	"annotate_auth": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.AnnotateAuth)
+	},
+	"strip_examples": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.StripExamples)
+	},
+	"promote_examples_to_defaults": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.PromoteExamplesToDefaults)
+	},
+	"synthesize_examples": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.SynthesizeExamples)
+	},
+	"operation_order": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.OperationOrder); err != nil {
+			return err
+		}
+		if !containsString(converter.OperationOrders, opts.OperationOrder) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.OperationOrders, ", "))
+		}
+		return nil
+	},
+	"initially_disabled": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.InitiallyDisabled)
+	},
+	"extension_passthrough": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.ExtensionPassthrough); err != nil {
+			return err
+		}
+		for _, extension := range opts.ExtensionPassthrough {
+			if !strings.HasPrefix(extension, "x-") {
+				return fmt.Errorf("%q is not a vendor extension, it must start with x-", extension)
+			}
+		}
+		return nil
+	},
+	"force_required": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.ForceRequired)
+	},
+	"response_size_hint": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.ResponseSizeHints)
+	},
+	"format_mapping": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		var mapping FormatMapping
+		if err := json.Unmarshal(value, &mapping); err != nil {
+			return err
+		}
+		opts.FormatMapping = mapping
+		return nil
+	},
+	"include_response_headers": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.IncludeResponseHeaders)
+	},
+	"include_enum_descriptions": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.IncludeEnumDescriptions)
+	},
+	"strict_numeric_constraints": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.StrictNumericConstraints)
+	},
+	"body_wrapper": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.BodyWrapper)
+	},
+	"param_name_case": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.ParamNameCase); err != nil {
+			return err
+		}
+		if !containsString(converter.ParamNameCases, opts.ParamNameCase) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.ParamNameCases, ", "))
+		}
+		return nil
+	},
+	"ref_strategy": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.RefStrategy); err != nil {
+			return err
+		}
+		if !containsString(converter.RefStrategies, opts.RefStrategy) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.RefStrategies, ", "))
+		}
+		return nil
+	},
+	"default_array_style": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.DefaultArrayStyle); err != nil {
+			return err
+		}
+		if !containsString(converter.ArrayStyles, opts.DefaultArrayStyle) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.ArrayStyles, ", "))
+		}
+		return nil
+	},
+	"null_handling": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.NullHandling); err != nil {
+			return err
+		}
+		if !containsString(converter.NullHandlings, opts.NullHandling) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.NullHandlings, ", "))
+		}
+		return nil
+	},
+	"empty_param_handling": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.EmptyParamHandling); err != nil {
+			return err
+		}
+		if !containsString(converter.EmptyParamHandlings, opts.EmptyParamHandling) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.EmptyParamHandlings, ", "))
+		}
+		return nil
+	},
+	"missing_body_schema": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.MissingBodySchema); err != nil {
+			return err
+		}
+		if !containsString(converter.MissingBodySchemas, opts.MissingBodySchema) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.MissingBodySchemas, ", "))
+		}
+		return nil
+	},
+	"response_codes_in_description": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		var codes ResponseCodeSelection
+		if err := json.Unmarshal(value, &codes); err != nil {
+			return err
+		}
+		if err := converter.ValidateResponseCodes(codes); err != nil {
+			return err
+		}
+		opts.ResponseCodesInDescription = codes
+		return nil
+	},
+	"max_enum_values": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.MaxEnumValues); err != nil {
+			return err
+		}
+		if opts.MaxEnumValues < 0 {
+			return fmt.Errorf("must not be negative")
+		}
+		return nil
+	},
+	"enum_overflow": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.EnumOverflow); err != nil {
+			return err
+		}
+		if !containsString(converter.EnumOverflows, opts.EnumOverflow) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.EnumOverflows, ", "))
+		}
+		return nil
+	},
+	"parameter_conflict_mode": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.ParameterConflictMode); err != nil {
+			return err
+		}
+		if !containsString(converter.ParameterConflictModes, opts.ParameterConflictMode) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.ParameterConflictModes, ", "))
+		}
+		return nil
+	},
+	"filter_description_language": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.FilterDescriptionLanguage); err != nil {
+			return err
+		}
+		if !containsString(converter.DescriptionLanguages, opts.FilterDescriptionLanguage) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.DescriptionLanguages, ", "))
+		}
+		return nil
+	},
+	"description_language_mode": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.DescriptionLanguageMode); err != nil {
+			return err
+		}
+		if !containsString(converter.DescriptionLanguageModes, opts.DescriptionLanguageMode) {
+			return fmt.Errorf("must be one of: %s", strings.Join(converter.DescriptionLanguageModes, ", "))
+		}
+		return nil
+	},
+	"coerce_types": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.CoerceTypes); err != nil {
+			return err
+		}
+		return validateCoercionPolicy(opts.CoerceTypes)
+	},
+	"pipeline_order": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.PipelineOrder); err != nil {
+			return err
+		}
+		_, err := converter.ResolvePipelineOrder(opts.PipelineOrder)
+		return err
+	},
+	"emit_spec_info": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.EmitSpecInfo)
+	},
+	"request_http2": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.Transport.HTTP2)
+	},
+	"request_keepalive": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		return json.Unmarshal(value, &opts.Transport.KeepAlive)
+	},
+	"request_max_redirects": func(opts *models.ConvertOptions, value json.RawMessage) error {
+		if err := json.Unmarshal(value, &opts.Transport.MaxRedirects); err != nil {
+			return err
+		}
+		if opts.Transport.MaxRedirects != nil && *opts.Transport.MaxRedirects < 0 {
+			return fmt.Errorf("must not be negative")
+		}
+		return nil
+	},
+}
+
+func unmarshalRegexp(value json.RawMessage, target **regexp.Regexp) error {
+	var pattern string
+	if err := json.Unmarshal(value, &pattern); err != nil {
+		return err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	*target = re
+	return nil
+}
+
+// supportedConverterOptions returns the accepted converter_options keys, sorted.
+func supportedConverterOptions() []string {
+	keys := make([]string, 0, len(converterOptionSetters))
+	for key := range converterOptionSetters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// applyConverterOptions sets the converter_options of a request on opts.
+// Values given here take precedence over the dedicated request fields.
+func applyConverterOptions(opts *models.ConvertOptions, options map[string]json.RawMessage) error {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		setter, ok := converterOptionSetters[key]
+		if !ok {
+			return newAPIError(http.StatusBadRequest, "unknown converter_options key %q, supported keys are: %s", key, strings.Join(supportedConverterOptions(), ", "))
+		}
+		if err := setter(opts, options[key]); err != nil {
+			return newAPIError(http.StatusBadRequest, "invalid converter_options.%s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// validateContentType checks that value is a media type such as
+// application/json, optionally with parameters.
+func validateContentType(value string) error {
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(mediaType, "/") {
+		return fmt.Errorf("%q is not a type/subtype media type", value)
+	}
+	return nil
+}
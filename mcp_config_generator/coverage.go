@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// untaggedCoverageKey groups the operations without tags in a coverage report.
+const untaggedCoverageKey = "(untagged)"
+
+// CoverageReport compares the operations of a spec with the tools generated
+// from it.
+type CoverageReport struct {
+	Operations int                      `json:"operations"`
+	Tools      int                      `json:"tools"`
+	Percent    float64                  `json:"percent"`
+	ByTag      map[string]*CoverageStat `json:"by_tag"`
+	ByMethod   map[string]*CoverageStat `json:"by_method"`
+	Dropped    []SkippedOperationInfo   `json:"dropped"`
+}
+
+// CoverageStat is the coverage of one tag or HTTP method.
+type CoverageStat struct {
+	Operations int     `json:"operations"`
+	Tools      int     `json:"tools"`
+	Percent    float64 `json:"percent"`
+}
+
+func (c *CoverageStat) add(converted bool) {
+	c.Operations++
+	if converted {
+		c.Tools++
+	}
+}
+
+// buildCoverageReport counts every operation of the spec by tag and method and
+// lists the ones that were skipped. An operation with several tags counts
+// towards each of them.
+func buildCoverageReport(paths openapi3.Paths, skipped []models.SkippedOperation) *CoverageReport {
+	dropped := make(map[string]bool)
+	for _, op := range skipped {
+		dropped[op.Method+" "+op.Path] = true
+	}
+
+	report := &CoverageReport{
+		ByTag:    make(map[string]*CoverageStat),
+		ByMethod: make(map[string]*CoverageStat),
+		Dropped:  newSkippedOperationInfos(skipped),
+	}
+	for path, pathItem := range paths {
+		if pathItem == nil {
+			continue
+		}
+		for method, operation := range pathItem.Operations() {
+			method = strings.ToUpper(method)
+			converted := !dropped[method+" "+path]
+
+			report.Operations++
+			if converted {
+				report.Tools++
+			}
+
+			if report.ByMethod[method] == nil {
+				report.ByMethod[method] = &CoverageStat{}
+			}
+			report.ByMethod[method].add(converted)
+
+			tags := operation.Tags
+			if len(tags) == 0 {
+				tags = []string{untaggedCoverageKey}
+			}
+			for _, tag := range tags {
+				if report.ByTag[tag] == nil {
+					report.ByTag[tag] = &CoverageStat{}
+				}
+				report.ByTag[tag].add(converted)
+			}
+		}
+	}
+
+	report.Percent = coveragePercent(report.Tools, report.Operations)
+	for _, stats := range []map[string]*CoverageStat{report.ByTag, report.ByMethod} {
+		for _, stat := range stats {
+			stat.Percent = coveragePercent(stat.Tools, stat.Operations)
+		}
+	}
+	sort.Slice(report.Dropped, func(i, j int) bool {
+		if report.Dropped[i].Path != report.Dropped[j].Path {
+			return report.Dropped[i].Path < report.Dropped[j].Path
+		}
+		return report.Dropped[i].Method < report.Dropped[j].Method
+	})
+
+	return report
+}
+
+// coveragePercent returns tools/operations as a percentage rounded to one
+// decimal. A spec without operations is fully covered.
+func coveragePercent(tools, operations int) float64 {
+	if operations == 0 {
+		return 100
+	}
+	return float64(int(float64(tools)*1000/float64(operations)+0.5)) / 10
+}
+
+// storeCoverageReport writes the report to coverage/<server>-<timestamp>.json
+// and returns its URL.
+func (s *ConversionService) storeCoverageReport(ctx context.Context, serverName string, report *CoverageReport, timestamp string, opts storageOptions) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal coverage report: %w", err)
+	}
+
+	fileName := fmt.Sprintf("coverage/%s-%s.json", serverName, timestamp)
+	return s.saveToStorage(ctx, fileName, data, "application/json", opts)
+}
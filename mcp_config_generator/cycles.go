@@ -0,0 +1,124 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// toolDependencyExtension is the vendor extension, passed through to a tool's
+// metadata with extension_passthrough, that lists the tools it depends on.
+const toolDependencyExtension = "x-depends-on"
+
+// templateToolCall matches a tool call in a template action, such as
+// {{ tool "get_user" .args.id }}, capturing the tool name.
+var templateToolCall = regexp.MustCompile(`\{\{-?\s*(?:\(\s*)?tool\s+"([^"]+)"`)
+
+// toolReferences builds the dependency graph between tools. A tool depends on
+// another when one of its request or response templates, which the runtime
+// evaluates when the tool is called, calls it with a tool action, or when its
+// x-depends-on metadata names it. Descriptions and literal template text are
+// not inspected: tool names such as "list" or "get" commonly appear in them
+// without referencing the tool.
+func toolReferences(config *models.MCPConfig) map[string][]string {
+	names := make(map[string]bool, len(config.Tools))
+	for _, tool := range config.Tools {
+		names[tool.Name] = true
+	}
+
+	graph := make(map[string][]string, len(config.Tools))
+	for _, tool := range config.Tools {
+		templates := []string{
+			tool.RequestTemplate.URL,
+			tool.RequestTemplate.Body,
+			tool.ResponseTemplate.Body,
+			tool.ResponseTemplate.PrependBody,
+			tool.ResponseTemplate.AppendBody,
+		}
+		for _, header := range tool.RequestTemplate.Headers {
+			templates = append(templates, header.Value)
+		}
+
+		referenced := make(map[string]bool)
+		for _, text := range templates {
+			for _, match := range templateToolCall.FindAllStringSubmatch(text, -1) {
+				referenced[match[1]] = true
+			}
+		}
+		switch dependencies := tool.Metadata[toolDependencyExtension].(type) {
+		case string:
+			referenced[dependencies] = true
+		case []interface{}:
+			for _, dependency := range dependencies {
+				if name, ok := dependency.(string); ok {
+					referenced[name] = true
+				}
+			}
+		}
+
+		for name := range referenced {
+			// References to tools the config doesn't have can't loop
+			if names[name] {
+				graph[tool.Name] = append(graph[tool.Name], name)
+			}
+		}
+		sort.Strings(graph[tool.Name])
+	}
+
+	return graph
+}
+
+// findToolCycle returns the first dependency cycle between tools, as a path
+// that starts and ends with the same tool name, or nil when there is none.
+func findToolCycle(config *models.MCPConfig) []string {
+	graph := toolReferences(config)
+
+	names := make([]string, 0, len(config.Tools))
+	for _, tool := range config.Tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(names))
+	var stack []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, next := range graph[name] {
+			switch state[next] {
+			case visiting:
+				// Cut the stack back to where the cycle starts
+				for i, n := range stack {
+					if n == next {
+						cycle := append([]string{}, stack[i:]...)
+						return append(cycle, next)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
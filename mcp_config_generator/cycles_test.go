@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+func TestFindToolCycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		tools    []models.Tool
+		expected string
+	}{
+		{
+			name: "Templates calling each other",
+			tools: []models.Tool{
+				{Name: "get_order", ResponseTemplate: models.ResponseTemplate{AppendBody: `{{ tool "list_items" .args.id }}`}},
+				{Name: "list_items", RequestTemplate: models.RequestTemplate{Body: `{"order": {{ tool "get_order" .args.order_id | toJson }}}`}},
+			},
+			expected: "get_order -> list_items -> get_order",
+		},
+		{
+			name: "x-depends-on metadata",
+			tools: []models.Tool{
+				{Name: "a", Metadata: map[string]interface{}{"x-depends-on": []interface{}{"b"}}},
+				{Name: "b", Metadata: map[string]interface{}{"x-depends-on": "a"}},
+			},
+			expected: "a -> b -> a",
+		},
+		{
+			name: "Tool names mentioned in descriptions and URLs",
+			tools: []models.Tool{
+				{Name: "list", Description: "Call get to fetch one item, or search", RequestTemplate: models.RequestTemplate{URL: "https://api.example.com/search/get"}},
+				{Name: "get", Description: "Use list to find ids"},
+				{Name: "search", Description: "Like list, but filtered; see get", ResponseTemplate: models.ResponseTemplate{PrependBody: "Results of list:"}},
+			},
+		},
+		{
+			name: "Dependencies without a cycle",
+			tools: []models.Tool{
+				{Name: "a", ResponseTemplate: models.ResponseTemplate{Body: `{{ tool "b" }}`}},
+				{Name: "b", Metadata: map[string]interface{}{"x-depends-on": []interface{}{"c", "missing"}}},
+				{Name: "c"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cycle := findToolCycle(&models.MCPConfig{Tools: tc.tools})
+			if got := strings.Join(cycle, " -> "); got != tc.expected {
+				t.Errorf("cycle = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
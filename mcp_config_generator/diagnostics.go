@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// ConversionDiagnostics is the audit record stored next to a converted config
+// when store_diagnostics is enabled.
+type ConversionDiagnostics struct {
+	ServerName        string                 `json:"server_name"`
+	RequestID         string                 `json:"request_id,omitempty"`
+	GeneratedAt       string                 `json:"generated_at"`
+	OpenAPIFileURL    string                 `json:"openapi_file_url"`
+	MCPConfigFileURL  string                 `json:"mcp_config_file_url"`
+	SpecBytes         int                    `json:"spec_bytes"`
+	ToolCount         int                    `json:"tool_count"`
+	Warnings          []string               `json:"warnings"`
+	SkippedOperations []SkippedOperationInfo `json:"skipped_operations"`
+	Timing            ConversionTiming       `json:"timing"`
+	Options           ConversionRequest      `json:"options"`
+}
+
+type SkippedOperationInfo struct {
+	Path     string `json:"path"`
+	Method   string `json:"method"`
+	Category string `json:"category"`
+	Reason   string `json:"reason"`
+}
+
+// ConversionTiming holds the durations, in milliseconds, of each conversion stage.
+type ConversionTiming struct {
+	ParseMs   int64 `json:"parse_ms"`
+	ConvertMs int64 `json:"convert_ms"`
+	TotalMs   int64 `json:"total_ms"`
+}
+
+func newSkippedOperationInfos(skipped []models.SkippedOperation) []SkippedOperationInfo {
+	infos := make([]SkippedOperationInfo, 0, len(skipped))
+	for _, op := range skipped {
+		infos = append(infos, SkippedOperationInfo{
+			Path:     op.Path,
+			Method:   op.Method,
+			Category: op.Category,
+			Reason:   op.Reason,
+		})
+	}
+	return infos
+}
+
+// effectiveOptions returns the request as it was applied, without the spec and
+// template bodies which are stored separately, and without the registry token.
+func effectiveOptions(req ConversionRequest) ConversionRequest {
+	req.OpenAPISpec = ""
+	req.TemplateConfig = ""
+	if req.PublishPackage != nil {
+		publish := *req.PublishPackage
+		publish.Token = ""
+		req.PublishPackage = &publish
+	}
+	return req
+}
+
+// storeDiagnostics writes the diagnostics report for a conversion to
+// diagnostics/<server>-<timestamp>.json and returns its URL.
+func (s *ConversionService) storeDiagnostics(ctx context.Context, req ConversionRequest, response *ConversionResponse, output *conversionOutput, timestamp string, started time.Time) (string, error) {
+	warnings := output.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+
+	timing := output.Timing
+	timing.TotalMs = time.Since(started).Milliseconds()
+
+	diagnostics := ConversionDiagnostics{
+		ServerName:        req.ServerName,
+		RequestID:         requestIDFromContext(ctx),
+		GeneratedAt:       time.Now().UTC().Format(time.RFC3339),
+		OpenAPIFileURL:    response.OpenAPIFileURL,
+		MCPConfigFileURL:  response.MCPConfigFileURL,
+		SpecBytes:         len(req.OpenAPISpec),
+		ToolCount:         output.ToolCount,
+		Warnings:          warnings,
+		SkippedOperations: newSkippedOperationInfos(output.Skipped),
+		Timing:            timing,
+		Options:           effectiveOptions(req),
+	}
+
+	data, err := json.MarshalIndent(diagnostics, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal diagnostics: %w", err)
+	}
+
+	fileName := fmt.Sprintf("diagnostics/%s-%s.json", req.ServerName, timestamp)
+	return s.saveToStorage(ctx, fileName, data, "application/json", storageOptions{})
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"github.com/higress-group/openapi-to-mcpserver/pkg/converter"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// EffectiveOptions are the options a conversion actually ran with, after
+// request defaults, environment fallbacks and converter_options were
+// applied. Unlike the request, every field is present, so defaults show up
+// explicitly.
+type EffectiveOptions struct {
+	ServerName      string `json:"server_name"`
+	Format          string `json:"format"`
+	OutputExtension string `json:"output_extension"`
+	ToolPrefix      string `json:"tool_prefix"`
+	Environment     string `json:"environment"`
+	JSONIndent      string `json:"json_indent"`
+	Validate        bool   `json:"validate"`
+	TemplateConfig  bool   `json:"template_config"`
+
+	IncludePathRegex       string                             `json:"include_path_regex"`
+	ExcludePathRegex       string                             `json:"exclude_path_regex"`
+	IncludePathGlobs       []string                           `json:"include_path_globs"`
+	ExcludePathGlobs       []string                           `json:"exclude_path_globs"`
+	RequireSuccessResponse bool                               `json:"require_success_response"`
+	StrictOperationIDs     bool                               `json:"strict_operation_ids"`
+	StrictNames            bool                               `json:"strict_names"`
+	OperationOrder         string                             `json:"operation_order"`
+	DescriptionTemplate    string                             `json:"description_template"`
+	DescriptionSource      string                             `json:"description_source"`
+	PreferredRequestMedia  []string                           `json:"preferred_request_media"`
+	DefaultContentType     string                             `json:"default_request_content_type"`
+	PathPrefix             string                             `json:"path_prefix"`
+	StripPathPrefix        string                             `json:"strip_path_prefix"`
+	MaxSchemaDepth         int                                `json:"max_schema_depth"`
+	PropagateTags          bool                               `json:"propagate_tags"`
+	AnnotateAuth           bool                               `json:"annotate_auth"`
+	AnnotateSource         bool                               `json:"annotate_source"`
+	StripExamples          bool                               `json:"strip_examples"`
+	PromoteExamples        bool                               `json:"promote_examples_to_defaults"`
+	SynthesizeExamples     bool                               `json:"synthesize_examples"`
+	ForceRequired          map[string][]string                `json:"force_required"`
+	InitiallyDisabled      []string                           `json:"initially_disabled"`
+	ExtensionPassthrough   []string                           `json:"extension_passthrough"`
+	ParameterConflictMode  string                             `json:"parameter_conflict_mode"`
+	DescriptionLanguage    string                             `json:"filter_description_language"`
+	LanguageMode           string                             `json:"description_language_mode"`
+	ResponseSizeHints      map[string]models.ResponseSizeHint `json:"response_size_hint"`
+	EnumDescriptions       bool                               `json:"include_enum_descriptions"`
+	ResponseHeaders        bool                               `json:"include_response_headers"`
+	StrictNumeric          bool                               `json:"strict_numeric_constraints"`
+	BodyWrapper            string                             `json:"body_wrapper"`
+	ParamNameCase          string                             `json:"param_name_case"`
+	NullHandling           string                             `json:"null_handling"`
+	EmptyParamHandling     string                             `json:"empty_param_handling"`
+	CoerceTypes            map[string]string                  `json:"coerce_types"`
+	PipelineOrder          []string                           `json:"pipeline_order"`
+	SecretPlaceholder      string                             `json:"secret_placeholder_template"`
+	OperationIDStripPrefix string                             `json:"operation_id_strip_prefix"`
+	SafeMode               bool                               `json:"safe_mode"`
+	MissingBodySchema      string                             `json:"missing_body_schema"`
+	ResponseCodes          []string                           `json:"response_codes_in_description"`
+	MaxEnumValues          int                                `json:"max_enum_values"`
+	EnumOverflow           string                             `json:"enum_overflow"`
+	DefaultArrayStyle      string                             `json:"default_array_style"`
+	RefStrategy            string                             `json:"ref_strategy"`
+	FormatMapping          map[string]models.FormatConstraint `json:"format_mapping"`
+	EmitSpecInfo           bool                               `json:"emit_spec_info"`
+	ServerConfig           map[string]interface{}             `json:"server_config"`
+	RequestHTTP2           *bool                              `json:"request_http2"`
+	RequestKeepalive       *bool                              `json:"request_keepalive"`
+	RequestMaxRedirects    *int                               `json:"request_max_redirects"`
+
+	SourceFormat        string   `json:"source_format"`
+	CacheControl        string   `json:"cache_control"`
+	NoOverwrite         bool     `json:"no_overwrite"`
+	DryRun              bool     `json:"dry_run"`
+	Bundle              bool     `json:"bundle"`
+	BundleFormats       []string `json:"bundle_formats"`
+	SplitBy             string   `json:"split_by"`
+	RedactPatterns      []string `json:"redact_patterns"`
+	DetectCycles        bool     `json:"detect_cycles"`
+	AllowedHosts        []string `json:"allowed_hosts"`
+	TemplatedHosts      string   `json:"templated_hosts"`
+	VerifyServers       bool     `json:"verify_servers"`
+	GenerateChangelog   bool     `json:"generate_changelog"`
+	CoverageReport      bool     `json:"coverage_report"`
+	StoreCoverageReport bool     `json:"store_coverage_report"`
+	StoreDiagnostics    bool     `json:"store_diagnostics"`
+	PublishPackage      bool     `json:"publish_package"`
+	ResourceStats       bool     `json:"include_resource_stats"`
+}
+
+// newEffectiveOptions resolves the options of a conversion. req must already
+// have its defaults applied and opts is what the converter ran with, so
+// converter_options are reflected where they override a request field.
+func (s *ConversionService) newEffectiveOptions(req ConversionRequest, opts models.ConvertOptions) *EffectiveOptions {
+	effective := &EffectiveOptions{
+		ServerName:      req.ServerName,
+		Format:          req.Format,
+		OutputExtension: s.configExtension(req),
+		ToolPrefix:      opts.ToolNamePrefix,
+		Environment:     req.Environment,
+		JSONIndent:      req.JSONIndent.String(),
+		Validate:        req.Validate,
+		TemplateConfig:  req.TemplateConfig != "",
+
+		IncludePathGlobs:       opts.IncludePathGlobs,
+		ExcludePathGlobs:       opts.ExcludePathGlobs,
+		RequireSuccessResponse: opts.RequireSuccessResponse,
+		StrictOperationIDs:     opts.StrictOperationIDs,
+		StrictNames:            opts.StrictNames,
+		SafeMode:               opts.SafeMode,
+		OperationOrder:         opts.OperationOrder,
+		PreferredRequestMedia:  opts.PreferredRequestMedia,
+		DescriptionSource:      opts.DescriptionSource,
+		DefaultContentType:     opts.DefaultRequestContentType,
+		PathPrefix:             opts.PathPrefix,
+		StripPathPrefix:        opts.StripPathPrefix,
+		MaxSchemaDepth:         opts.MaxSchemaDepth,
+		PropagateTags:          opts.PropagateTags,
+		AnnotateAuth:           opts.AnnotateAuth,
+		AnnotateSource:         opts.AnnotateSource,
+		StripExamples:          opts.StripExamples,
+		PromoteExamples:        opts.PromoteExamplesToDefaults,
+		SynthesizeExamples:     opts.SynthesizeExamples,
+		ForceRequired:          opts.ForceRequired,
+		InitiallyDisabled:      opts.InitiallyDisabled,
+		ExtensionPassthrough:   opts.ExtensionPassthrough,
+		ParameterConflictMode:  opts.ParameterConflictMode,
+		DescriptionLanguage:    opts.FilterDescriptionLanguage,
+		LanguageMode:           opts.DescriptionLanguageMode,
+		ResponseSizeHints:      opts.ResponseSizeHints,
+		EnumDescriptions:       opts.IncludeEnumDescriptions,
+		ResponseHeaders:        opts.IncludeResponseHeaders,
+		StrictNumeric:          opts.StrictNumericConstraints,
+		BodyWrapper:            opts.BodyWrapper,
+		ParamNameCase:          opts.ParamNameCase,
+		NullHandling:           opts.NullHandling,
+		EmptyParamHandling:     opts.EmptyParamHandling,
+		MissingBodySchema:      opts.MissingBodySchema,
+		ResponseCodes:          opts.ResponseCodesInDescription,
+		MaxEnumValues:          opts.MaxEnumValues,
+		EnumOverflow:           opts.EnumOverflow,
+		CoerceTypes:            opts.CoerceTypes,
+		DefaultArrayStyle:      opts.DefaultArrayStyle,
+		RefStrategy:            opts.RefStrategy,
+		FormatMapping:          opts.FormatMapping,
+		EmitSpecInfo:           opts.EmitSpecInfo,
+		ServerConfig:           opts.ServerConfig,
+		RequestHTTP2:           opts.Transport.HTTP2,
+		RequestKeepalive:       opts.Transport.KeepAlive,
+		RequestMaxRedirects:    opts.Transport.MaxRedirects,
+
+		SourceFormat:        req.SourceFormat,
+		CacheControl:        s.cacheControl,
+		NoOverwrite:         req.NoOverwrite,
+		DryRun:              s.storageDryRun,
+		Bundle:              req.Bundle,
+		SplitBy:             req.SplitBy,
+		RedactPatterns:      req.RedactPatterns,
+		DetectCycles:        req.DetectCycles,
+		AllowedHosts:        req.AllowedHosts,
+		TemplatedHosts:      req.TemplatedHosts,
+		VerifyServers:       req.VerifyServers,
+		GenerateChangelog:   req.GenerateChangelog,
+		CoverageReport:      req.CoverageReport || req.StoreCoverageReport,
+		StoreCoverageReport: req.StoreCoverageReport,
+		StoreDiagnostics:    req.StoreDiagnostics,
+		PublishPackage:      req.PublishPackage != nil,
+		ResourceStats:       req.IncludeResourceStats,
+	}
+
+	if opts.IncludePathRegex != nil {
+		effective.IncludePathRegex = opts.IncludePathRegex.String()
+	}
+	if opts.OperationIDStripPrefix != nil {
+		effective.OperationIDStripPrefix = opts.OperationIDStripPrefix.String()
+	}
+	if opts.ExcludePathRegex != nil {
+		effective.ExcludePathRegex = opts.ExcludePathRegex.String()
+	}
+	if effective.DefaultContentType == "" {
+		effective.DefaultContentType = converter.DefaultRequestContentType
+	}
+	// The full order, with the unlisted steps where they ran
+	effective.PipelineOrder, _ = converter.ResolvePipelineOrder(opts.PipelineOrder)
+	if effective.OperationOrder == "" {
+		effective.OperationOrder = converter.OrderOperationID
+	}
+	if effective.DescriptionSource == "" {
+		effective.DescriptionSource = converter.DescriptionFromBoth
+	}
+	if effective.RefStrategy == "" {
+		effective.RefStrategy = converter.RefStrategyInline
+	}
+	if effective.EmptyParamHandling == "" {
+		effective.EmptyParamHandling = converter.EmptyParamOmit
+	}
+	if effective.ResponseCodes == nil {
+		effective.ResponseCodes = []string{converter.ResponseCodesNone}
+	}
+	if effective.ParameterConflictMode == "" {
+		effective.ParameterConflictMode = converter.ParameterConflictOperationWins
+	}
+	if effective.DescriptionLanguage != "" && effective.LanguageMode == "" {
+		effective.LanguageMode = converter.DescriptionLanguageSkip
+	}
+	if effective.EnumOverflow == "" {
+		effective.EnumOverflow = converter.EnumOverflowTruncate
+	}
+	if effective.MissingBodySchema == "" {
+		effective.MissingBodySchema = converter.MissingBodySchemaFreeForm
+	}
+	if opts.DescriptionTemplate != nil && opts.DescriptionTemplate.Tree != nil {
+		effective.DescriptionTemplate = opts.DescriptionTemplate.Tree.Root.String()
+	}
+	effective.SecretPlaceholder = converter.DefaultSecretPlaceholder
+	if opts.SecretPlaceholderTemplate != nil && opts.SecretPlaceholderTemplate.Tree != nil {
+		effective.SecretPlaceholder = opts.SecretPlaceholderTemplate.Tree.Root.String()
+	}
+	if len(req.AllowedHosts) > 0 && effective.TemplatedHosts == "" {
+		effective.TemplatedHosts = templatedHostsReject
+	}
+	if req.CacheControl != "" {
+		effective.CacheControl = req.CacheControl
+	}
+	if req.Bundle {
+		effective.BundleFormats = req.BundleFormats
+		if len(effective.BundleFormats) == 0 {
+			effective.BundleFormats = supportedFormats
+		}
+	}
+	return effective
+}
@@ -0,0 +1,545 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// anonymousClient is the client ID of requests without an API key.
+const anonymousClient = "anonymous"
+
+// Scopes of the reported counts
+const (
+	// fieldUsageScopeBucket counts are stored in the bucket and cover every
+	// instance
+	fieldUsageScopeBucket = "bucket"
+	// fieldUsageScopeInstance counts are kept in memory by the instance
+	// answering, in dry-run mode
+	fieldUsageScopeInstance = "instance"
+)
+
+// fieldUsagePrefix holds the stored field usage in daily partitions.
+const fieldUsagePrefix = "field-usage/"
+
+// fieldUsageLog counts which request fields each client sends, by day, so
+// fields can be deprecated once nobody uses them. Only a sample of requests
+// is inspected, after the response is written. Counts are collected in
+// memory and flushed to the store every flushInterval as immutable objects
+// under a daily partition, like the audit log, so every instance adds to
+// the same rolling log. Days older than the retention period roll off.
+type fieldUsageLog struct {
+	sampleRate    float64
+	retention     int
+	flushInterval time.Duration
+	// store keeps the flushed counts, nil in dry-run mode where they stay
+	// in memory
+	store fieldUsageStore
+	// instance is the host name reported with in-memory counts
+	instance string
+	started  time.Time
+
+	mu sync.Mutex
+	// days maps a UTC date to each client's usage on that day that isn't
+	// flushed yet
+	days map[string]map[string]*clientFieldUsage
+	// cleaned is the last date old partitions were deleted on
+	cleaned string
+}
+
+// clientFieldUsage is what one client sent on one day.
+type clientFieldUsage struct {
+	Requests int            `json:"requests"`
+	Fields   map[string]int `json:"fields"`
+	LastSeen time.Time      `json:"last_seen"`
+}
+
+// add counts other into u.
+func (u *clientFieldUsage) add(other *clientFieldUsage) {
+	u.Requests += other.Requests
+	if other.LastSeen.After(u.LastSeen) {
+		u.LastSeen = other.LastSeen
+	}
+	for field, count := range other.Fields {
+		u.Fields[field] += count
+	}
+}
+
+// fieldUsageStore holds flushed field usage objects.
+type fieldUsageStore interface {
+	write(ctx context.Context, name string, data []byte) error
+	list(ctx context.Context, prefix string) ([]string, error)
+	read(ctx context.Context, name string) ([]byte, error)
+	delete(ctx context.Context, name string) error
+}
+
+// bucketFieldUsageStore keeps field usage objects in a storage bucket.
+type bucketFieldUsageStore struct {
+	bucket *storage.BucketHandle
+}
+
+func (b *bucketFieldUsageStore) write(ctx context.Context, name string, data []byte) error {
+	// Objects are private and never overwritten
+	writer := b.bucket.Object(name).If(storage.Conditions{DoesNotExist: true}).NewWriter(ctx)
+	writer.ContentType = "application/json"
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (b *bucketFieldUsageStore) list(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	it := b.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, attrs.Name)
+	}
+}
+
+func (b *bucketFieldUsageStore) read(ctx context.Context, name string) ([]byte, error) {
+	reader, err := b.bucket.Object(name).NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func (b *bucketFieldUsageStore) delete(ctx context.Context, name string) error {
+	return b.bucket.Object(name).Delete(ctx)
+}
+
+// newFieldUsageLogFromEnv reads FIELD_USAGE_SAMPLE_RATE, the share of
+// requests inspected between 0 and 1, FIELD_USAGE_RETENTION_DAYS (default
+// 30) and FIELD_USAGE_FLUSH_INTERVAL (default 1m). It returns nil when no
+// sample rate is configured. The store is set by the caller.
+func newFieldUsageLogFromEnv() (*fieldUsageLog, error) {
+	value := os.Getenv("FIELD_USAGE_SAMPLE_RATE")
+	if value == "" {
+		return nil, nil
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate <= 0 || rate > 1 {
+		return nil, fmt.Errorf("FIELD_USAGE_SAMPLE_RATE must be a number above 0 and at most 1, got %q", value)
+	}
+
+	instance, _ := os.Hostname()
+	usage := &fieldUsageLog{
+		sampleRate:    rate,
+		retention:     30,
+		flushInterval: time.Minute,
+		instance:      instance,
+		started:       time.Now(),
+		days:          make(map[string]map[string]*clientFieldUsage),
+	}
+	if value := os.Getenv("FIELD_USAGE_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 {
+			return nil, fmt.Errorf("FIELD_USAGE_RETENTION_DAYS must be a positive number of days, got %q", value)
+		}
+		usage.retention = days
+	}
+	if value := os.Getenv("FIELD_USAGE_FLUSH_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("FIELD_USAGE_FLUSH_INTERVAL must be a positive duration, got %q", value)
+		}
+		usage.flushInterval = interval
+	}
+	return usage, nil
+}
+
+// run flushes the counts every flushInterval until ctx is done. Counts not
+// flushed yet are lost when the instance stops.
+func (u *fieldUsageLog) run(ctx context.Context) {
+	ticker := time.NewTicker(u.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := u.flush(ctx, time.Now()); err != nil {
+			log.Printf("Warning: Failed to store field usage: %v", err)
+		}
+	}
+}
+
+// flush writes the counts collected since the last flush as one object per
+// day and deletes the partitions past retention once a day. Counts that
+// fail to be written are kept for the next flush.
+func (u *fieldUsageLog) flush(ctx context.Context, now time.Time) error {
+	if u.store == nil {
+		return nil
+	}
+	u.mu.Lock()
+	days := u.days
+	u.days = make(map[string]map[string]*clientFieldUsage)
+	u.mu.Unlock()
+
+	var failed error
+	for day, usages := range days {
+		data, err := json.Marshal(usages)
+		if err == nil {
+			name := fmt.Sprintf("%sdate=%s/%s-%s.json", fieldUsagePrefix, day, now.UTC().Format("150405.000000000"), newRequestID())
+			err = u.store.write(ctx, name, data)
+		}
+		if err != nil {
+			failed = err
+			u.mu.Lock()
+			for client, usage := range usages {
+				u.addLocked(day, client, usage)
+			}
+			u.mu.Unlock()
+		}
+	}
+	if failed != nil {
+		return failed
+	}
+
+	today := now.UTC().Format("2006-01-02")
+	if u.cleaned == today {
+		return nil
+	}
+	names, err := u.store.list(ctx, fieldUsagePrefix)
+	if err != nil {
+		return err
+	}
+	oldest := u.oldestDay(now)
+	for _, name := range names {
+		if day := fieldUsageDay(name); day != "" && day < oldest {
+			if err := u.store.delete(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+	u.cleaned = today
+	return nil
+}
+
+// fieldUsageDay returns the date of a field usage object's partition.
+func fieldUsageDay(name string) string {
+	partition, _, _ := strings.Cut(strings.TrimPrefix(name, fieldUsagePrefix+"date="), "/")
+	if _, err := time.Parse("2006-01-02", partition); err != nil {
+		return ""
+	}
+	return partition
+}
+
+// oldestDay is the first date within retention.
+func (u *fieldUsageLog) oldestDay(now time.Time) string {
+	return now.UTC().AddDate(0, 0, -(u.retention - 1)).Format("2006-01-02")
+}
+
+// track wraps a conversion endpoint so a sample of its request bodies is
+// copied while the handler reads them and their fields are recorded once
+// it returns. A nil log leaves the handler as it is.
+func (u *fieldUsageLog) track(next http.HandlerFunc) http.HandlerFunc {
+	if u == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || rand.Float64() >= u.sampleRate {
+			next(w, r)
+			return
+		}
+
+		var body bytes.Buffer
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(r.Body, &body), r.Body}
+		next(w, r)
+
+		fields := requestFields(r.URL.Path, body.Bytes())
+		if fields == nil {
+			return
+		}
+		client, _ := withAPIKeyID(r).Value(apiKeyIDKey{}).(string)
+		if client == "" {
+			client = anonymousClient
+		}
+		u.record(client, fields, time.Now())
+	}
+}
+
+// requestFields lists the fields a request body sets: the top-level
+// ConversionRequest fields, prefixed with "batch." for the batch envelope,
+// and the converter_options keys as "converter_options.<key>". It returns
+// nil for a body that isn't a JSON object.
+func requestFields(path string, body []byte) []string {
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	conversions := []map[string]json.RawMessage{request}
+	if path == "/convert/batch" {
+		conversions = nil
+		for key, value := range request {
+			seen["batch."+key] = true
+			if key == "conversions" {
+				json.Unmarshal(value, &conversions)
+			}
+		}
+	}
+	for _, conversion := range conversions {
+		for key, value := range conversion {
+			seen[key] = true
+			if key != "converter_options" {
+				continue
+			}
+			var options map[string]json.RawMessage
+			if json.Unmarshal(value, &options) == nil {
+				for option := range options {
+					seen["converter_options."+option] = true
+				}
+			}
+		}
+	}
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// record counts one request of a client and drops the days past retention.
+func (u *fieldUsageLog) record(client string, fields []string, now time.Time) {
+	usage := &clientFieldUsage{Requests: 1, Fields: make(map[string]int), LastSeen: now.UTC()}
+	for _, field := range fields {
+		usage.Fields[field]++
+	}
+	oldest := u.oldestDay(now)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for date := range u.days {
+		if date < oldest {
+			delete(u.days, date)
+		}
+	}
+	u.addLocked(now.UTC().Format("2006-01-02"), client, usage)
+}
+
+// addLocked counts usage into the client's day. Callers must hold u.mu.
+func (u *fieldUsageLog) addLocked(day, client string, usage *clientFieldUsage) {
+	if u.days[day] == nil {
+		u.days[day] = make(map[string]*clientFieldUsage)
+	}
+	total := u.days[day][client]
+	if total == nil {
+		total = &clientFieldUsage{Fields: make(map[string]int)}
+		u.days[day][client] = total
+	}
+	total.add(usage)
+}
+
+// FieldUsageSummary is the response of GET /admin/field-usage.
+type FieldUsageSummary struct {
+	Success    bool    `json:"success"`
+	Since      string  `json:"since"`
+	Days       int     `json:"days"`
+	SampleRate float64 `json:"sample_rate"`
+	// Scope is "bucket" for the counts of every instance, stored in the
+	// bucket, or "instance" for those of the instance answering, kept in
+	// memory in dry-run mode
+	Scope string `json:"scope"`
+	// Instance and CountingSince are the instance and its start, set for
+	// the "instance" scope
+	Instance      string `json:"instance,omitempty"`
+	CountingSince string `json:"counting_since,omitempty"`
+	// Requests is the number of sampled requests
+	Requests int                  `json:"requests"`
+	Fields   []FieldUsage         `json:"fields"`
+	Clients  []ClientFieldSummary `json:"clients"`
+	// UnusedFields are the conversion request fields no sampled request set
+	UnusedFields []string `json:"unused_fields"`
+}
+
+// FieldUsage is how many sampled requests and clients sent a field.
+type FieldUsage struct {
+	Field    string   `json:"field"`
+	Requests int      `json:"requests"`
+	Clients  []string `json:"clients"`
+}
+
+// ClientFieldSummary is the fields one client sent.
+type ClientFieldSummary struct {
+	APIKeyID string         `json:"api_key_id"`
+	Requests int            `json:"requests"`
+	LastSeen string         `json:"last_seen"`
+	Fields   map[string]int `json:"fields"`
+}
+
+// summary adds up the usage of the last days days, today included: the
+// stored objects of every instance and this instance's counts not flushed
+// yet.
+func (u *fieldUsageLog) summary(ctx context.Context, days int, now time.Time) (FieldUsageSummary, error) {
+	since := now.UTC().AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+	summary := FieldUsageSummary{Success: true, Since: since, Days: days, SampleRate: u.sampleRate, Scope: fieldUsageScopeBucket}
+	if u.store == nil {
+		summary.Scope = fieldUsageScopeInstance
+		summary.Instance = u.instance
+		summary.CountingSince = u.started.UTC().Format(time.RFC3339)
+	}
+
+	var usages []map[string]*clientFieldUsage
+	if u.store != nil {
+		for day := now.UTC().AddDate(0, 0, -(days - 1)); !day.After(now.UTC()); day = day.AddDate(0, 0, 1) {
+			names, err := u.store.list(ctx, fmt.Sprintf("%sdate=%s/", fieldUsagePrefix, day.Format("2006-01-02")))
+			if err != nil {
+				return summary, fmt.Errorf("failed to list field usage: %w", err)
+			}
+			for _, name := range names {
+				data, err := u.store.read(ctx, name)
+				if err != nil {
+					return summary, fmt.Errorf("failed to read %s: %w", name, err)
+				}
+				var stored map[string]*clientFieldUsage
+				if err := json.Unmarshal(data, &stored); err != nil {
+					log.Printf("Warning: Ignoring unreadable field usage object %s: %v", name, err)
+					continue
+				}
+				usages = append(usages, stored)
+			}
+		}
+	}
+	// Copy the pending counts, record keeps adding to them
+	u.mu.Lock()
+	for date, pending := range u.days {
+		if date < since {
+			continue
+		}
+		copied := make(map[string]*clientFieldUsage, len(pending))
+		for client, usage := range pending {
+			copied[client] = &clientFieldUsage{Fields: make(map[string]int)}
+			copied[client].add(usage)
+		}
+		usages = append(usages, copied)
+	}
+	u.mu.Unlock()
+
+	clients := make(map[string]*ClientFieldSummary)
+	fields := make(map[string]*FieldUsage)
+	for _, byClient := range usages {
+		for client, usage := range byClient {
+			if usage == nil {
+				continue
+			}
+			total := clients[client]
+			if total == nil {
+				total = &ClientFieldSummary{APIKeyID: client, Fields: make(map[string]int)}
+				clients[client] = total
+			}
+			total.Requests += usage.Requests
+			if lastSeen := usage.LastSeen.UTC().Format(time.RFC3339); lastSeen > total.LastSeen {
+				total.LastSeen = lastSeen
+			}
+			summary.Requests += usage.Requests
+			for field, count := range usage.Fields {
+				total.Fields[field] += count
+				if fields[field] == nil {
+					fields[field] = &FieldUsage{Field: field}
+				}
+				fields[field].Requests += count
+			}
+		}
+	}
+
+	summary.Clients = make([]ClientFieldSummary, 0, len(clients))
+	for _, client := range clients {
+		summary.Clients = append(summary.Clients, *client)
+		for field := range client.Fields {
+			fields[field].Clients = append(fields[field].Clients, client.APIKeyID)
+		}
+	}
+	sort.Slice(summary.Clients, func(i, j int) bool { return summary.Clients[i].APIKeyID < summary.Clients[j].APIKeyID })
+
+	summary.Fields = make([]FieldUsage, 0, len(fields))
+	for _, field := range fields {
+		sort.Strings(field.Clients)
+		summary.Fields = append(summary.Fields, *field)
+	}
+	sort.Slice(summary.Fields, func(i, j int) bool { return summary.Fields[i].Field < summary.Fields[j].Field })
+
+	summary.UnusedFields = []string{}
+	for _, field := range conversionRequestFields() {
+		if fields[field] == nil {
+			summary.UnusedFields = append(summary.UnusedFields, field)
+		}
+	}
+	return summary, nil
+}
+
+// conversionRequestFields lists the JSON names of the ConversionRequest
+// fields.
+func conversionRequestFields() []string {
+	requestType := reflect.TypeOf(ConversionRequest{})
+	fields := make([]string, 0, requestType.NumField())
+	for i := 0; i < requestType.NumField(); i++ {
+		name, _, _ := strings.Cut(requestType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// handleFieldUsage serves GET /admin/field-usage, optionally limited to the
+// last ?days=N days of the retention period.
+func (u *fieldUsageLog) handleFieldUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if u == nil {
+		respondWithAdminError(w, "field usage logging is disabled, set FIELD_USAGE_SAMPLE_RATE to enable it", http.StatusNotFound)
+		return
+	}
+
+	days := u.retention
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > u.retention {
+			respondWithAdminError(w, fmt.Sprintf("days must be between 1 and %d", u.retention), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	summary, err := u.summary(r.Context(), days, time.Now())
+	if err != nil {
+		respondWithAdminError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryFieldUsageStore keeps field usage objects in memory.
+type memoryFieldUsageStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memoryFieldUsageStore) write(ctx context.Context, name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.objects[name]; ok {
+		return fmt.Errorf("%s already exists", name)
+	}
+	m.objects[name] = data
+	return nil
+}
+
+func (m *memoryFieldUsageStore) list(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for name := range m.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *memoryFieldUsageStore) read(ctx context.Context, name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[name]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+	return data, nil
+}
+
+func (m *memoryFieldUsageStore) delete(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, name)
+	return nil
+}
+
+func TestRequestFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		expected []string
+	}{
+		{
+			name:     "Conversion with converter options",
+			path:     "/convert",
+			body:     `{"openapi_spec": "openapi: 3.0.0", "strip_examples": true, "converter_options": {"max_schema_depth": 2}}`,
+			expected: []string{"converter_options", "converter_options.max_schema_depth", "openapi_spec", "strip_examples"},
+		},
+		{
+			name:     "Batch envelope and its conversions",
+			path:     "/convert/batch",
+			body:     `{"conversions": [{"openapi_spec": "a"}, {"openapi_spec": "b", "server_name": "orders"}], "fail_fast": true}`,
+			expected: []string{"batch.conversions", "batch.fail_fast", "openapi_spec", "server_name"},
+		},
+		{
+			name: "Not a JSON object",
+			path: "/convert",
+			body: `[1, 2]`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fields := requestFields(tc.path, []byte(tc.body))
+			sort.Strings(fields)
+			if strings.Join(fields, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("fields = %v, want %v", fields, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFieldUsageSummary(t *testing.T) {
+	store := &memoryFieldUsageStore{objects: make(map[string][]byte)}
+	now := time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)
+	newInstance := func() *fieldUsageLog {
+		return &fieldUsageLog{sampleRate: 0.5, retention: 7, store: store, days: make(map[string]map[string]*clientFieldUsage)}
+	}
+
+	// Two instances flush to the same store, one keeps counts pending
+	first, second := newInstance(), newInstance()
+	first.record("client-a", []string{"openapi_spec", "strip_examples"}, now.AddDate(0, 0, -1))
+	first.record("client-a", []string{"openapi_spec"}, now)
+	second.record("client-b", []string{"openapi_spec"}, now)
+	if err := first.flush(context.Background(), now); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if err := second.flush(context.Background(), now); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	second.record("client-a", []string{"openapi_spec"}, now.Add(time.Minute))
+	if names, _ := store.list(context.Background(), fieldUsagePrefix); len(names) != 3 {
+		t.Errorf("stored objects = %v, want one per instance and day", names)
+	}
+
+	summary, err := second.summary(context.Background(), 7, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if summary.Scope != fieldUsageScopeBucket || summary.Since != "2024-05-01" || summary.Requests != 4 {
+		t.Errorf("summary = %s since %s with %d requests, want bucket since 2024-05-01 with 4", summary.Scope, summary.Since, summary.Requests)
+	}
+	if len(summary.Clients) != 2 || summary.Clients[0].APIKeyID != "client-a" || summary.Clients[0].Requests != 3 {
+		t.Fatalf("clients = %+v, want client-a with 3 requests first", summary.Clients)
+	}
+	if lastSeen := summary.Clients[0].LastSeen; lastSeen != "2024-05-07T09:01:00Z" {
+		t.Errorf("client-a last seen %s, want its pending request", lastSeen)
+	}
+	if len(summary.Fields) != 2 || summary.Fields[0].Field != "openapi_spec" || summary.Fields[0].Requests != 4 || len(summary.Fields[0].Clients) != 2 {
+		t.Errorf("fields = %+v, want openapi_spec sent 4 times by both clients", summary.Fields)
+	}
+	for _, field := range summary.UnusedFields {
+		if field == "openapi_spec" || field == "strip_examples" {
+			t.Errorf("unused_fields = %v, want the sent fields left out", summary.UnusedFields)
+		}
+	}
+
+	// Only the days asked for are counted
+	summary, err = second.summary(context.Background(), 1, now)
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if summary.Requests != 3 {
+		t.Errorf("today's requests = %d, want 3", summary.Requests)
+	}
+
+	// Partitions past retention are deleted on the next day's flush
+	later := now.AddDate(0, 0, 6)
+	if err := first.flush(context.Background(), later); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if names, _ := store.list(context.Background(), fieldUsagePrefix); len(names) != 2 {
+		t.Errorf("stored objects after retention = %v, want only 2024-05-07", names)
+	}
+
+	// Without a store the counts are the instance's own
+	local := &fieldUsageLog{sampleRate: 1, retention: 7, instance: "host-1", started: now, days: make(map[string]map[string]*clientFieldUsage)}
+	local.record("client-a", []string{"openapi_spec"}, now)
+	summary, err = local.summary(context.Background(), 7, now)
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if summary.Scope != fieldUsageScopeInstance || summary.Instance != "host-1" || summary.Requests != 1 {
+		t.Errorf("summary = %+v, want host-1's single request", summary)
+	}
+}
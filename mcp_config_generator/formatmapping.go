@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/converter"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// FormatMapping is the format_mapping request field: true enables the
+// default mapping and an object maps extra formats, or overrides defaults,
+// on top of it. false or absent leaves formats out of the tool arguments.
+type FormatMapping map[string]models.FormatConstraint
+
+func (m *FormatMapping) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		if enabled {
+			*m = defaultFormatMapping()
+		} else {
+			*m = nil
+		}
+		return nil
+	}
+
+	var custom map[string]models.FormatConstraint
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return fmt.Errorf("format_mapping must be a boolean or an object of format to {\"format\", \"pattern\"}")
+	}
+	mapping := defaultFormatMapping()
+	for format, constraint := range custom {
+		mapping[format] = constraint
+	}
+	*m = mapping
+	return nil
+}
+
+// defaultFormatMapping returns a copy of the library's default mapping, so
+// requests never modify the shared map.
+func defaultFormatMapping() FormatMapping {
+	mapping := make(FormatMapping, len(converter.DefaultFormatMapping))
+	for format, constraint := range converter.DefaultFormatMapping {
+		mapping[format] = constraint
+	}
+	return mapping
+}
@@ -4,7 +4,10 @@ go 1.20
 
 require (
 	cloud.google.com/go/storage v1.30.1
+	github.com/getkin/kin-openapi v0.118.0
 	github.com/higress-group/openapi-to-mcpserver v0.0.0-00010101000000-000000000000
+	github.com/klauspost/compress v1.16.7
+	golang.org/x/net v0.10.0
 	google.golang.org/api v0.126.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -14,7 +17,6 @@ require (
 	cloud.google.com/go/compute v1.19.3 // indirect
 	cloud.google.com/go/compute/metadata v0.2.3 // indirect
 	cloud.google.com/go/iam v0.13.0 // indirect
-	github.com/getkin/kin-openapi v0.118.0 // indirect
 	github.com/go-openapi/jsonpointer v0.19.5 // indirect
 	github.com/go-openapi/swag v0.19.5 // indirect
 	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
@@ -31,7 +33,6 @@ require (
 	github.com/perimeterx/marshmallow v1.1.4 // indirect
 	go.opencensus.io v0.24.0 // indirect
 	golang.org/x/crypto v0.9.0 // indirect
-	golang.org/x/net v0.10.0 // indirect
 	golang.org/x/oauth2 v0.8.0 // indirect
 	golang.org/x/sys v0.8.0 // indirect
 	golang.org/x/text v0.9.0 // indirect
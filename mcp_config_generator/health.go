@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// healthCheckObject is the object written by the readiness write check.
+const healthCheckObject = ".healthcheck"
+
+// writeCheck verifies that the service can write to the bucket. The result is
+// cached for interval so readiness probes don't write on every request.
+type writeCheck struct {
+	interval time.Duration
+	delete   bool
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	lastErr   error
+}
+
+// newWriteCheckFromEnv returns the write check configured by
+// HEALTH_CHECK_WRITE, HEALTH_CHECK_WRITE_INTERVAL and HEALTH_CHECK_DELETE, or
+// nil when HEALTH_CHECK_WRITE is not "true".
+func newWriteCheckFromEnv() (*writeCheck, error) {
+	if os.Getenv("HEALTH_CHECK_WRITE") != "true" {
+		return nil, nil
+	}
+
+	check := &writeCheck{
+		interval: 5 * time.Minute,
+		delete:   os.Getenv("HEALTH_CHECK_DELETE") == "true",
+	}
+	if interval := os.Getenv("HEALTH_CHECK_WRITE_INTERVAL"); interval != "" {
+		var err error
+		if check.interval, err = time.ParseDuration(interval); err != nil {
+			return nil, fmt.Errorf("invalid HEALTH_CHECK_WRITE_INTERVAL: %w", err)
+		}
+	}
+	return check, nil
+}
+
+// run writes the health check object unless the previous result is still
+// fresh, and returns the result of the latest write.
+func (c *writeCheck) run(ctx context.Context, bucket *storage.BucketHandle) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.interval {
+		return c.lastErr
+	}
+
+	c.lastErr = writeHealthCheckObject(ctx, bucket, c.delete)
+	c.checkedAt = time.Now()
+	return c.lastErr
+}
+
+func writeHealthCheckObject(ctx context.Context, bucket *storage.BucketHandle, deleteAfter bool) error {
+	obj := bucket.Object(healthCheckObject)
+
+	writer := obj.NewWriter(ctx)
+	writer.ContentType = "text/plain"
+	if _, err := writer.Write([]byte(time.Now().UTC().Format(time.RFC3339))); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write %s: %w", healthCheckObject, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", healthCheckObject, err)
+	}
+
+	if deleteAfter {
+		if err := obj.Delete(ctx); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", healthCheckObject, err)
+		}
+	}
+	return nil
+}
+
+// handleReady reports whether the service can reach its bucket, and with
+// HEALTH_CHECK_WRITE also whether it can write to it. It responds with 503
+// and the error when a check fails.
+func (s *ConversionService) handleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.warmup.ready() {
+		respondNotReady(w, "warmup", errWarmupPending)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	bucket := s.storageClient.Bucket(s.bucketName)
+	checks := map[string]string{}
+
+	it := bucket.Objects(ctx, &storage.Query{Prefix: healthCheckObject})
+	if _, err := it.Next(); err != nil && err != iterator.Done {
+		respondNotReady(w, "storage_read", err)
+		return
+	}
+	checks["storage_read"] = "ok"
+
+	if s.writeCheck != nil {
+		if err := s.writeCheck.run(ctx, bucket); err != nil {
+			respondNotReady(w, "storage_write", err)
+			return
+		}
+		checks["storage_write"] = "ok"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "ready",
+		"checks": checks,
+	})
+}
+
+func respondNotReady(w http.ResponseWriter, check string, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "not ready",
+		"check":  check,
+		"error":  err.Error(),
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+func TestJSONIndent(t *testing.T) {
+	config := &models.MCPConfig{Server: models.ServerConfig{Name: "orders"}}
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{name: "Default", body: `{}`, expected: "{\n  \"Server\""},
+		{name: "Number of spaces", body: `{"json_indent": 4}`, expected: "{\n    \"Server\""},
+		{name: "Tab", body: `{"json_indent": "\t"}`, expected: "{\n\t\"Server\""},
+		{name: "Zero is compact", body: `{"json_indent": 0}`, expected: `{"Server":{`},
+		{name: "Empty string is compact", body: `{"json_indent": ""}`, expected: `{"Server":{`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var req ConversionRequest
+			if err := json.Unmarshal([]byte(tc.body), &req); err != nil {
+				t.Fatalf("failed to decode the request: %v", err)
+			}
+			data, err := marshalConfig(config, "json", req.JSONIndent.String())
+			if err != nil {
+				t.Fatalf("marshalConfig: %v", err)
+			}
+			if !strings.HasPrefix(string(data), tc.expected) {
+				t.Errorf("config = %s, want it to start with %q", data, tc.expected)
+			}
+		})
+	}
+}
+
+func TestJSONIndentErrors(t *testing.T) {
+	service := &ConversionService{}
+	for body, expected := range map[string]string{
+		`{"json_indent": 17}`:   "json_indent must be between 0 and 16 spaces, got 17",
+		`{"json_indent": true}`: "json_indent must be a string or an integer",
+		`{"json_indent": `:      "Invalid JSON request",
+	} {
+		recorder := httptest.NewRecorder()
+		service.handleConvert(recorder, httptest.NewRequest(http.MethodPost, "/convert", strings.NewReader(body)))
+
+		var response ConversionResponse
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode the response to %s: %v", body, err)
+		}
+		if recorder.Code != http.StatusBadRequest || response.Error != expected {
+			t.Errorf("response to %s = %d %q, want 400 %q", body, recorder.Code, response.Error, expected)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import "github.com/higress-group/openapi-to-mcpserver/pkg/models"
+
+// DescriptionLanguage is the language detected in the description of an
+// operation, reported with filter_description_language. Language is an ISO
+// 639-1 code, or "und" when the description is too short or ambiguous.
+type DescriptionLanguage struct {
+	Path     string `json:"path"`
+	Method   string `json:"method"`
+	Language string `json:"language"`
+}
+
+func newDescriptionLanguages(detected []models.OperationLanguage) []DescriptionLanguage {
+	if len(detected) == 0 {
+		return nil
+	}
+	languages := make([]DescriptionLanguage, 0, len(detected))
+	for _, operation := range detected {
+		languages = append(languages, DescriptionLanguage{
+			Path:     operation.Path,
+			Method:   operation.Method,
+			Language: operation.Language,
+		})
+	}
+	return languages
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// logLevelHeader raises the log verbosity of a single request when
+// ALLOW_REQUEST_LOG_LEVEL is true.
+const logLevelHeader = "X-Log-Level"
+
+type debugLogKey struct{}
+
+// requestLogLevelFromEnv parses ALLOW_REQUEST_LOG_LEVEL.
+func requestLogLevelFromEnv() (bool, error) {
+	value := os.Getenv("ALLOW_REQUEST_LOG_LEVEL")
+	if value == "" {
+		return false, nil
+	}
+	allowed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("ALLOW_REQUEST_LOG_LEVEL must be true or false")
+	}
+	return allowed, nil
+}
+
+// withLogLevel enables debug logging for requests sent with X-Log-Level:
+// debug. When allowed is false the header is ignored, so clients can't raise
+// the verbosity of a production service.
+func withLogLevel(allowed bool, next http.Handler) http.Handler {
+	if !allowed {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(strings.TrimSpace(r.Header.Get(logLevelHeader)), "debug") {
+			r = r.WithContext(context.WithValue(r.Context(), debugLogKey{}, true))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// debugf logs a line for requests with debug logging enabled, tagged with
+// the request ID like the request log line.
+func debugf(ctx context.Context, format string, args ...interface{}) {
+	if enabled, _ := ctx.Value(debugLogKey{}).(bool); !enabled {
+		return
+	}
+	log.Printf("request_id=%s level=debug "+format, append([]interface{}{requestIDFromContext(ctx)}, args...)...)
+}
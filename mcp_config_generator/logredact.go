@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// storageLocationPattern matches bucket URIs and public storage URLs. The
+// last character excludes punctuation that usually ends a sentence.
+var storageLocationPattern = regexp.MustCompile(`(gs://|s3://|https://storage\.googleapis\.com/)([^/\s"'<>]*[^/\s"'<>.,:;)])((?:/[^\s"'<>]*[^\s"'<>.,:;)])?/?)`)
+
+// redactedBucket replaces bucket names in redacted log lines.
+const redactedBucket = "[redacted]"
+
+// logRedaction masks storage locations in log lines when REDACT_URLS_IN_LOGS
+// is true, so log vendors see neither bucket names nor object paths. Only the
+// logs are affected; responses keep the full URLs.
+var logRedaction *logRedactor
+
+// logRedactor rewrites every log line before it reaches the underlying
+// writer. Each object keeps its base name and a short hash of its full
+// location, so lines about the same object can still be correlated.
+type logRedactor struct {
+	out io.Writer
+	// buckets are names that are masked wherever they appear, not only in URLs
+	buckets []string
+}
+
+// newLogRedactorFromEnv parses REDACT_URLS_IN_LOGS. It returns nil when
+// redaction is disabled.
+func newLogRedactorFromEnv(out io.Writer, buckets []string) (*logRedactor, error) {
+	value := os.Getenv("REDACT_URLS_IN_LOGS")
+	if value == "" {
+		return nil, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, errors.New("REDACT_URLS_IN_LOGS must be true or false")
+	}
+	if !enabled {
+		return nil, nil
+	}
+	redactor := &logRedactor{out: out}
+	for _, bucket := range buckets {
+		if bucket != "" {
+			redactor.buckets = append(redactor.buckets, bucket)
+		}
+	}
+	return redactor, nil
+}
+
+// Write redacts one log line. The log package calls it once per line.
+func (l *logRedactor) Write(p []byte) (int, error) {
+	if _, err := l.out.Write([]byte(l.redact(string(p)))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// redact masks the storage locations and bucket names in text.
+func (l *logRedactor) redact(text string) string {
+	text = storageLocationPattern.ReplaceAllStringFunc(text, func(location string) string {
+		parts := storageLocationPattern.FindStringSubmatch(location)
+		objectPath := strings.Trim(parts[3], "/")
+		if objectPath == "" {
+			return parts[1] + redactedBucket
+		}
+		return parts[1] + redactedBucket + "/" + redactedObjectName(location, objectPath)
+	})
+	for _, bucket := range l.buckets {
+		text = strings.ReplaceAll(text, bucket, redactedBucket)
+	}
+	return text
+}
+
+// redactedObjectName is the hash of an object's full location followed by
+// its base name.
+func redactedObjectName(location, objectPath string) string {
+	sum := sha256.Sum256([]byte(location))
+	return hex.EncodeToString(sum[:4]) + "/" + path.Base(objectPath)
+}
+
+// logObjectName returns the object name to log: the name itself, or its
+// redacted form when log redaction is enabled.
+func logObjectName(name string) string {
+	if logRedaction == nil {
+		return name
+	}
+	return redactedObjectName(name, name)
+}
@@ -4,57 +4,545 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"cloud.google.com/go/storage"
 	"github.com/higress-group/openapi-to-mcpserver/pkg/converter"
 	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
 	"github.com/higress-group/openapi-to-mcpserver/pkg/parser"
-	"google.golang.org/api/option"
+	"google.golang.org/api/googleapi"
 	"gopkg.in/yaml.v3"
 )
 
 type ConversionRequest struct {
-	OpenAPISpec    string `json:"openapi_spec"`
-	ServerName     string `json:"server_name,omitempty"`
-	ToolPrefix     string `json:"tool_prefix,omitempty"`
-	Format         string `json:"format,omitempty"`
-	Validate       bool   `json:"validate,omitempty"`
-	TemplateConfig string `json:"template_config,omitempty"`
+	OpenAPISpec    string  `json:"openapi_spec"`
+	ServerName     string  `json:"server_name,omitempty"`
+	ToolPrefix     string  `json:"tool_prefix,omitempty"`
+	Format         string  `json:"format,omitempty"`
+	Validate       bool    `json:"validate,omitempty"`
+	TemplateConfig string  `json:"template_config,omitempty"`
+	JSONIndent     *Indent `json:"json_indent,omitempty"`
+
+	// SourceFormat is "markdown" when OpenAPISpec is a Markdown document
+	// holding the spec in a fenced code block, "openapi" when it is the
+	// spec itself, or "auto" (the default) to detect Markdown.
+	SourceFormat string `json:"source_format,omitempty"`
+	// SourceType forces the input type instead of detecting it: "openapi"
+	// or "markdown", like the matching source_format.
+	SourceType string `json:"source_type,omitempty"`
+
+	// OutputFormat "github-annotations" returns the warnings and error as
+	// GitHub Actions workflow commands instead of the JSON response, with
+	// AnnotationFile, the spec's path in the repository, as their file.
+	OutputFormat   string `json:"output_format,omitempty"`
+	AnnotationFile string `json:"annotation_file,omitempty"`
+
+	// OutputExtension overrides the extension of the stored MCP config, e.g.
+	// "yml" or "mcp.json". The content type still follows Format.
+	OutputExtension string `json:"output_extension,omitempty"`
+	Environment     string `json:"environment,omitempty"`
+
+	RequireSuccessResponse bool `json:"require_success_response,omitempty"`
+	StoreDiagnostics       bool `json:"store_diagnostics,omitempty"`
+	DetectCycles           bool `json:"detect_cycles,omitempty"`
+
+	// IncludeResourceStats returns the wall time, CPU time and allocations
+	// of the conversion in resource_stats, see ResourceStats for how
+	// precise they are
+	IncludeResourceStats bool `json:"include_resource_stats,omitempty"`
+
+	// AllowedHosts rejects the conversion with 422 when a tool calls a host
+	// that matches none of these hostnames or globs, e.g. *.example.com.
+	// TemplatedHosts is "reject" (the default) or "skip" for tools whose host
+	// is templated or missing.
+	AllowedHosts   []string `json:"allowed_hosts,omitempty"`
+	TemplatedHosts string   `json:"templated_hosts,omitempty"`
+
+	// CacheControl and StorageHeaders override the Cache-Control and extra
+	// response headers set on the stored objects.
+	CacheControl   string            `json:"cache_control,omitempty"`
+	StorageHeaders map[string]string `json:"storage_headers,omitempty"`
+	// NoOverwrite rejects the conversion with 409 Conflict when an object it
+	// writes is already stored.
+	NoOverwrite bool `json:"no_overwrite,omitempty"`
+
+	// Bundle packages the config in each of BundleFormats (default: yaml and
+	// json) together with the source spec into a zip archive.
+	Bundle        bool     `json:"bundle,omitempty"`
+	BundleFormats []string `json:"bundle_formats,omitempty"`
+
+	// SplitBy "tag" makes the bundle a tar.gz of one config per operation tag
+	// with a manifest, instead of the zip. It requires Bundle.
+	SplitBy string `json:"split_by,omitempty"`
+
+	// DescriptionTemplate is a text/template rendered for each tool's
+	// description with .Method, .Path, .Summary, .Description, .OperationId
+	// and .ExternalDocs.
+	DescriptionTemplate string `json:"description_template,omitempty"`
+
+	// SecretPlaceholderTemplate is a text/template rendered as each security
+	// scheme's defaultCredential with .SchemeName, .EnvName, .Type, .Scheme,
+	// .In and .Name. The default renders ${ENV_NAME}.
+	SecretPlaceholderTemplate string `json:"secret_placeholder_template,omitempty"`
+
+	// DescriptionSource picks the operation fields tool descriptions are made
+	// of: description, summary, both (the default) or
+	// summary_then_description. description_template takes precedence.
+	DescriptionSource string `json:"description_source,omitempty"`
+
+	// PreferredRequestMedia lists request body content types in order of
+	// preference, e.g. ["application/json", "multipart/form-data"].
+	PreferredRequestMedia []string `json:"preferred_request_media,omitempty"`
+
+	// PathPrefix is prepended to every operation path in the tools' request
+	// URLs, after StripPathPrefix has been removed from it
+	PathPrefix      string `json:"path_prefix,omitempty"`
+	StripPathPrefix string `json:"strip_path_prefix,omitempty"`
+
+	// DefaultRequestContentType is sent by tools whose request body declares
+	// no content type (default: application/json)
+	DefaultRequestContentType string `json:"default_request_content_type,omitempty"`
+
+	// MaxSchemaDepth limits how many levels of nested schemas are expanded
+	// in tool arguments and response descriptions. 0 means the default of 10.
+	MaxSchemaDepth int `json:"max_schema_depth,omitempty"`
+
+	// PropagateTags copies the OpenAPI operation tags into the tools
+	PropagateTags bool `json:"propagate_tags,omitempty"`
+
+	// GenerateChangelog compares the tools with the previous conversion of
+	// the same server and stores a changelog
+	GenerateChangelog bool `json:"generate_changelog,omitempty"`
+
+	// StrictOperationIDs rejects specs where operations share an
+	// operationId instead of keeping the first one with a warning
+	StrictOperationIDs bool `json:"strict_operation_ids,omitempty"`
+
+	// StrictNames rejects specs producing tool names that aren't valid MCP
+	// identifiers instead of sanitizing them with a warning
+	StrictNames bool `json:"strict_names,omitempty"`
+
+	// OperationIDStripPrefix is a regular expression removed from the start
+	// of each operationId before it becomes a tool name, e.g. billing\.
+	OperationIDStripPrefix string `json:"operation_id_strip_prefix,omitempty"`
+
+	// SafeMode keeps only the GET, HEAD and OPTIONS operations and marks
+	// the config read-only, for toolsets that must not change anything
+	SafeMode bool `json:"safe_mode,omitempty"`
+
+	// IncludePathRegex and ExcludePathRegex filter the operations by path
+	// before conversion. Exclude wins when a path matches both.
+	IncludePathRegex string `json:"include_path_regex,omitempty"`
+	ExcludePathRegex string `json:"exclude_path_regex,omitempty"`
+
+	// IncludePathGlobs and ExcludePathGlobs filter the operations by path
+	// globs, combined with the regexes: an operation is kept when it matches
+	// any include filter, or there are none, and no exclude filter.
+	IncludePathGlobs []string `json:"include_path_globs,omitempty"`
+	ExcludePathGlobs []string `json:"exclude_path_globs,omitempty"`
+
+	// AnnotateAuth records on each tool which security schemes it requires
+	AnnotateAuth bool `json:"annotate_auth,omitempty"`
+
+	// AnnotateSource records the HTTP method and path each tool was
+	// generated from in its source field
+	AnnotateSource bool `json:"annotate_source,omitempty"`
+
+	// StripExamples removes examples from the spec before conversion
+	StripExamples bool `json:"strip_examples,omitempty"`
+
+	// PromoteExamplesToDefaults uses parameter examples as defaults for
+	// optional parameters without one
+	PromoteExamplesToDefaults bool `json:"promote_examples_to_defaults,omitempty"`
+
+	// SynthesizeExamples generates an example for each argument without one,
+	// from its type, format, enum and bounds
+	SynthesizeExamples bool `json:"synthesize_examples,omitempty"`
+
+	// RedactPatterns are regular expressions whose matches in spec values are
+	// replaced before the spec is stored. Conversion uses the original spec.
+	RedactPatterns []string `json:"redact_patterns,omitempty"`
+
+	// OperationOrder sorts the generated tools: operationId (default), path,
+	// method, tag or spec
+	OperationOrder string `json:"operation_order,omitempty"`
+
+	// ForceRequired maps operationIds to parameter names that are marked as
+	// required in the generated tools regardless of the spec
+	ForceRequired map[string][]string `json:"force_required,omitempty"`
+
+	// InitiallyDisabled lists operationIds or tool names whose tools are
+	// emitted with disabled: true, for staged rollouts
+	InitiallyDisabled []string `json:"initially_disabled,omitempty"`
+
+	// ResponseSizeHint maps operationIds to a maximum response size in bytes
+	// or "paginate", overriding the operations' x-response-size extensions
+	ResponseSizeHint map[string]models.ResponseSizeHint `json:"response_size_hint,omitempty"`
+
+	// FormatMapping adds JSON Schema constraints for OpenAPI formats such as
+	// uuid and date-time to the tool arguments, see FormatMapping
+	FormatMapping FormatMapping `json:"format_mapping,omitempty"`
+
+	// IncludeResponseHeaders lists the headers documented on each operation's
+	// success response in the tool's responseHeaders and description
+	IncludeResponseHeaders bool `json:"include_response_headers,omitempty"`
+
+	// IncludeEnumDescriptions appends the x-enum-descriptions of enum
+	// parameters to their descriptions as a value: meaning list
+	IncludeEnumDescriptions bool `json:"include_enum_descriptions,omitempty"`
+
+	// StrictNumericConstraints types untyped parameters from their numeric
+	// format and keeps the bounds of integer parameters integral
+	StrictNumericConstraints bool `json:"strict_numeric_constraints,omitempty"`
+
+	// BodyWrapper sends each tool's JSON request body under this key, e.g.
+	// "data" for {"data": {...}}
+	BodyWrapper string `json:"body_wrapper,omitempty"`
+
+	// ParamNameCase renames tool arguments to camel, snake or kebab case,
+	// keeping the original names in each tool's paramMapping
+	ParamNameCase string `json:"param_name_case,omitempty"`
+
+	// NullHandling writes nullable arguments as nullable: true ("nullable")
+	// or as type: [T, "null"] ("type-array"). Empty follows the spec's
+	// OpenAPI version.
+	NullHandling string `json:"null_handling,omitempty"`
+
+	// EmptyParamHandling leaves unset optional parameters out of requests
+	// ("omit") or sends string parameters as empty strings ("send-empty")
+	EmptyParamHandling string `json:"empty_param_handling,omitempty"`
+
+	// MissingBodySchema is how request bodies without a schema are taken:
+	// as an open object ("free-form", the default), a raw "string", or
+	// "skip" to leave their operations out
+	MissingBodySchema string `json:"missing_body_schema,omitempty"`
+
+	// ResponseCodesInDescription picks the response codes summarized in
+	// each tool description: "success-only" (the default), "all", "none",
+	// or an array of codes such as ["200", "404", "5XX"]
+	ResponseCodesInDescription ResponseCodeSelection `json:"response_codes_in_description,omitempty"`
+
+	// MaxEnumValues keeps the first values of longer enums, 0 for no
+	// limit. EnumOverflow "string" drops such enums instead.
+	MaxEnumValues int    `json:"max_enum_values,omitempty"`
+	EnumOverflow  string `json:"enum_overflow,omitempty"`
+
+	// ExtensionPassthrough lists the operation vendor extensions, e.g.
+	// x-dangerous, copied verbatim into each tool's metadata
+	ExtensionPassthrough []string `json:"extension_passthrough,omitempty"`
+
+	// ParameterConflictMode resolves parameters declared at both the path
+	// and the operation level: "operation-wins" (the default), "path-wins"
+	// or "error"
+	ParameterConflictMode string `json:"parameter_conflict_mode,omitempty"`
+
+	// FilterDescriptionLanguage is the ISO 639-1 code of the language the
+	// operation descriptions should be in. DescriptionLanguageMode "skip"
+	// (the default) leaves out the operations detected in another language,
+	// "tag" sets the language of their tools.
+	FilterDescriptionLanguage string `json:"filter_description_language,omitempty"`
+	DescriptionLanguageMode   string `json:"description_language_mode,omitempty"`
+
+	// CoerceTypes marks integer, number and boolean arguments for coercion
+	// of string-encoded values. CoercionPolicy overrides the policy per
+	// type: "none", "hint" (the default) or "relax" to also accept strings.
+	CoerceTypes    bool              `json:"coerce_types,omitempty"`
+	CoercionPolicy map[string]string `json:"coercion_policy,omitempty"`
+
+	// DefaultArrayStyle is the serialization of array parameters that set
+	// neither style nor explode: csv, multi, pipe or space
+	DefaultArrayStyle string `json:"default_array_style,omitempty"`
+
+	// RefStrategy is "inline" (the default) or "shared-components" to write
+	// referenced component schemas once and point to them with $ref
+	RefStrategy string `json:"ref_strategy,omitempty"`
+
+	// EmitSpecInfo adds a $id and an info block with the spec's title,
+	// version and source hash to the generated config
+	EmitSpecInfo bool `json:"emit_spec_info,omitempty"`
+
+	// VerifyServers checks that each base server URL of the spec answers and
+	// reports the unreachable ones as warnings
+	VerifyServers bool `json:"verify_servers,omitempty"`
+
+	// RequestHTTP2, RequestKeepalive and RequestMaxRedirects are HTTP client
+	// settings for the tools' backend requests, unset by default
+	RequestHTTP2        *bool `json:"request_http2,omitempty"`
+	RequestKeepalive    *bool `json:"request_keepalive,omitempty"`
+	RequestMaxRedirects *int  `json:"request_max_redirects,omitempty"`
+
+	// CoverageReport returns a report of how many operations became tools,
+	// by tag and method, and StoreCoverageReport also stores it
+	CoverageReport      bool `json:"coverage_report,omitempty"`
+	StoreCoverageReport bool `json:"store_coverage_report,omitempty"`
+
+	// ConverterOptions sets converter options by name, see
+	// converterOptionSetters for the supported keys
+	ConverterOptions map[string]json.RawMessage `json:"converter_options,omitempty"`
+
+	// PublishPackage publishes the config as an npm package named after the
+	// server and versioned by the spec's info.version
+	PublishPackage *PublishPackageOptions `json:"publish_package,omitempty"`
+
+	// PipelineOrder reorders the steps applied to the config after the
+	// operations are converted; unlisted steps keep their default order
+	PipelineOrder []string `json:"pipeline_order,omitempty"`
+}
+
+// Indent is a JSON indentation given either as a whitespace string or as a
+// number of spaces. An empty indentation, "" or 0, writes compact JSON.
+type Indent string
+
+// defaultIndent is the indentation when json_indent isn't set.
+const defaultIndent = Indent("  ")
+
+// String returns the indentation, the default when it isn't set.
+func (i *Indent) String() string {
+	if i == nil {
+		return string(defaultIndent)
+	}
+	return string(*i)
+}
+
+func (i *Indent) UnmarshalJSON(data []byte) error {
+	var count int
+	if err := json.Unmarshal(data, &count); err == nil {
+		if count < 0 || count > 16 {
+			return newAPIError(http.StatusBadRequest, "json_indent must be between 0 and 16 spaces, got %d", count)
+		}
+		*i = Indent(strings.Repeat(" ", count))
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return newAPIError(http.StatusBadRequest, "json_indent must be a string or an integer")
+	}
+	*i = Indent(value)
+	return nil
 }
 
 type UploadRequest struct {
-	FileContent string `json:"file_content"`
-	FileName    string `json:"file_name,omitempty"`
-	Format      string `json:"format,omitempty"`
+	FileContent    string            `json:"file_content"`
+	FileName       string            `json:"file_name,omitempty"`
+	Format         string            `json:"format,omitempty"`
+	CacheControl   string            `json:"cache_control,omitempty"`
+	StorageHeaders map[string]string `json:"storage_headers,omitempty"`
+	NoOverwrite    bool              `json:"no_overwrite,omitempty"`
+	// SourceType forces the file type instead of detecting it: "openapi",
+	// "swagger" or "mcp_config".
+	SourceType string `json:"source_type,omitempty"`
+	// OutputExtension overrides the extension of an uploaded MCP config, as
+	// output_extension does for converted ones
+	OutputExtension string `json:"output_extension,omitempty"`
 }
 
 type ConversionResponse struct {
-	Success           bool   `json:"success"`
-	MCPConfig         string `json:"mcp_config,omitempty"`
-	Error             string `json:"error,omitempty"`
-	Format            string `json:"format"`
-	ServerName        string `json:"server_name"`
-	OpenAPIFileURL    string `json:"openapi_file_url,omitempty"`
-	MCPConfigFileURL  string `json:"mcp_config_file_url,omitempty"`
+	Success          bool            `json:"success"`
+	MCPConfig        string          `json:"mcp_config,omitempty"`
+	Error            string          `json:"error,omitempty"`
+	Format           string          `json:"format"`
+	ServerName       string          `json:"server_name"`
+	OpenAPIFileURL   string          `json:"openapi_file_url,omitempty"`
+	MCPConfigFileURL string          `json:"mcp_config_file_url,omitempty"`
+	Warnings         []string        `json:"warnings,omitempty"`
+	DiagnosticsURL   string          `json:"diagnostics_url,omitempty"`
+	BundleURL        string          `json:"bundle_url,omitempty"`
+	DryRun           bool            `json:"dry_run,omitempty"`
+	Coverage         *CoverageReport `json:"coverage,omitempty"`
+	CoverageURL      string          `json:"coverage_url,omitempty"`
+	Changelog        *Changelog      `json:"changelog,omitempty"`
+	ChangelogURL     string          `json:"changelog_url,omitempty"`
+	ToolCache        *ToolCacheStats `json:"tool_cache,omitempty"`
+	Files            []StoredFile    `json:"files,omitempty"`
+
+	EffectiveOptions *EffectiveOptions `json:"effective_options,omitempty"`
+	// Package are the coordinates of the config published with publish_package
+	Package *PublishedPackage `json:"package,omitempty"`
+	// Pipeline lists the post-conversion steps applied, in the order they ran
+	Pipeline []string `json:"pipeline,omitempty"`
+	// Signature is the detached signature of the config, with SIGN_OUTPUT
+	Signature *ConfigSignature `json:"signature,omitempty"`
+	// ResourceStats is what the conversion cost, with include_resource_stats
+	ResourceStats *ResourceStats `json:"resource_stats,omitempty"`
+	// Cached is set when the result of an earlier identical conversion was
+	// returned, with CONVERSION_CACHE_TTL or REDIS_URL
+	Cached bool `json:"cached,omitempty"`
+	// DescriptionLanguages is the language detected for each operation, with
+	// filter_description_language
+	DescriptionLanguages []DescriptionLanguage `json:"description_languages,omitempty"`
+
+	// mcpConfigObject and openAPIObject are the bucket object names of the
+	// stored MCP config and spec.
+	mcpConfigObject string
+	openAPIObject   string
+	// spec is the converted spec, after it was read from its source.
+	spec string
 }
 
 type UploadResponse struct {
-	Success    bool   `json:"success"`
-	Error      string `json:"error,omitempty"`
-	FileType   string `json:"file_type"`
-	PublicURL  string `json:"public_url,omitempty"`
-	FileName   string `json:"file_name,omitempty"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+	FileType  string `json:"file_type"`
+	PublicURL string `json:"public_url,omitempty"`
+	FileName  string `json:"file_name,omitempty"`
+	DryRun    bool   `json:"dry_run,omitempty"`
+}
+
+// apiError is an error that carries the HTTP status it should be reported with.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func newAPIError(status int, format string, args ...interface{}) *apiError {
+	return &apiError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// requestDecodeError is the message for a request body that failed to
+// decode: the error of the field that rejected its value, such as
+// json_indent, or a generic one for malformed JSON.
+func requestDecodeError(err error) string {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.message
+	}
+	return "Invalid JSON request"
+}
+
+// errorStatus returns the HTTP status for err, defaulting to 500.
+func errorStatus(err error) int {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.status
+	}
+	return http.StatusInternalServerError
 }
 
 type ConversionService struct {
-	storageClient *storage.Client
-	bucketName    string
+	storageClient       *storage.Client
+	bucketName          string
+	webhookSecret       string
+	allowedEnvironments []string
+	cacheControl        string
+	stats               *statsdClient
+	quota               *quotaEnforcer
+	specSourceBuckets   []string
+	defaultFormat       string
+	writeCheck          *writeCheck
+	storageDryRun       bool
+	writeLimiter        *writeLimiter
+	toolCache           *toolCache
+	maxSpecBytes        int64
+	outputExtensions    map[string]string
+	warmup              *warmup
+	audit               *auditLog
+
+	// servers checks verify_servers, nil unless VERIFY_SERVER_HOSTS is set
+	servers *serverVerifier
+	// publishRegistryHosts are the registries publish_package may upload
+	// to, none when empty
+	publishRegistryHosts []string
+	// signer signs the stored configs, nil unless SIGN_OUTPUT is set
+	signer *outputSigner
+	// results caches whole conversions, nil unless CONVERSION_CACHE_TTL or
+	// REDIS_URL is set
+	results *resultCache
+}
+
+// storageOptions customizes how an object is served from the bucket.
+type storageOptions struct {
+	// CacheControl overrides the service-wide Cache-Control value
+	CacheControl string
+	// Headers are extra response headers stored as object metadata for the CDN
+	Headers map[string]string
+	// NoOverwrite fails the write with an objectExistsError when the object
+	// is already stored
+	NoOverwrite bool
+}
+
+// headerNamePattern matches valid HTTP header field names.
+var headerNamePattern = regexp.MustCompile(`^[A-Za-z0-9!#$%&'*+.^_|~-]+$`)
+
+// validateStorageHeaders checks that every storage header name is a valid HTTP
+// header and that no value contains line breaks.
+func validateStorageHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid storage header name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid value for storage header %q", name)
+		}
+	}
+	return nil
+}
+
+// coercionPolicies returns the coercion policy of each type for a request,
+// nil when coerce_types is off.
+func coercionPolicies(req ConversionRequest) map[string]string {
+	if !req.CoerceTypes {
+		return nil
+	}
+	policies := converter.DefaultCoercion()
+	for schemaType, policy := range req.CoercionPolicy {
+		policies[schemaType] = policy
+	}
+	return policies
+}
+
+// validateCoercionPolicy checks the types and policies of a coercion policy
+// map.
+func validateCoercionPolicy(policies map[string]string) error {
+	for schemaType, policy := range policies {
+		if !containsString(converter.CoercibleTypes, schemaType) {
+			return fmt.Errorf("type %q must be one of: %s", schemaType, strings.Join(converter.CoercibleTypes, ", "))
+		}
+		if !containsString(converter.CoercionPolicies, policy) {
+			return fmt.Errorf("policy %q for %s must be one of: %s", policy, schemaType, strings.Join(converter.CoercionPolicies, ", "))
+		}
+	}
+	return nil
+}
+
+// defaultEnvironments is the environment allowlist used when
+// ALLOWED_ENVIRONMENTS is not set.
+var defaultEnvironments = []string{"dev", "staging", "prod"}
+
+// tempDir is the directory conversions write their temporary files to, set
+// from TEMP_DIR. Empty means the system temp directory.
+var tempDir string
+
+// checkTempDir verifies that dir exists and that temporary files can be
+// created in it.
+func checkTempDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	probe, err := os.CreateTemp(dir, "write-check-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
 }
 
 func main() {
@@ -68,35 +556,251 @@ func main() {
 		log.Fatal("FIREBASE_STORAGE_BUCKET environment variable is required")
 	}
 
-	// Initialize Firebase Storage client
+	// Initialize Firebase Storage client with a connection pool sized for
+	// batch writes
 	ctx := context.Background()
-	var storageClient *storage.Client
-	var err error
-
-	// If running locally, use service account key
-	if serviceAccountPath := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); serviceAccountPath != "" {
-		storageClient, err = storage.NewClient(ctx, option.WithCredentialsFile(serviceAccountPath))
-	} else {
-		// Use default credentials (works in Cloud Run)
-		storageClient, err = storage.NewClient(ctx)
+	storageTransport, err := storageTransportFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid storage connection configuration: %v", err)
 	}
 
+	// If running locally, use service account key; otherwise use default
+	// credentials (works in Cloud Run)
+	storageClient, err := newStorageClient(ctx, storageTransport, os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
 	if err != nil {
 		log.Fatalf("Failed to create storage client: %v", err)
 	}
 
 	service := &ConversionService{
-		storageClient: storageClient,
-		bucketName:    bucketName,
+		storageClient:       storageClient,
+		bucketName:          bucketName,
+		webhookSecret:       os.Getenv("WEBHOOK_SECRET"),
+		allowedEnvironments: defaultEnvironments,
+		cacheControl:        "public, max-age=300",
+		specSourceBuckets:   splitList(os.Getenv("SPEC_SOURCE_BUCKETS")),
+		defaultFormat:       "yaml",
+		storageDryRun:       os.Getenv("STORAGE_DRY_RUN") == "true",
+	}
+	service.publishRegistryHosts = publishRegistryHostsFromEnv()
+	service.servers = newServerVerifierFromEnv()
+
+	// Optionally sign the generated configs
+	service.signer, err = newOutputSignerFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid signing configuration: %v", err)
+	}
+	if service.signer != nil {
+		log.Printf("Signing configs with Ed25519 key %s", service.signer.keyID)
+		publicEndpoints = append(publicEndpoints, endpointInfo{Method: http.MethodGet, Path: "/pubkey", Description: "Public key of the config signatures"})
+	}
+
+	// Optionally keep bucket names and object paths out of the logs
+	logRedaction, err = newLogRedactorFromEnv(os.Stderr, append([]string{bucketName}, service.specSourceBuckets...))
+	if err != nil {
+		log.Fatalf("Invalid log redaction configuration: %v", err)
+	}
+	if logRedaction != nil {
+		log.SetOutput(logRedaction)
+		log.Printf("Redacting storage URLs in logs")
+	}
+	if defaultFormat := os.Getenv("DEFAULT_FORMAT"); defaultFormat != "" {
+		if !containsString(supportedFormats, defaultFormat) {
+			log.Fatalf("Invalid DEFAULT_FORMAT %q, must be one of: %s", defaultFormat, strings.Join(supportedFormats, ", "))
+		}
+		service.defaultFormat = defaultFormat
+	}
+	service.outputExtensions, err = parseOutputExtensions(os.Getenv("OUTPUT_EXTENSIONS"))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if cacheControl := os.Getenv("STORAGE_CACHE_CONTROL"); cacheControl != "" {
+		service.cacheControl = cacheControl
+	}
+
+	// Optionally enforce storage quotas
+	service.quota, err = newQuotaEnforcerFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid storage quota configuration: %v", err)
+	}
+
+	// Optionally alert before storage quotas are reached
+	quotaAlerts, err := newQuotaAlerterFromEnv(service.webhookSecret)
+	if err != nil {
+		log.Fatalf("Invalid quota alert configuration: %v", err)
+	}
+	if quotaAlerts != nil {
+		if service.quota == nil {
+			log.Fatalf("QUOTA_ALERT_WEBHOOK requires STORAGE_MAX_OBJECTS, STORAGE_MAX_BYTES or STORAGE_PREFIX_QUOTAS")
+		}
+		go quotaAlerts.run(context.Background(), service)
+		log.Printf("Sending quota alerts at %v%% usage, checked every %s", quotaAlerts.thresholds, quotaAlerts.interval)
+	}
+
+	// Optionally expire old specs and configs
+	retention, err := newRetentionJanitorFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid retention configuration: %v", err)
+	}
+	if retention != nil {
+		go retention.run(context.Background(), service)
+		log.Printf("Deleting specs and configs older than %s, swept every %s", retention.maxAge, retention.interval)
+	}
+
+	// Optionally pace storage writes
+	service.writeLimiter, err = newWriteLimiter(os.Getenv("STORAGE_WRITE_RPS"), os.Getenv("STORAGE_WRITE_MAX_WAIT"))
+	if err != nil {
+		log.Fatalf("Invalid storage write rate: %v", err)
 	}
 
-	http.HandleFunc("/convert", service.handleConvert)
-	http.HandleFunc("/upload", service.handleUpload)
+	service.maxSpecBytes, err = parseMaxSpecBytes(os.Getenv("MAX_SPEC_BYTES"))
+	if err != nil {
+		log.Fatalf("Invalid spec size limit: %v", err)
+	}
+
+	// Optionally reuse the tools of unchanged operations
+	service.toolCache, err = newToolCache(os.Getenv("TOOL_CACHE_SIZE"))
+	if err != nil {
+		log.Fatalf("Invalid tool cache configuration: %v", err)
+	}
+
+	// Optionally verify write access in the readiness check
+	service.writeCheck, err = newWriteCheckFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid health check configuration: %v", err)
+	}
+
+	// Optionally keep an audit record of every conversion
+	service.audit, err = newAuditLogFromEnv(bucketName, service.webhookSecret)
+	if err != nil {
+		log.Fatalf("Invalid audit log configuration: %v", err)
+	}
+	if service.audit != nil {
+		log.Printf("Writing conversion audit records to %s", service.audit)
+	}
+
+	// Optionally prime the conversion libraries before reporting ready
+	service.warmup, err = newWarmupFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid warm-up configuration: %v", err)
+	}
+	if service.warmup != nil {
+		go service.warmup.run()
+	}
+
+	// Optionally export metrics to StatsD
+	if statsdAddr := os.Getenv("STATSD_ADDR"); statsdAddr != "" {
+		prefix := os.Getenv("STATSD_PREFIX")
+		if prefix == "" {
+			prefix = "mcp_converter."
+		}
+		service.stats, err = newStatsdClient(statsdAddr, prefix)
+		if err != nil {
+			log.Fatalf("Failed to create StatsD client: %v", err)
+		}
+		log.Printf("Sending StatsD metrics to %s", statsdAddr)
+	}
+
+	// Optionally cache conversion results, in Redis when REDIS_URL is set
+	service.results, err = newResultCacheFromEnv(service.stats)
+	if err != nil {
+		log.Fatalf("Invalid conversion cache configuration: %v", err)
+	}
+	if service.results != nil {
+		log.Printf("Caching conversion results in %s", service.results)
+	}
+	if dir := os.Getenv("TEMP_DIR"); dir != "" {
+		if err := checkTempDir(dir); err != nil {
+			log.Fatalf("Invalid TEMP_DIR: %v", err)
+		}
+		tempDir = dir
+		log.Printf("Writing temporary files to %s", tempDir)
+	}
+	if environments := os.Getenv("ALLOWED_ENVIRONMENTS"); environments != "" {
+		service.allowedEnvironments = splitList(environments)
+	}
+
+	// Optionally reject writes during storage maintenance
+	maintenance, err := newMaintenanceModeFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid maintenance configuration: %v", err)
+	}
+	adminToken := os.Getenv("ADMIN_TOKEN")
+
+	// Optionally log which request fields clients send
+	fieldUsage, err := newFieldUsageLogFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid field usage configuration: %v", err)
+	}
+	if fieldUsage != nil {
+		if !service.storageDryRun {
+			fieldUsage.store = &bucketFieldUsageStore{bucket: storageClient.Bucket(bucketName)}
+			go fieldUsage.run(context.Background())
+		}
+		log.Printf("Logging request field usage of %g of requests for %d days", fieldUsage.sampleRate, fieldUsage.retention)
+	}
+
+	http.HandleFunc("/convert", maintenance.guard(fieldUsage.track(service.handleConvert)))
+	http.HandleFunc("/convert/batch", maintenance.guard(fieldUsage.track(service.handleBatchConvert)))
+	http.HandleFunc("/upload", maintenance.guard(service.handleUpload))
+	http.HandleFunc("/tool-preview", handleToolPreview)
 	http.HandleFunc("/health", handleHealth)
+	http.HandleFunc("/health/live", handleHealth)
+	http.HandleFunc("/health/ready", service.handleReady)
+	http.HandleFunc("/pubkey", service.handlePublicKey)
+	http.HandleFunc("/admin/maintenance", requireAdmin(adminToken, maintenance.handleMaintenance))
+	http.HandleFunc("/admin/field-usage", requireAdmin(adminToken, fieldUsage.handleFieldUsage))
+
+	allowRequestLogLevel, err := requestLogLevelFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid log level configuration: %v", err)
+	}
+	if allowRequestLogLevel {
+		log.Printf("Requests may enable debug logging with %s: debug", logLevelHeader)
+	}
+
+	// Catch-all for unknown paths, must stay the last route. The optional UI
+	// is served from the same route.
+	enableUI, err := uiEnabledFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid UI configuration: %v", err)
+	}
+	if enableUI {
+		http.HandleFunc("/", withUI(handleNotFound))
+		log.Printf("Web UI enabled at /")
+	} else {
+		http.HandleFunc("/", handleNotFound)
+	}
 
 	log.Printf("Server starting on port %s", port)
 	log.Printf("Using Firebase Storage bucket: %s", bucketName)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	log.Printf("Default output format: %s", service.defaultFormat)
+	if service.maxSpecBytes > 0 {
+		log.Printf("Maximum spec size: %d bytes", service.maxSpecBytes)
+	} else {
+		log.Printf("Maximum spec size: unlimited")
+	}
+	if service.storageDryRun {
+		log.Printf("Storage dry run enabled: nothing will be written to the bucket")
+	}
+
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: withRequestID(requestIDHeaderFromEnv(), withLogLevel(allowRequestLogLevel, withCompression(withResponseCase(http.DefaultServeMux)))),
+	}
+	if err := configureTimeouts(server); err != nil {
+		log.Fatalf("Invalid server timeout: %v", err)
+	}
+	h2cEnabled, err := h2cEnabledFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid h2c configuration: %v", err)
+	}
+	if h2cEnabled {
+		enableH2C(server)
+		log.Printf("HTTP/2 cleartext (h2c) enabled")
+	}
+	if err := runServer(server); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func (s *ConversionService) handleConvert(w http.ResponseWriter, r *http.Request) {
@@ -105,47 +809,348 @@ func (s *ConversionService) handleConvert(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	version, err := requestedAPIVersion(r)
+	if err != nil {
+		respondWithError(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("X-API-Version", strconv.Itoa(version))
+
 	// Parse JSON request
 	var req ConversionRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
+	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		respondWithError(w, "Invalid JSON request", http.StatusBadRequest)
+		respondWithError(w, requestDecodeError(err), http.StatusBadRequest)
+		return
+	}
+	if req.OutputFormat != "" && !containsString(outputFormats, req.OutputFormat) {
+		respondWithError(w, fmt.Sprintf("output_format must be one of: %s", strings.Join(outputFormats, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	response, err := s.processConversion(withAPIKeyID(r), req)
+	if req.OutputFormat == outputFormatGitHubAnnotations {
+		if response == nil {
+			respondWithAnnotations(w, errorStatus(err), req, req.OpenAPISpec, nil, err.Error())
+			return
+		}
+		status := http.StatusOK
+		var errorMessage string
+		if err != nil {
+			status = errorStatus(err)
+			errorMessage = err.Error()
+		}
+		respondWithAnnotations(w, status, req, response.spec, response.Warnings, errorMessage)
+		return
+	}
+	if err != nil && response == nil {
+		respondWithError(w, err.Error(), errorStatus(err))
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		// Some outputs failed to store; report which ones were written
+		w.WriteHeader(errorStatus(err))
+	}
+	json.NewEncoder(w).Encode(response.forVersion(version))
+}
+
+// processConversion stores the spec, converts it and stores the resulting MCP
+// config. It is shared by the single and batch conversion endpoints. When
+// only some outputs fail to store, the response listing every file is
+// returned together with the error.
+func (s *ConversionService) processConversion(ctx context.Context, req ConversionRequest) (response *ConversionResponse, err error) {
+	started := time.Now()
+	var meter *resourceMeter
+	if req.IncludeResourceStats {
+		meter = newResourceMeter()
+	}
+	defer func() {
+		s.stats.Timing("conversion.duration", time.Since(started))
+		if meter != nil {
+			stats := meter.stop()
+			if response != nil {
+				response.ResourceStats = stats
+			}
+		}
+		if err != nil {
+			s.stats.Incr("conversion.failure")
+		} else {
+			s.stats.Incr("conversion.success")
+		}
+		s.recordAudit(ctx, req, response, err, started)
+	}()
+
 	// Validate required fields
 	if req.OpenAPISpec == "" {
-		respondWithError(w, "openapi_spec is required", http.StatusBadRequest)
-		return
+		return nil, newAPIError(http.StatusBadRequest, "openapi_spec is required")
 	}
 
+	// Reject oversized specs before anything is written or parsed
+	if err := s.checkSpecSize("openapi_spec", req.OpenAPISpec); err != nil {
+		return nil, err
+	}
+
+	// Read the spec from cloud storage when given as a gs:// or s3:// URI
+	req.OpenAPISpec, err = s.resolveSpecSource(ctx, req.OpenAPISpec)
+	if err != nil {
+		return nil, err
+	}
+	sourceFormat, err := conversionSourceFormat(req)
+	if err != nil {
+		return nil, err
+	}
+	req.OpenAPISpec, req.SourceFormat, err = extractSpec(req.OpenAPISpec, sourceFormat)
+	if err != nil {
+		return nil, err
+	}
+	debugf(ctx, "spec received: %d bytes (%s)", len(req.OpenAPISpec), req.SourceFormat)
+
 	// Set defaults
 	if req.ServerName == "" {
 		req.ServerName = "openapi-server"
 	}
 	if req.Format == "" {
-		req.Format = "yaml"
+		req.Format = s.defaultFormat
+	}
+	if req.JSONIndent == nil {
+		indent := defaultIndent
+		req.JSONIndent = &indent
+	}
+	if strings.Trim(req.JSONIndent.String(), " \t") != "" {
+		return nil, newAPIError(http.StatusBadRequest, "json_indent must contain only spaces or tabs")
 	}
 
-	ctx := context.Background()
+	// Suffix the server name with the target environment
+	if req.Environment != "" {
+		if !containsString(s.allowedEnvironments, req.Environment) {
+			return nil, newAPIError(http.StatusBadRequest, "environment must be one of: %s", strings.Join(s.allowedEnvironments, ", "))
+		}
+		req.ServerName = fmt.Sprintf("%s-%s", req.ServerName, req.Environment)
+	}
+
+	if err := validateStorageHeaders(req.StorageHeaders); err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "%v", err)
+	}
+	storageOpts := storageOptions{CacheControl: req.CacheControl, Headers: req.StorageHeaders, NoOverwrite: req.NoOverwrite}
+
+	for _, format := range req.BundleFormats {
+		if !containsString(supportedFormats, format) {
+			return nil, newAPIError(http.StatusBadRequest, "unsupported bundle format %q", format)
+		}
+	}
+
+	if req.SplitBy != "" {
+		if !containsString(splitByModes, req.SplitBy) {
+			return nil, newAPIError(http.StatusBadRequest, "split_by must be one of: %s", strings.Join(splitByModes, ", "))
+		}
+		if !req.Bundle {
+			return nil, newAPIError(http.StatusBadRequest, "split_by requires bundle")
+		}
+	}
+
+	if req.MaxSchemaDepth < 0 {
+		return nil, newAPIError(http.StatusBadRequest, "max_schema_depth must not be negative")
+	}
+	if req.RequestMaxRedirects != nil && *req.RequestMaxRedirects < 0 {
+		return nil, newAPIError(http.StatusBadRequest, "request_max_redirects must not be negative")
+	}
+
+	if req.DescriptionTemplate != "" {
+		if _, err := template.New("description").Parse(req.DescriptionTemplate); err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "invalid description_template: %v", err)
+		}
+	}
+	if req.SecretPlaceholderTemplate != "" {
+		if _, err := template.New("secret_placeholder").Parse(req.SecretPlaceholderTemplate); err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "invalid secret_placeholder_template: %v", err)
+		}
+	}
+	if req.DescriptionSource != "" && !containsString(converter.DescriptionSources, req.DescriptionSource) {
+		return nil, newAPIError(http.StatusBadRequest, "description_source must be one of: %s", strings.Join(converter.DescriptionSources, ", "))
+	}
+
+	if _, err := regexp.Compile(req.IncludePathRegex); err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "invalid include_path_regex: %v", err)
+	}
+	if _, err := regexp.Compile(req.ExcludePathRegex); err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "invalid exclude_path_regex: %v", err)
+	}
+	if _, err := regexp.Compile(req.OperationIDStripPrefix); err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "invalid operation_id_strip_prefix: %v", err)
+	}
+
+	if req.OutputExtension != "" {
+		if req.OutputExtension, err = normalizeExtension(req.OutputExtension); err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "invalid output_extension: %v", err)
+		}
+	}
+	if req.OperationOrder != "" && !containsString(converter.OperationOrders, req.OperationOrder) {
+		return nil, newAPIError(http.StatusBadRequest, "operation_order must be one of: %s", strings.Join(converter.OperationOrders, ", "))
+	}
+	if req.PathPrefix != "" && !strings.HasPrefix(req.PathPrefix, "/") {
+		return nil, newAPIError(http.StatusBadRequest, "path_prefix must start with /")
+	}
+	if req.StripPathPrefix != "" && !strings.HasPrefix(req.StripPathPrefix, "/") {
+		return nil, newAPIError(http.StatusBadRequest, "strip_path_prefix must start with /")
+	}
+	if req.DefaultRequestContentType != "" {
+		if err := validateContentType(req.DefaultRequestContentType); err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "invalid default_request_content_type: %v", err)
+		}
+	}
+	if req.ParamNameCase != "" && !containsString(converter.ParamNameCases, req.ParamNameCase) {
+		return nil, newAPIError(http.StatusBadRequest, "param_name_case must be one of: %s", strings.Join(converter.ParamNameCases, ", "))
+	}
+	if req.RefStrategy != "" && !containsString(converter.RefStrategies, req.RefStrategy) {
+		return nil, newAPIError(http.StatusBadRequest, "ref_strategy must be one of: %s", strings.Join(converter.RefStrategies, ", "))
+	}
+	if req.DefaultArrayStyle != "" && !containsString(converter.ArrayStyles, req.DefaultArrayStyle) {
+		return nil, newAPIError(http.StatusBadRequest, "default_array_style must be one of: %s", strings.Join(converter.ArrayStyles, ", "))
+	}
+	if req.NullHandling != "" && !containsString(converter.NullHandlings, req.NullHandling) {
+		return nil, newAPIError(http.StatusBadRequest, "null_handling must be one of: %s", strings.Join(converter.NullHandlings, ", "))
+	}
+	if req.EmptyParamHandling != "" && !containsString(converter.EmptyParamHandlings, req.EmptyParamHandling) {
+		return nil, newAPIError(http.StatusBadRequest, "empty_param_handling must be one of: %s", strings.Join(converter.EmptyParamHandlings, ", "))
+	}
+	if req.MissingBodySchema != "" && !containsString(converter.MissingBodySchemas, req.MissingBodySchema) {
+		return nil, newAPIError(http.StatusBadRequest, "missing_body_schema must be one of: %s", strings.Join(converter.MissingBodySchemas, ", "))
+	}
+	if len(req.CoercionPolicy) > 0 {
+		if !req.CoerceTypes {
+			return nil, newAPIError(http.StatusBadRequest, "coercion_policy requires coerce_types")
+		}
+		if err := validateCoercionPolicy(req.CoercionPolicy); err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "coercion_policy: %v", err)
+		}
+	}
+
+	if err := validateAllowedHosts(req); err != nil {
+		return nil, err
+	}
+	if _, err := converter.ResolvePipelineOrder(req.PipelineOrder); err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "pipeline_order: %v", err)
+	}
+	if req.MaxEnumValues < 0 {
+		return nil, newAPIError(http.StatusBadRequest, "max_enum_values must not be negative")
+	}
+	if req.EnumOverflow != "" && !containsString(converter.EnumOverflows, req.EnumOverflow) {
+		return nil, newAPIError(http.StatusBadRequest, "enum_overflow must be one of: %s", strings.Join(converter.EnumOverflows, ", "))
+	}
+	if req.EnumOverflow != "" && req.MaxEnumValues == 0 {
+		return nil, newAPIError(http.StatusBadRequest, "enum_overflow requires max_enum_values")
+	}
+	if req.ParameterConflictMode != "" && !containsString(converter.ParameterConflictModes, req.ParameterConflictMode) {
+		return nil, newAPIError(http.StatusBadRequest, "parameter_conflict_mode must be one of: %s", strings.Join(converter.ParameterConflictModes, ", "))
+	}
+	if req.FilterDescriptionLanguage != "" && !containsString(converter.DescriptionLanguages, req.FilterDescriptionLanguage) {
+		return nil, newAPIError(http.StatusBadRequest, "filter_description_language must be one of: %s", strings.Join(converter.DescriptionLanguages, ", "))
+	}
+	if req.DescriptionLanguageMode != "" && !containsString(converter.DescriptionLanguageModes, req.DescriptionLanguageMode) {
+		return nil, newAPIError(http.StatusBadRequest, "description_language_mode must be one of: %s", strings.Join(converter.DescriptionLanguageModes, ", "))
+	}
+	if req.DescriptionLanguageMode != "" && req.FilterDescriptionLanguage == "" {
+		return nil, newAPIError(http.StatusBadRequest, "description_language_mode requires filter_description_language")
+	}
+	for _, extension := range req.ExtensionPassthrough {
+		if !strings.HasPrefix(extension, "x-") {
+			return nil, newAPIError(http.StatusBadRequest, "extension_passthrough: %q is not a vendor extension, it must start with x-", extension)
+		}
+	}
+	if err := converter.ValidateResponseCodes(req.ResponseCodesInDescription); err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "response_codes_in_description: %v", err)
+	}
+
+	if err := applyConverterOptions(&models.ConvertOptions{}, req.ConverterOptions); err != nil {
+		return nil, err
+	}
+	if req.PublishPackage != nil {
+		if err := s.validatePublishPackage(req.PublishPackage); err != nil {
+			return nil, err
+		}
+	}
+	if req.VerifyServers && s.servers == nil {
+		return nil, newAPIError(http.StatusBadRequest, "verify_servers is disabled, set VERIFY_SERVER_HOSTS to enable it")
+	}
+
+	redactPatterns, err := compileRedactPatterns(req.RedactPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	// Redact the copies of the spec that are stored; conversion uses the original
+	storedSpec, redactions, err := redactSpec(req.OpenAPISpec, redactPatterns)
+	if err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "%v", err)
+	}
+
+	// Return the result of an identical earlier conversion, if cached
+	cacheKey := s.results.key(req)
+	if cached, ok := s.results.get(ctx, cacheKey, s.objectExists); ok {
+		debugf(ctx, "returning cached conversion %s", cacheKey)
+		response = cached.Response
+		response.Cached = true
+		response.mcpConfigObject = cached.MCPConfigObject
+		response.openAPIObject = cached.OpenAPIObject
+		response.spec = req.OpenAPISpec
+		return response, nil
+	}
 
 	// Generate unique filenames with timestamp
 	timestamp := time.Now().Format("20060102-150405")
 	openAPIFileName := fmt.Sprintf("openapi/%s-%s.yaml", req.ServerName, timestamp)
-	mcpConfigFileName := fmt.Sprintf("mcp-configs/%s-%s.%s", req.ServerName, timestamp, req.Format)
+	mcpConfigFileName := fmt.Sprintf("mcp-configs/%s-%s.%s", req.ServerName, timestamp, s.configExtension(req))
 
-	// Save OpenAPI spec to Firebase Storage
-	openAPIFileURL, err := s.saveToStorage(ctx, openAPIFileName, []byte(req.OpenAPISpec), "application/x-yaml")
-	if err != nil {
-		respondWithError(w, fmt.Sprintf("Failed to save OpenAPI spec: %v", err), http.StatusInternalServerError)
-		return
-	}
+	// Save OpenAPI spec to Firebase Storage. Failed writes are recorded and
+	// reported together once every file has been attempted.
+	writes := &fileWrites{}
+	openAPIFileURL, err := s.saveToStorage(ctx, openAPIFileName, []byte(storedSpec), "application/x-yaml", storageOpts)
+	openAPIFileURL = writes.record("openapi_spec", openAPIFileURL, err)
 
 	// Convert the specification
-	mcpConfig, err := convertOpenAPIToMCP(req.OpenAPISpec, req.ServerName, req.ToolPrefix, req.Format, req.Validate, req.TemplateConfig)
+	output, err := convertOpenAPIToMCP(req, s.toolCache)
 	if err != nil {
-		respondWithError(w, fmt.Sprintf("Conversion failed: %v", err), http.StatusBadRequest)
-		return
+		status := http.StatusBadRequest
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			status = apiErr.status
+		}
+		debugf(ctx, "conversion failed: %v", err)
+		return nil, newAPIError(status, "Conversion failed: %v", err)
+	}
+	debugf(ctx, "parsed spec in %dms and converted %d tool(s) in %dms", output.Timing.ParseMs, output.ToolCount, output.Timing.ConvertMs)
+	for _, skipped := range output.Skipped {
+		debugf(ctx, "skipped %s %s (%s): %s", strings.ToUpper(skipped.Method), skipped.Path, skipped.Category, skipped.Reason)
+	}
+	for _, warning := range output.Warnings {
+		debugf(ctx, "warning: %s", warning)
+	}
+	if redactions > 0 {
+		output.Warnings = append(output.Warnings, fmt.Sprintf("redacted %d value(s) from the stored spec", redactions))
+	}
+	if req.VerifyServers {
+		output.Warnings = append(output.Warnings, s.servers.verify(ctx, output.ServerURLs)...)
+	}
+
+	// Resolve the package coordinates before anything else is stored, so a
+	// spec without a usable version is rejected early
+	var packageName, packageVersion string
+	if req.PublishPackage != nil {
+		packageName, packageVersion, err = packageCoordinates(req.PublishPackage, req.ServerName, output.SpecVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Compare with the previous conversion before the new config is stored
+	var changelog *Changelog
+	if req.GenerateChangelog {
+		changelog, err = s.buildChangelog(ctx, req.ServerName, s.configExtensions(req), output.MCPConfig)
+		if err != nil {
+			return nil, newAPIError(http.StatusInternalServerError, "Failed to build changelog: %v", err)
+		}
 	}
 
 	// Save MCP config to Firebase Storage
@@ -156,44 +1161,166 @@ func (s *ConversionService) handleConvert(w http.ResponseWriter, r *http.Request
 		contentType = "application/x-yaml"
 	}
 
-	mcpConfigFileURL, err := s.saveToStorage(ctx, mcpConfigFileName, []byte(mcpConfig), contentType)
-	if err != nil {
-		respondWithError(w, fmt.Sprintf("Failed to save MCP config: %v", err), http.StatusInternalServerError)
-		return
+	mcpConfigFileURL, err := s.saveToStorage(ctx, mcpConfigFileName, []byte(output.Config), contentType, storageOpts)
+	mcpConfigFileURL = writes.record("mcp_config", mcpConfigFileURL, err)
+
+	response = &ConversionResponse{
+		Success:          true,
+		MCPConfig:        output.Config,
+		Format:           req.Format,
+		ServerName:       req.ServerName,
+		OpenAPIFileURL:   openAPIFileURL,
+		MCPConfigFileURL: mcpConfigFileURL,
+		Warnings:         output.Warnings,
+		DryRun:           s.storageDryRun,
+		ToolCache:        output.ToolCache,
+		EffectiveOptions: s.newEffectiveOptions(req, output.Options),
+		Pipeline:         output.Pipeline,
+		mcpConfigObject:  mcpConfigFileName,
+		openAPIObject:    openAPIFileName,
+		spec:             req.OpenAPISpec,
 	}
+	response.DescriptionLanguages = newDescriptionLanguages(output.DescriptionLanguages)
 
-	// Return successful response
-	response := ConversionResponse{
-		Success:           true,
-		MCPConfig:         mcpConfig,
-		Format:            req.Format,
-		ServerName:        req.ServerName,
-		OpenAPIFileURL:    openAPIFileURL,
-		MCPConfigFileURL:  mcpConfigFileURL,
+	if s.signer != nil {
+		signature, err := s.storeSignature(ctx, mcpConfigFileName, []byte(output.Config), storageOpts)
+		signature.URL = writes.record("signature", signature.URL, err)
+		response.Signature = signature
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	if req.Bundle {
+		bundleReq := req
+		bundleReq.OpenAPISpec = storedSpec
+		bundleURL, err := s.storeBundle(ctx, bundleReq, output, timestamp, storageOpts)
+		response.BundleURL = writes.record("bundle", bundleURL, err)
+	}
+
+	if changelog != nil {
+		response.Changelog = changelog
+		changelogURL, err := s.storeChangelog(ctx, req.ServerName, changelog, timestamp, storageOpts)
+		response.ChangelogURL = writes.record("changelog", changelogURL, err)
+	}
+
+	if output.Coverage != nil {
+		response.Coverage = output.Coverage
+		if req.StoreCoverageReport {
+			coverageURL, err := s.storeCoverageReport(ctx, req.ServerName, output.Coverage, timestamp, storageOpts)
+			response.CoverageURL = writes.record("coverage_report", coverageURL, err)
+		}
+	}
+
+	if req.StoreDiagnostics {
+		diagnosticsURL, err := s.storeDiagnostics(ctx, req, response, output, timestamp, started)
+		response.DiagnosticsURL = writes.record("diagnostics", diagnosticsURL, err)
+	}
+
+	response.Files = writes.files
+	if err := writes.err(); err != nil {
+		// The response still lists what was stored, so it is returned with the error
+		response.Success = false
+		response.Error = err.Error()
+		return response, err
+	}
+
+	if req.PublishPackage != nil {
+		if s.storageDryRun {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("dry run: package %s@%s was not published", packageName, packageVersion))
+			return response, nil
+		}
+		configFile := "mcp-config." + s.configExtension(req)
+		published, err := publishPackage(ctx, req.PublishPackage, packageName, packageVersion, output.SpecTitle, configFile, []byte(output.Config))
+		response.Package = published
+		if err != nil {
+			debugf(ctx, "publishing %s@%s failed: %v", packageName, packageVersion, err)
+			// The config is stored either way, so the response is returned with the error
+			response.Success = false
+			response.Error = err.Error()
+			return response, err
+		}
+	}
+	s.results.set(cacheKey, response)
+	return response, nil
 }
 
-func (s *ConversionService) saveToStorage(ctx context.Context, fileName string, data []byte, contentType string) (string, error) {
+// objectExists reports whether the bucket has an object. In dry-run mode
+// nothing is stored, so every object counts as existing.
+func (s *ConversionService) objectExists(ctx context.Context, name string) (bool, error) {
+	if s.storageDryRun {
+		return true, nil
+	}
+	_, err := s.storageClient.Bucket(s.bucketName).Object(name).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *ConversionService) saveToStorage(ctx context.Context, fileName string, data []byte, contentType string, opts storageOptions) (string, error) {
+	started := time.Now()
+	defer func() {
+		s.stats.Timing("storage.write.duration", time.Since(started))
+	}()
+
+	cacheControl := s.cacheControl
+	if opts.CacheControl != "" {
+		cacheControl = opts.CacheControl
+	}
+	metadata := map[string]string{}
+	for name, value := range opts.Headers {
+		metadata[name] = value
+	}
+	metadata["uploaded_at"] = time.Now().UTC().Format(time.RFC3339)
+	debugf(ctx, "storage write %s: %d bytes, content type %s", logObjectName(fileName), len(data), contentType)
+
+	// In dry-run mode only log what would have been written
+	if s.storageDryRun {
+		log.Printf("Dry run: would write %s (content type %s, %d bytes, cache control %q, metadata %v)", logObjectName(fileName), contentType, len(data), cacheControl, metadata)
+		return fmt.Sprintf("dry-run://%s/%s", s.bucketName, fileName), nil
+	}
+
+	// Wait for a write slot when storage writes are rate limited
+	if err := s.writeLimiter.wait(ctx); err != nil {
+		s.stats.Incr("storage.error")
+		return "", err
+	}
+
+	// Reject the write if it would exceed the prefix quota. The reservation
+	// is released when the write fails.
+	bucket := s.storageClient.Bucket(s.bucketName)
+	reservation, err := s.quota.reserve(ctx, bucket, fileName, int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
 	// Create object handle
-	obj := s.storageClient.Bucket(s.bucketName).Object(fileName)
+	obj := bucket.Object(fileName)
+	publicURL := fmt.Sprintf("https://storage.googleapis.com/%s/%s", s.bucketName, fileName)
+	if opts.NoOverwrite {
+		obj = obj.If(storage.Conditions{DoesNotExist: true})
+	}
 
 	// Create writer
 	writer := obj.NewWriter(ctx)
 	writer.ContentType = contentType
-	writer.Metadata = map[string]string{
-		"uploaded_at": time.Now().UTC().Format(time.RFC3339),
-	}
+	writer.CacheControl = cacheControl
+	writer.Metadata = metadata
 
 	// Write data
 	if _, err := writer.Write(data); err != nil {
+		reservation.release()
+		s.stats.Incr("storage.error")
 		return "", fmt.Errorf("failed to write to storage: %w", err)
 	}
 
 	// Close writer
 	if err := writer.Close(); err != nil {
+		reservation.release()
+		var apiErr *googleapi.Error
+		if opts.NoOverwrite && errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+			s.stats.Incr("storage.conflict")
+			return "", &objectExistsError{url: publicURL}
+		}
+		s.stats.Incr("storage.error")
 		return "", fmt.Errorf("failed to close storage writer: %w", err)
 	}
 
@@ -203,9 +1330,8 @@ func (s *ConversionService) saveToStorage(ctx context.Context, fileName string,
 		// Continue anyway, file is still accessible with proper authentication
 	}
 
-	// Generate public URL
-	publicURL := fmt.Sprintf("https://storage.googleapis.com/%s/%s", s.bucketName, fileName)
-	
+	debugf(ctx, "stored %s in %s", logObjectName(fileName), time.Since(started).Round(time.Millisecond))
+
 	return publicURL, nil
 }
 
@@ -214,13 +1340,13 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Authorization")
-	
+
 	// Handle preflight requests
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
-	
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
 }
@@ -235,82 +1361,288 @@ func respondWithError(w http.ResponseWriter, message string, statusCode int) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func convertOpenAPIToMCP(openAPIContent, serverName, toolPrefix, format string, validate bool, templateConfig string) (string, error) {
+// conversionOutput is the marshalled MCP config together with what was
+// learned while producing it.
+type conversionOutput struct {
+	Config    string
+	MCPConfig *models.MCPConfig
+	Warnings  []string
+	Skipped   []models.SkippedOperation
+	ToolCount int
+	Timing    ConversionTiming
+	Coverage  *CoverageReport
+
+	// ServerURLs are the spec's base server URLs, set for verify_servers
+	ServerURLs []string
+	// ToolCache counts the tool cache hits and misses, nil without a cache
+	ToolCache *ToolCacheStats
+	// Options are the converter options the conversion ran with
+	Options models.ConvertOptions
+	// SpecTitle and SpecVersion are the spec's info.title and info.version
+	SpecTitle   string
+	SpecVersion string
+	// Pipeline lists the post-conversion steps the converter applied
+	Pipeline []string
+	// DescriptionLanguages are the languages detected with
+	// filter_description_language
+	DescriptionLanguages []models.OperationLanguage
+}
+
+func convertOpenAPIToMCP(req ConversionRequest, cache *toolCache) (*conversionOutput, error) {
 	// Create a temporary file for the OpenAPI content
-	tmpFile, err := os.CreateTemp("", "openapi-*.yaml")
+	tmpFile, err := os.CreateTemp(tempDir, "openapi-*.yaml")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temporary file: %w", err)
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
 	}
 	defer os.Remove(tmpFile.Name())
 	defer tmpFile.Close()
 
 	// Write OpenAPI content to temporary file
-	_, err = tmpFile.WriteString(openAPIContent)
+	_, err = tmpFile.WriteString(req.OpenAPISpec)
 	if err != nil {
-		return "", fmt.Errorf("failed to write temporary file: %w", err)
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
 	}
 	tmpFile.Close()
 
 	// Create parser and set validation option
 	p := parser.NewParser()
-	p.SetValidation(validate)
+	p.SetValidation(req.Validate)
 
 	// Parse the OpenAPI specification
+	parseStarted := time.Now()
 	err = p.ParseFile(tmpFile.Name())
 	if err != nil {
-		return "", fmt.Errorf("failed to parse OpenAPI specification: %w", err)
+		return nil, fmt.Errorf("failed to parse OpenAPI specification: %w", err)
 	}
+	parseDuration := time.Since(parseStarted)
 
 	// Handle template if provided
 	var templatePath string
-	if templateConfig != "" {
-		tmpTemplate, err := os.CreateTemp("", "template-*.yaml")
+	if req.TemplateConfig != "" {
+		tmpTemplate, err := os.CreateTemp(tempDir, "template-*.yaml")
 		if err != nil {
-			return "", fmt.Errorf("failed to create template file: %w", err)
+			return nil, fmt.Errorf("failed to create template file: %w", err)
 		}
 		defer os.Remove(tmpTemplate.Name())
 		defer tmpTemplate.Close()
 
-		_, err = tmpTemplate.WriteString(templateConfig)
+		_, err = tmpTemplate.WriteString(req.TemplateConfig)
 		if err != nil {
-			return "", fmt.Errorf("failed to write template file: %w", err)
+			return nil, fmt.Errorf("failed to write template file: %w", err)
 		}
 		tmpTemplate.Close()
 		templatePath = tmpTemplate.Name()
 	}
 
-	// Create converter
-	c := converter.NewConverter(p, models.ConvertOptions{
-		ServerName:     serverName,
-		ToolNamePrefix: toolPrefix,
+	var descriptionTemplate *template.Template
+	if req.DescriptionTemplate != "" {
+		descriptionTemplate, err = template.New("description").Parse(req.DescriptionTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid description_template: %w", err)
+		}
+	}
+
+	var secretPlaceholderTemplate *template.Template
+	if req.SecretPlaceholderTemplate != "" {
+		secretPlaceholderTemplate, err = template.New("secret_placeholder").Parse(req.SecretPlaceholderTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret_placeholder_template: %w", err)
+		}
+	}
+
+	var includePathRegex, excludePathRegex *regexp.Regexp
+	if req.IncludePathRegex != "" {
+		includePathRegex, err = regexp.Compile(req.IncludePathRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include_path_regex: %w", err)
+		}
+	}
+	if req.ExcludePathRegex != "" {
+		excludePathRegex, err = regexp.Compile(req.ExcludePathRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude_path_regex: %w", err)
+		}
+	}
+	var operationIDStripPrefix *regexp.Regexp
+	if req.OperationIDStripPrefix != "" {
+		operationIDStripPrefix, err = regexp.Compile(req.OperationIDStripPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operation_id_strip_prefix: %w", err)
+		}
+	}
+	options := models.ConvertOptions{
+		ServerName:     req.ServerName,
+		ToolNamePrefix: req.ToolPrefix,
 		TemplatePath:   templatePath,
-	})
+
+		RequireSuccessResponse: req.RequireSuccessResponse,
+		DescriptionTemplate:    descriptionTemplate,
+		DescriptionSource:      req.DescriptionSource,
+		PreferredRequestMedia:  req.PreferredRequestMedia,
+		MaxSchemaDepth:         req.MaxSchemaDepth,
+		PropagateTags:          req.PropagateTags || req.SplitBy == splitByTag,
+		StrictOperationIDs:     req.StrictOperationIDs,
+		StrictNames:            req.StrictNames,
+		IncludePathRegex:       includePathRegex,
+		ExcludePathRegex:       excludePathRegex,
+		IncludePathGlobs:       req.IncludePathGlobs,
+		ExcludePathGlobs:       req.ExcludePathGlobs,
+		AnnotateAuth:           req.AnnotateAuth,
+		AnnotateSource:         req.AnnotateSource,
+		StripExamples:          req.StripExamples,
+
+		PromoteExamplesToDefaults: req.PromoteExamplesToDefaults,
+		SynthesizeExamples:        req.SynthesizeExamples,
+		DefaultRequestContentType: req.DefaultRequestContentType,
+		PathPrefix:                req.PathPrefix,
+		StripPathPrefix:           req.StripPathPrefix,
+		OperationOrder:            req.OperationOrder,
+		ForceRequired:             req.ForceRequired,
+		InitiallyDisabled:         req.InitiallyDisabled,
+		ResponseSizeHints:         req.ResponseSizeHint,
+		EmitSpecInfo:              req.EmitSpecInfo,
+		ParamNameCase:             req.ParamNameCase,
+		NullHandling:              req.NullHandling,
+		EmptyParamHandling:        req.EmptyParamHandling,
+		CoerceTypes:               coercionPolicies(req),
+		DefaultArrayStyle:         req.DefaultArrayStyle,
+		RefStrategy:               req.RefStrategy,
+		FormatMapping:             req.FormatMapping,
+		IncludeEnumDescriptions:   req.IncludeEnumDescriptions,
+		IncludeResponseHeaders:    req.IncludeResponseHeaders,
+		StrictNumericConstraints:  req.StrictNumericConstraints,
+		BodyWrapper:               req.BodyWrapper,
+		PipelineOrder:             req.PipelineOrder,
+		SecretPlaceholderTemplate: secretPlaceholderTemplate,
+		OperationIDStripPrefix:    operationIDStripPrefix,
+		SafeMode:                  req.SafeMode,
+		MissingBodySchema:         req.MissingBodySchema,
+		MaxEnumValues:             req.MaxEnumValues,
+		EnumOverflow:              req.EnumOverflow,
+		ExtensionPassthrough:      req.ExtensionPassthrough,
+		ParameterConflictMode:     req.ParameterConflictMode,
+		FilterDescriptionLanguage: req.FilterDescriptionLanguage,
+		DescriptionLanguageMode:   req.DescriptionLanguageMode,
+		Transport: models.TransportOptions{
+			HTTP2:        req.RequestHTTP2,
+			KeepAlive:    req.RequestKeepalive,
+			MaxRedirects: req.RequestMaxRedirects,
+		},
+	}
+	// Descriptions summarize the success responses unless asked otherwise
+	options.ResponseCodesInDescription = req.ResponseCodesInDescription
+	if options.ResponseCodesInDescription == nil {
+		options.ResponseCodesInDescription = []string{converter.ResponseCodesSuccessOnly}
+	}
+	if err := applyConverterOptions(&options, req.ConverterOptions); err != nil {
+		return nil, err
+	}
+	// A nil *toolCache would be a non-nil models.ToolCache
+	if cache != nil {
+		options.ToolCache = cache
+	}
+
+	// Create converter
+	c := converter.NewConverter(p, options)
 
 	// Convert the OpenAPI specification to an MCP configuration
+	convertStarted := time.Now()
 	config, err := c.Convert()
 	if err != nil {
-		return "", fmt.Errorf("failed to convert OpenAPI specification: %w", err)
+		return nil, fmt.Errorf("failed to convert OpenAPI specification: %w", err)
 	}
+	convertDuration := time.Since(convertStarted)
 
-	// Marshal the configuration based on the requested format
-	var data []byte
-	if format == "json" {
-		data, err = json.MarshalIndent(config, "", "  ")
-	} else {
-		var buffer bytes.Buffer
-		encoder := yaml.NewEncoder(&buffer)
-		encoder.SetIndent(2)
-		
-		if err := encoder.Encode(config); err != nil {
-			return "", fmt.Errorf("failed to encode YAML: %w", err)
+	// Reject configs whose tools reference each other in a loop
+	if req.DetectCycles {
+		if cycle := findToolCycle(config); cycle != nil {
+			return nil, newAPIError(http.StatusUnprocessableEntity, "circular tool dependency: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	// Reject configs whose tools call hosts outside the allowlist
+	var hostWarnings []string
+	if len(req.AllowedHosts) > 0 {
+		violations, unknown := checkAllowedHosts(config, req.AllowedHosts)
+		if len(unknown) > 0 && req.TemplatedHosts == templatedHostsSkip {
+			hostWarnings = append(hostWarnings, fmt.Sprintf("allowed_hosts: skipped %d tool(s) with templated or missing hosts: %s", len(unknown), strings.Join(unknown, ", ")))
+		} else {
+			violations = append(violations, unknown...)
+		}
+		if len(violations) > 0 {
+			return nil, newAPIError(http.StatusUnprocessableEntity, "tools call hosts outside allowed_hosts: %s", strings.Join(violations, ", "))
 		}
-		data = buffer.Bytes()
 	}
+
+	// Marshal the configuration based on the requested format
+	data, err := marshalConfig(config, req.Format, req.JSONIndent.String())
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal MCP configuration: %w", err)
+		return nil, err
+	}
+
+	var coverage *CoverageReport
+	if req.CoverageReport || req.StoreCoverageReport {
+		coverage = buildCoverageReport(p.GetPaths(), c.GetSkippedOperations())
+	}
+
+	var cacheStats *ToolCacheStats
+	if cache != nil {
+		hits, misses := c.GetCacheStats()
+		cacheStats = &ToolCacheStats{Hits: hits, Misses: misses}
+	}
+
+	var serverURLs []string
+	if req.VerifyServers {
+		serverURLs = specServerURLs(p.GetDocument())
+	}
+
+	output := &conversionOutput{
+		Config:    string(data),
+		MCPConfig: config,
+		Warnings:  c.GetWarnings(),
+		Skipped:   c.GetSkippedOperations(),
+		ToolCount: len(config.Tools),
+		Timing: ConversionTiming{
+			ParseMs:   parseDuration.Milliseconds(),
+			ConvertMs: convertDuration.Milliseconds(),
+		},
+		Coverage:   coverage,
+		ServerURLs: serverURLs,
+		ToolCache:  cacheStats,
+		Options:    options,
+		Pipeline:   c.GetPipeline(),
+	}
+	output.Warnings = append(output.Warnings, hostWarnings...)
+	output.DescriptionLanguages = c.GetDescriptionLanguages()
+	if info := p.GetInfo(); info != nil {
+		output.SpecTitle = info.Title
+		output.SpecVersion = info.Version
+	}
+	return output, nil
+}
+
+// marshalConfig encodes an MCP configuration as JSON or YAML. JSON without
+// an indentation is compact.
+func marshalConfig(config *models.MCPConfig, format, jsonIndent string) ([]byte, error) {
+	if format == "json" {
+		data, err := json.Marshal(config)
+		if jsonIndent != "" {
+			data, err = json.MarshalIndent(config, "", jsonIndent)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal MCP configuration: %w", err)
+		}
+		return data, nil
 	}
 
-	return string(data), nil
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(config); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return buffer.Bytes(), nil
 }
 
 func (s *ConversionService) handleUpload(w http.ResponseWriter, r *http.Request) {
@@ -333,8 +1665,25 @@ func (s *ConversionService) handleUpload(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Detect file type
-	fileType, err := detectFileType(req.FileContent)
+	// Reject oversized files before anything is parsed or stored
+	var apiErr *apiError
+	if err := s.checkSpecSize("file_content", req.FileContent); errors.As(err, &apiErr) {
+		respondWithUploadError(w, apiErr.Error(), apiErr.status)
+		return
+	}
+	if req.OutputExtension != "" {
+		if req.OutputExtension, err = normalizeExtension(req.OutputExtension); err != nil {
+			respondWithUploadError(w, fmt.Sprintf("invalid output_extension: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Detect file type, unless source_type forces it
+	fileType, err := uploadFileType(req.FileContent, req.SourceType)
+	if errors.As(err, &apiErr) {
+		respondWithUploadError(w, apiErr.Error(), apiErr.status)
+		return
+	}
 	if err != nil {
 		respondWithUploadError(w, fmt.Sprintf("File validation failed: %v", err), http.StatusBadRequest)
 		return
@@ -343,7 +1692,7 @@ func (s *ConversionService) handleUpload(w http.ResponseWriter, r *http.Request)
 	// Set defaults based on file type
 	if req.Format == "" {
 		if fileType == "openapi" {
-			req.Format = "yaml"
+			req.Format = s.defaultFormat
 		} else {
 			// Detect format from content for MCP files
 			req.Format = detectMCPFormat(req.FileContent)
@@ -354,12 +1703,17 @@ func (s *ConversionService) handleUpload(w http.ResponseWriter, r *http.Request)
 		req.FileName = fmt.Sprintf("uploaded-file-%s", time.Now().Format("20060102-150405"))
 	}
 
-	ctx := context.Background()
+	if err := validateStorageHeaders(req.StorageHeaders); err != nil {
+		respondWithUploadError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
 
 	// Generate filename based on file type
 	var fileName string
 	var contentType string
-	
+
 	if fileType == "openapi" {
 		fileName = fmt.Sprintf("openapi/%s.%s", req.FileName, req.Format)
 		if req.Format == "json" {
@@ -368,7 +1722,8 @@ func (s *ConversionService) handleUpload(w http.ResponseWriter, r *http.Request)
 			contentType = "application/x-yaml"
 		}
 	} else {
-		fileName = fmt.Sprintf("mcp-configs/%s.%s", req.FileName, req.Format)
+		extension := s.configExtension(ConversionRequest{Format: req.Format, OutputExtension: req.OutputExtension})
+		fileName = fmt.Sprintf("mcp-configs/%s.%s", req.FileName, extension)
 		if req.Format == "json" {
 			contentType = "application/json"
 		} else {
@@ -377,9 +1732,13 @@ func (s *ConversionService) handleUpload(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Save file to Firebase Storage
-	publicURL, err := s.saveToStorage(ctx, fileName, []byte(req.FileContent), contentType)
+	publicURL, err := s.saveToStorage(ctx, fileName, []byte(req.FileContent), contentType, storageOptions{
+		CacheControl: req.CacheControl,
+		Headers:      req.StorageHeaders,
+		NoOverwrite:  req.NoOverwrite,
+	})
 	if err != nil {
-		respondWithUploadError(w, fmt.Sprintf("Failed to save file: %v", err), http.StatusInternalServerError)
+		respondWithUploadError(w, fmt.Sprintf("Failed to save file: %v", err), storageErrorStatus(err))
 		return
 	}
 
@@ -389,6 +1748,7 @@ func (s *ConversionService) handleUpload(w http.ResponseWriter, r *http.Request)
 		FileType:  fileType,
 		PublicURL: publicURL,
 		FileName:  fileName,
+		DryRun:    s.storageDryRun,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -410,7 +1770,7 @@ func detectFileType(content string) (string, error) {
 				return "openapi", nil
 			}
 		}
-		
+
 		// Check for MCP config indicators
 		if server, exists := jsonData["server"]; exists {
 			if serverMap, ok := server.(map[string]interface{}); ok {
@@ -424,7 +1784,7 @@ func detectFileType(content string) (string, error) {
 				return "mcp_config", nil
 			}
 		}
-		
+
 		return "", fmt.Errorf("unrecognized JSON file format")
 	}
 
@@ -442,7 +1802,7 @@ func detectFileType(content string) (string, error) {
 				return "openapi", nil
 			}
 		}
-		
+
 		// Check for MCP config indicators
 		if server, exists := yamlData["server"]; exists {
 			if serverMap, ok := server.(map[string]interface{}); ok {
@@ -456,7 +1816,7 @@ func detectFileType(content string) (string, error) {
 				return "mcp_config", nil
 			}
 		}
-		
+
 		return "", fmt.Errorf("unrecognized YAML file format")
 	}
 
@@ -469,7 +1829,7 @@ func detectMCPFormat(content string) string {
 	if err := json.Unmarshal([]byte(content), &jsonData); err == nil {
 		return "json"
 	}
-	
+
 	// Default to YAML
 	return "yaml"
 }
@@ -482,4 +1842,25 @@ func respondWithUploadError(w http.ResponseWriter, message string, statusCode in
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
+
+// splitList splits a comma-separated list, trimming blanks and dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// containsString checks if a string slice contains a string
+func containsString(slice []string, str string) bool {
+	for _, s := range slice {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
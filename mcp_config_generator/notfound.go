@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// endpointInfo describes a public endpoint in the 404 response.
+type endpointInfo struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// publicEndpoints are the endpoints advertised to clients that request an
+// unknown path. Internal and admin endpoints are deliberately left out.
+var publicEndpoints = []endpointInfo{
+	{Method: http.MethodPost, Path: "/convert", Description: "Convert an OpenAPI spec to an MCP config"},
+	{Method: http.MethodPost, Path: "/convert/batch", Description: "Convert several specs in one request"},
+	{Method: http.MethodPost, Path: "/upload", Description: "Store an OpenAPI spec or MCP config"},
+	{Method: http.MethodPost, Path: "/tool-preview", Description: "Preview the tool generated for a single operation"},
+	{Method: http.MethodGet, Path: "/health", Description: "Health check"},
+	{Method: http.MethodGet, Path: "/health/live", Description: "Liveness check"},
+	{Method: http.MethodGet, Path: "/health/ready", Description: "Readiness check"},
+}
+
+// handleNotFound is registered on "/" so it receives every request no other
+// route matches, and answers with a JSON 404 listing the public endpoints.
+func handleNotFound(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   false,
+		"error":     "no endpoint at " + r.URL.Path,
+		"hint":      `POST a JSON body such as {"openapi_spec": "..."} to /convert to convert a spec`,
+		"endpoints": publicEndpoints,
+	})
+}
@@ -66,6 +66,9 @@ func main() {
 		fmt.Printf("Error converting OpenAPI specification: %v\n", err)
 		os.Exit(1)
 	}
+	for _, warning := range c.GetWarnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
 
 	// Create the output directory if it doesn't exist
 	outputDir := filepath.Dir(*outputFile)
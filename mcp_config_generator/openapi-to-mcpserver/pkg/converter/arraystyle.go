@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// Array serializations supported by ConvertOptions.DefaultArrayStyle and
+// written to Arg.ArrayStyle
+const (
+	// ArrayStyleCSV sends ?ids=1,2,3
+	ArrayStyleCSV = "csv"
+	// ArrayStyleMulti sends ?ids=1&ids=2&ids=3
+	ArrayStyleMulti = "multi"
+	// ArrayStylePipe sends ?ids=1|2|3
+	ArrayStylePipe = "pipe"
+	// ArrayStyleSpace sends ?ids=1%202%203
+	ArrayStyleSpace = "space"
+)
+
+// ArrayStyles lists the supported array serializations
+var ArrayStyles = []string{ArrayStyleCSV, ArrayStyleMulti, ArrayStylePipe, ArrayStyleSpace}
+
+// applyArrayStyle sets how an array parameter is serialized from its style
+// and explode settings. Parameters that set neither get the configured
+// default, or none so the MCP server's default (multi) applies. Styles the
+// request template can't express are reported and left out.
+func (c *Converter) applyArrayStyle(arg *models.Arg, param *openapi3.Parameter, path, method string) {
+	if arg.Type != "array" {
+		return
+	}
+	if param.Style == "" && param.Explode == nil {
+		arg.ArrayStyle = c.options.DefaultArrayStyle
+		return
+	}
+
+	serialization, err := param.SerializationMethod()
+	if err != nil {
+		c.addWarning("%s %s parameter %q: %v; the MCP server's default serialization is used", strings.ToUpper(method), path, param.Name, err)
+		return
+	}
+	style, explode := serialization.Style, serialization.Explode
+
+	switch {
+	case style == openapi3.SerializationSimple:
+		// Simple arrays are comma separated whether exploded or not
+		arg.ArrayStyle = ArrayStyleCSV
+	case explode && (style == openapi3.SerializationForm || style == openapi3.SerializationSpaceDelimited || style == openapi3.SerializationPipeDelimited):
+		arg.ArrayStyle = ArrayStyleMulti
+	case style == openapi3.SerializationForm:
+		arg.ArrayStyle = ArrayStyleCSV
+	case style == openapi3.SerializationSpaceDelimited:
+		arg.ArrayStyle = ArrayStyleSpace
+	case style == openapi3.SerializationPipeDelimited:
+		arg.ArrayStyle = ArrayStylePipe
+	default:
+		c.addWarning("%s %s parameter %q uses array style %q, which the request template can't represent; the MCP server's default serialization is used", strings.ToUpper(method), path, param.Name, style)
+	}
+}
@@ -0,0 +1,43 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// applyBodyWrapper sets a body template that sends the tool's body arguments
+// as a JSON object under the BodyWrapper key. pick leaves out the optional
+// arguments the caller didn't pass. Bodies that aren't a JSON object of
+// arguments are sent unwrapped with a warning.
+func (c *Converter) applyBodyWrapper(tool *models.Tool, path, method, mediaType string, operation *openapi3.Operation) {
+	if c.options.BodyWrapper == "" || operation.RequestBody == nil || operation.RequestBody.Value == nil {
+		return
+	}
+	operationName := strings.ToUpper(method) + " " + path
+	if !strings.Contains(mediaTypeBase(mediaType), "json") {
+		c.addWarning("body_wrapper: %s sends %s, which can't be wrapped; its body is sent as is", operationName, mediaType)
+		return
+	}
+
+	var names []string
+	for _, arg := range tool.Args {
+		if arg.Position != "body" {
+			continue
+		}
+		if wireName, ok := tool.ParamMapping[arg.Name]; ok {
+			c.addWarning("body_wrapper: %s renames body argument %q to %q, which the wrapped body can't map back; its body is sent as is", operationName, wireName, arg.Name)
+			return
+		}
+		names = append(names, strconv.Quote(arg.Name))
+	}
+	if len(names) == 0 {
+		c.addWarning("body_wrapper: %s has no body properties to wrap; its body is sent as is", operationName)
+		return
+	}
+
+	tool.RequestTemplate.Body = fmt.Sprintf("{%s: {{toJson (pick .args %s)}}}", strconv.Quote(c.options.BodyWrapper), strings.Join(names, " "))
+}
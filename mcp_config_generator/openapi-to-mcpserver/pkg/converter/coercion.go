@@ -0,0 +1,99 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// Coercion policies of ConvertOptions.CoerceTypes
+const (
+	// CoercionNone leaves arguments of the type as they are
+	CoercionNone = "none"
+	// CoercionHint marks arguments of the type with coerce: true
+	CoercionHint = "hint"
+	// CoercionRelax also accepts strings in the arguments' type, restricted
+	// to values that convert
+	CoercionRelax = "relax"
+)
+
+// CoercionPolicies lists the supported coercion policies
+var CoercionPolicies = []string{CoercionNone, CoercionHint, CoercionRelax}
+
+// CoercibleTypes lists the argument types CoerceTypes applies to
+var CoercibleTypes = []string{"integer", "number", "boolean"}
+
+// coercionPatterns restrict the strings a relaxed argument accepts to the
+// ones that convert to its type
+var coercionPatterns = map[string]string{
+	"integer": `^-?[0-9]+$`,
+	"number":  `^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`,
+	"boolean": `^(true|false)$`,
+}
+
+// DefaultCoercion returns the coercion policies used when coercion is
+// enabled without choosing them: hints for every coercible type
+func DefaultCoercion() map[string]string {
+	policies := make(map[string]string, len(CoercibleTypes))
+	for _, schemaType := range CoercibleTypes {
+		policies[schemaType] = CoercionHint
+	}
+	return policies
+}
+
+// validateCoerceTypes checks the types and policies of CoerceTypes
+func validateCoerceTypes(policies map[string]string) error {
+	for schemaType, policy := range policies {
+		if !contains(CoercibleTypes, schemaType) {
+			return fmt.Errorf("unsupported coercion type %q, must be one of: %s", schemaType, strings.Join(CoercibleTypes, ", "))
+		}
+		if !contains(CoercionPolicies, policy) {
+			return fmt.Errorf("unsupported coercion policy %q for %s, must be one of: %s", policy, schemaType, strings.Join(CoercionPolicies, ", "))
+		}
+	}
+	return nil
+}
+
+// applyCoercion marks the arguments whose type has a coercion policy. Enum
+// arguments are only hinted, since a string would never match their values.
+func (c *Converter) applyCoercion(tool *models.Tool) {
+	for i := range tool.Args {
+		arg := &tool.Args[i]
+		policy := c.options.CoerceTypes[arg.Type]
+		if policy == "" || policy == CoercionNone {
+			continue
+		}
+		arg.Coerce = true
+		if policy == CoercionRelax && len(arg.Enum) == 0 {
+			arg.AcceptString = true
+			if arg.Pattern == "" {
+				arg.Pattern = coercionPatterns[arg.Type]
+			}
+		}
+	}
+}
+
+// reportCoercion adds a warning listing the arguments that got coercion
+// hints
+func (c *Converter) reportCoercion(tools []models.Tool) {
+	var coerced []string
+	for _, tool := range tools {
+		for _, arg := range tool.Args {
+			if !arg.Coerce {
+				continue
+			}
+			form := "hint"
+			if arg.AcceptString {
+				form = "accepts strings"
+			}
+			coerced = append(coerced, fmt.Sprintf("%s.%s (%s, %s)", tool.Name, arg.Name, arg.Type, form))
+		}
+	}
+	if len(coerced) == 0 {
+		return
+	}
+	sort.Strings(coerced)
+	c.addWarning("coerce_types: %d argument(s) got coercion hints: %s", len(coerced), strings.Join(coerced, ", "))
+}
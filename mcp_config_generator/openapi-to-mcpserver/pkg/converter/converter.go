@@ -3,8 +3,11 @@ package converter
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"regexp"
 	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
@@ -14,8 +17,44 @@ import (
 
 // Converter represents an OpenAPI to MCP converter
 type Converter struct {
-	parser  *parser.Parser
-	options models.ConvertOptions
+	parser   *parser.Parser
+	options  models.ConvertOptions
+	warnings []string
+	skipped  []models.SkippedOperation
+
+	// forcedOperations holds the ForceRequired operationIds that were found
+	forcedOperations map[string]bool
+
+	// promotedDefaults counts the parameter examples used as defaults
+	promotedDefaults int
+
+	// reportedSchemes holds the security schemes already warned about
+	reportedSchemes map[string]bool
+
+	// schemaTruncated is set when MaxSchemaDepth cut short the current operation's schemas
+	schemaTruncated bool
+	// truncatedEnums lists the tool arguments whose enums MaxEnumValues
+	// shortened during this conversion
+	truncatedEnums []string
+	// descriptionLanguages holds the languages FilterDescriptionLanguage
+	// detected during this conversion
+	descriptionLanguages []models.OperationLanguage
+
+	// currentTool is the name of the tool being converted, for warnings
+	currentTool string
+
+	// pipeline holds the pipeline steps applied, in order
+	pipeline []string
+
+	// strippedNames holds the tool names of the operations whose operationId
+	// prefix is stripped, see OperationIDStripPrefix
+	strippedNames map[*openapi3.Operation]string
+
+	// documentHash, cacheHits and cacheMisses are the ToolCache state of the
+	// current conversion
+	documentHash string
+	cacheHits    int
+	cacheMisses  int
 }
 
 // NewConverter creates a new OpenAPI to MCP converter
@@ -39,6 +78,71 @@ func (c *Converter) Convert() (*models.MCPConfig, error) {
 	if c.parser.GetDocument() == nil {
 		return nil, fmt.Errorf("no OpenAPI document loaded")
 	}
+	c.warnings = nil
+	c.skipped = nil
+	c.pipeline = nil
+	c.reportedSchemes = make(map[string]bool)
+	c.promotedDefaults = 0
+	c.forcedOperations = make(map[string]bool)
+	c.truncatedEnums = nil
+	c.descriptionLanguages = nil
+	c.documentHash = ""
+	c.cacheHits = 0
+	c.cacheMisses = 0
+
+	if err := validatePathPrefix("path prefix", c.options.PathPrefix); err != nil {
+		return nil, err
+	}
+	if err := validatePathPrefix("strip path prefix", c.options.StripPathPrefix); err != nil {
+		return nil, err
+	}
+	if c.options.NullHandling != "" && !contains(NullHandlings, c.options.NullHandling) {
+		return nil, fmt.Errorf("unsupported null handling %q, must be one of: %s", c.options.NullHandling, strings.Join(NullHandlings, ", "))
+	}
+	c.warnUnresolvedTypes()
+	if c.options.DefaultArrayStyle != "" && !contains(ArrayStyles, c.options.DefaultArrayStyle) {
+		return nil, fmt.Errorf("unsupported default array style %q, must be one of: %s", c.options.DefaultArrayStyle, strings.Join(ArrayStyles, ", "))
+	}
+	if c.options.DescriptionSource != "" && !contains(DescriptionSources, c.options.DescriptionSource) {
+		return nil, fmt.Errorf("unsupported description source %q, must be one of: %s", c.options.DescriptionSource, strings.Join(DescriptionSources, ", "))
+	}
+	if c.options.RefStrategy != "" && !contains(RefStrategies, c.options.RefStrategy) {
+		return nil, fmt.Errorf("unsupported ref strategy %q, must be one of: %s", c.options.RefStrategy, strings.Join(RefStrategies, ", "))
+	}
+	if c.options.ParamNameCase != "" && !contains(ParamNameCases, c.options.ParamNameCase) {
+		return nil, fmt.Errorf("unsupported parameter name case %q, must be one of: %s", c.options.ParamNameCase, strings.Join(ParamNameCases, ", "))
+	}
+	if c.options.EmptyParamHandling != "" && !contains(EmptyParamHandlings, c.options.EmptyParamHandling) {
+		return nil, fmt.Errorf("unsupported empty parameter handling %q, must be one of: %s", c.options.EmptyParamHandling, strings.Join(EmptyParamHandlings, ", "))
+	}
+	if c.options.MissingBodySchema != "" && !contains(MissingBodySchemas, c.options.MissingBodySchema) {
+		return nil, fmt.Errorf("unsupported missing body schema handling %q, must be one of: %s", c.options.MissingBodySchema, strings.Join(MissingBodySchemas, ", "))
+	}
+	if c.options.MaxEnumValues < 0 {
+		return nil, fmt.Errorf("max enum values must not be negative, got %d", c.options.MaxEnumValues)
+	}
+	if c.options.EnumOverflow != "" && !contains(EnumOverflows, c.options.EnumOverflow) {
+		return nil, fmt.Errorf("unsupported enum overflow %q, must be one of: %s", c.options.EnumOverflow, strings.Join(EnumOverflows, ", "))
+	}
+	if c.options.ParameterConflictMode != "" && !contains(ParameterConflictModes, c.options.ParameterConflictMode) {
+		return nil, fmt.Errorf("unsupported parameter conflict mode %q, must be one of: %s", c.options.ParameterConflictMode, strings.Join(ParameterConflictModes, ", "))
+	}
+	if err := validateDescriptionLanguage(c.options.FilterDescriptionLanguage, c.options.DescriptionLanguageMode); err != nil {
+		return nil, err
+	}
+	if err := validateExtensionPassthrough(c.options.ExtensionPassthrough); err != nil {
+		return nil, err
+	}
+	if err := ValidateResponseCodes(c.options.ResponseCodesInDescription); err != nil {
+		return nil, err
+	}
+	if err := validateCoerceTypes(c.options.CoerceTypes); err != nil {
+		return nil, err
+	}
+	pipelineOrder, err := ResolvePipelineOrder(c.options.PipelineOrder)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create the MCP configuration
 	config := &models.MCPConfig{
@@ -46,6 +150,7 @@ func (c *Converter) Convert() (*models.MCPConfig, error) {
 			Name:            c.options.ServerName,
 			Config:          c.options.ServerConfig,
 			SecuritySchemes: []models.SecurityScheme{},
+			ReadOnly:        c.options.SafeMode,
 		},
 		Tools: []models.Tool{},
 	}
@@ -61,9 +166,15 @@ func (c *Converter) Convert() (*models.MCPConfig, error) {
 					Scheme: scheme.Scheme,
 					In:     scheme.In,
 					Name:   scheme.Name,
-					// DefaultCredential is not directly available in OpenAPI SecurityScheme,
-					// it's an extension for MCP. User can set it via template or manually.
 				}
+				// DefaultCredential is not available in an OpenAPI SecurityScheme,
+				// so it gets a placeholder for the deployment to substitute. A
+				// template can still set it.
+				placeholder, err := c.secretPlaceholder(mcpScheme)
+				if err != nil {
+					return nil, fmt.Errorf("failed to render secret placeholder for %s: %w", name, err)
+				}
+				mcpScheme.DefaultCredential = placeholder
 				config.Server.SecuritySchemes = append(config.Server.SecuritySchemes, mcpScheme)
 			}
 		}
@@ -73,34 +184,179 @@ func (c *Converter) Convert() (*models.MCPConfig, error) {
 		})
 	}
 
+	if c.options.EmitSpecInfo {
+		config.ID, config.Info = c.specInfo()
+	}
+
+	if c.options.StripExamples {
+		c.stripExamples()
+	}
+
+	c.checkTransportOptions()
+
+	// Keep only the first operation of each duplicated operationId
+	duplicates, err := c.findDuplicateOperationIDs()
+	if err != nil {
+		return nil, err
+	}
+	c.stripOperationIDPrefixes(duplicates)
+
 	// Process each path and operation
+	filters := c.compilePathFilters()
+	var operationCount, selectedCount int
+	var filterOverlaps []string
+	var withoutSuccess []string
+	var droppedMutating []string
+	var withoutBodySchema []string
+	var otherLanguage []string
+	var sources []toolSource
 	for path, pathItem := range c.parser.GetPaths() {
 		operations := getOperations(pathItem)
 		for method, operation := range operations {
-			tool, err := c.convertOperation(path, method, operation)
+			operationCount++
+			if included, excluded := filters.match(path); included && excluded {
+				filterOverlaps = append(filterOverlaps, fmt.Sprintf("%s %s", strings.ToUpper(method), path))
+			}
+			if reason := c.pathFilterReason(filters, path); reason != "" {
+				c.skipOperation(path, method, models.SkipFiltered, reason)
+				continue
+			}
+			selectedCount++
+			if c.droppedBySafeMode(method) {
+				droppedMutating = append(droppedMutating, fmt.Sprintf("%s %s", strings.ToUpper(method), path))
+				c.skipOperation(path, method, models.SkipFiltered, "mutating method left out by safe_mode")
+				continue
+			}
+			if duplicates[operation] {
+				c.skipOperation(path, method, models.SkipError, fmt.Sprintf("duplicate operationId %q", operation.OperationID))
+				continue
+			}
+			if c.options.RequireSuccessResponse && !hasSuccessResponse(operation) {
+				withoutSuccess = append(withoutSuccess, fmt.Sprintf("%s %s", strings.ToUpper(method), path))
+				c.skipOperation(path, method, models.SkipFiltered, "no 2xx response")
+				continue
+			}
+			if c.options.MissingBodySchema == MissingBodySchemaSkip && bodySchemaMissing(operation) {
+				withoutBodySchema = append(withoutBodySchema, fmt.Sprintf("%s %s", strings.ToUpper(method), path))
+				c.skipOperation(path, method, models.SkipFiltered, "request body without a schema")
+				continue
+			}
+			var language string
+			if c.options.FilterDescriptionLanguage != "" {
+				if detected := c.operationLanguage(path, method, operation); detected != LanguageUndetermined && detected != c.options.FilterDescriptionLanguage {
+					language = detected
+					otherLanguage = append(otherLanguage, fmt.Sprintf("%s %s (%s)", strings.ToUpper(method), path, detected))
+				}
+				if language != "" && c.options.DescriptionLanguageMode != DescriptionLanguageTag {
+					c.skipOperation(path, method, models.SkipFiltered, fmt.Sprintf("description in %s, not %s", language, c.options.FilterDescriptionLanguage))
+					continue
+				}
+			}
+
+			var tool *models.Tool
+			var err error
+			if c.options.ToolCache != nil {
+				tool, err = c.convertOperationCached(path, method, operation)
+			} else {
+				tool, err = c.convertOperation(path, method, operation)
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to convert operation %s %s: %w", method, path, err)
 			}
+			tool.Language = language
 			config.Tools = append(config.Tools, *tool)
+			sources = append(sources, toolSource{path: path, method: method, tags: operation.Tags})
 		}
 	}
-
-	// Apply template if provided
-	if c.options.TemplatePath != "" {
-		err := c.applyTemplate(config)
-		if err != nil {
-			return nil, fmt.Errorf("failed to apply template: %w", err)
+	if err := c.checkToolNames(config.Tools); err != nil {
+		return nil, err
+	}
+	if filters.active() {
+		c.addWarning("path filters selected %d of %d operation(s)", selectedCount, operationCount)
+	}
+	if len(filterOverlaps) > 0 {
+		sort.Strings(filterOverlaps)
+		c.addWarning("%d operation(s) matched both the include and exclude filters and were excluded: %s", len(filterOverlaps), strings.Join(filterOverlaps, ", "))
+	}
+	c.reportSafeMode(droppedMutating)
+	var unknownForced []string
+	for operationID := range c.options.ForceRequired {
+		if !c.forcedOperations[operationID] {
+			unknownForced = append(unknownForced, operationID)
 		}
 	}
-
-	// Sort tools by name for consistent output
-	sort.Slice(config.Tools, func(i, j int) bool {
-		return config.Tools[i].Name < config.Tools[j].Name
+	sort.Strings(unknownForced)
+	for _, operationID := range unknownForced {
+		c.addWarning("force_required: no operation with operationId %q", operationID)
+	}
+	c.checkResponseSizeHints()
+	c.checkExtensionPassthrough()
+	if c.promotedDefaults > 0 {
+		c.addWarning("promoted %d parameter example(s) to defaults", c.promotedDefaults)
+	}
+	if c.options.SynthesizeExamples {
+		c.reportSynthesizedExamples(config.Tools)
+	}
+	c.reportCoercion(config.Tools)
+	c.reportTruncatedEnums()
+	if c.options.FilterDescriptionLanguage != "" {
+		c.reportDescriptionLanguages(otherLanguage)
+	}
+	if len(withoutSuccess) > 0 {
+		sort.Strings(withoutSuccess)
+		c.addWarning("skipped %d operation(s) without a 2xx response: %s", len(withoutSuccess), strings.Join(withoutSuccess, ", "))
+	}
+	if len(withoutBodySchema) > 0 {
+		sort.Strings(withoutBodySchema)
+		c.addWarning("missing_body_schema: skipped %d operation(s) whose request body has no schema: %s", len(withoutBodySchema), strings.Join(withoutBodySchema, ", "))
+	}
+	sort.Slice(c.skipped, func(i, j int) bool {
+		if c.skipped[i].Path != c.skipped[j].Path {
+			return c.skipped[i].Path < c.skipped[j].Path
+		}
+		return c.skipped[i].Method < c.skipped[j].Method
 	})
 
+	if err := c.runPipeline(pipelineOrder, config, sources); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
+// GetWarnings returns the warnings collected during the last conversion
+func (c *Converter) GetWarnings() []string {
+	return c.warnings
+}
+
+// GetSkippedOperations returns the operations left out of the last conversion
+func (c *Converter) GetSkippedOperations() []models.SkippedOperation {
+	return c.skipped
+}
+
+// skipOperation records that an operation was left out of the configuration
+func (c *Converter) skipOperation(path, method, category, reason string) {
+	c.skipped = append(c.skipped, models.SkippedOperation{
+		Path:     path,
+		Method:   strings.ToUpper(method),
+		Category: category,
+		Reason:   reason,
+	})
+}
+
+// addWarning records a non-fatal problem found during conversion
+func (c *Converter) addWarning(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// addWarningOnce records a warning unless the same one was already recorded
+// during this conversion
+func (c *Converter) addWarningOnce(format string, args ...interface{}) {
+	if warning := fmt.Sprintf(format, args...); !contains(c.warnings, warning) {
+		c.warnings = append(c.warnings, warning)
+	}
+}
+
 // applyTemplate applies a template to the generated configuration
 func (c *Converter) applyTemplate(config *models.MCPConfig) error {
 	// Read the template file
@@ -218,46 +474,355 @@ func getOperations(pathItem *openapi3.PathItem) map[string]*openapi3.Operation {
 	return operations
 }
 
+// specInfo returns the $id and info block of a config. The $id is built from
+// the title and version only, so it stays the same across edits of one API
+// version; the source hash tells such edits apart.
+func (c *Converter) specInfo() (string, *models.SpecInfo) {
+	doc := c.parser.GetDocument()
+	info := &models.SpecInfo{
+		OpenAPIVersion: doc.OpenAPI,
+		SourceSHA256:   c.parser.GetSourceHash(),
+	}
+	if doc.Info != nil {
+		info.Title = doc.Info.Title
+		info.Version = doc.Info.Version
+	}
+
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(info.Title), "-"), "-")
+	if slug == "" {
+		slug = "api"
+	}
+	version := info.Version
+	if version == "" {
+		version = "unversioned"
+	}
+	return fmt.Sprintf("urn:openapi:%s:%s", slug, version), info
+}
+
+// nonSlugChars matches the runs of characters replaced in a $id title slug
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// checkTransportOptions warns about the requested transport settings. The
+// request template has no transport settings, so none of them can be
+// represented and the MCP server's defaults apply.
+func (c *Converter) checkTransportOptions() {
+	transport := c.options.Transport
+	var unsupported []string
+	if transport.HTTP2 != nil {
+		unsupported = append(unsupported, "request_http2")
+	}
+	if transport.KeepAlive != nil {
+		unsupported = append(unsupported, "request_keepalive")
+	}
+	if transport.MaxRedirects != nil {
+		unsupported = append(unsupported, "request_max_redirects")
+	}
+	for _, name := range unsupported {
+		c.addWarning("%s can't be represented in the MCP request template and was ignored", name)
+	}
+}
+
+// Operation orders supported by ConvertOptions.OperationOrder
+const (
+	OrderOperationID = "operationId"
+	OrderPath        = "path"
+	OrderMethod      = "method"
+	OrderTag         = "tag"
+	OrderSpec        = "spec"
+)
+
+// Description sources supported by ConvertOptions.DescriptionSource
+const (
+	// DescriptionFromBoth joins the summary and description with " - ",
+	// using whichever is present when only one is
+	DescriptionFromBoth = "both"
+	// DescriptionFromDescription uses only the description
+	DescriptionFromDescription = "description"
+	// DescriptionFromSummary uses only the summary
+	DescriptionFromSummary = "summary"
+	// DescriptionFromSummaryThenDescription uses the summary, or the
+	// description when there is no summary
+	DescriptionFromSummaryThenDescription = "summary_then_description"
+)
+
+// DescriptionSources lists the supported description sources
+var DescriptionSources = []string{DescriptionFromDescription, DescriptionFromSummary, DescriptionFromBoth, DescriptionFromSummaryThenDescription}
+
+// OperationOrders lists the valid values of ConvertOptions.OperationOrder
+var OperationOrders = []string{OrderOperationID, OrderPath, OrderMethod, OrderTag, OrderSpec}
+
+// toolSource records the operation a tool was generated from
+type toolSource struct {
+	path   string
+	method string
+	tags   []string
+}
+
+// sortTools orders the tools by ConvertOptions.OperationOrder. Ties are broken
+// by tool name so the output is stable whatever the order.
+func (c *Converter) sortTools(tools []models.Tool, sources []toolSource) error {
+	order := c.options.OperationOrder
+	if order == "" {
+		order = OrderOperationID
+	}
+	if !contains(OperationOrders, order) {
+		return fmt.Errorf("unsupported operation order %q, must be one of: %s", order, strings.Join(OperationOrders, ", "))
+	}
+
+	type entry struct {
+		tool   models.Tool
+		source toolSource
+	}
+	entries := make([]entry, len(tools))
+	for i := range tools {
+		entries[i] = entry{tool: tools[i], source: sources[i]}
+	}
+
+	firstTag := func(source toolSource) string {
+		if len(source.tags) == 0 {
+			return ""
+		}
+		return source.tags[0]
+	}
+	methodRank := func(method string) int {
+		for i, m := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+			if m == method {
+				return i
+			}
+		}
+		return -1
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].source, entries[j].source
+		switch order {
+		case OrderPath:
+			if a.path != b.path {
+				return a.path < b.path
+			}
+			if a.method != b.method {
+				return methodRank(a.method) < methodRank(b.method)
+			}
+		case OrderMethod:
+			if a.method != b.method {
+				return methodRank(a.method) < methodRank(b.method)
+			}
+			if a.path != b.path {
+				return a.path < b.path
+			}
+		case OrderTag:
+			// Untagged tools come last
+			tagA, tagB := firstTag(a), firstTag(b)
+			if tagA != tagB {
+				if tagA == "" || tagB == "" {
+					return tagB == ""
+				}
+				return tagA < tagB
+			}
+		case OrderSpec:
+			indexA := c.parser.GetOperationIndex(a.path, a.method)
+			indexB := c.parser.GetOperationIndex(b.path, b.method)
+			if indexA != indexB {
+				return indexA < indexB
+			}
+		}
+		return entries[i].tool.Name < entries[j].tool.Name
+	})
+
+	// Keep the sources in step so later pipeline steps can use them
+	for i := range entries {
+		tools[i] = entries[i].tool
+		sources[i] = entries[i].source
+	}
+	return nil
+}
+
+// pathFilterReason returns why the path is filtered out by the path
+// filters, or "" when its operations should be converted. Exclude filters
+// win over include filters.
+func (c *Converter) pathFilterReason(filters pathFilters, path string) string {
+	included, excluded := filters.match(path)
+	if excluded {
+		return "path matches the exclude filter"
+	}
+	if len(filters.include) > 0 && !included {
+		return "path does not match the include filter"
+	}
+	return ""
+}
+
+// findDuplicateOperationIDs looks for operationIds shared by several
+// operations. The first operation in path and method order is kept and the
+// others are returned so they can be skipped, with a warning for each
+// duplicated operationId. With StrictOperationIDs an error is returned instead.
+func (c *Converter) findDuplicateOperationIDs() (map[*openapi3.Operation]bool, error) {
+	type located struct {
+		name      string
+		operation *openapi3.Operation
+	}
+
+	paths := make([]string, 0, len(c.parser.GetPaths()))
+	for path := range c.parser.GetPaths() {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	byID := make(map[string][]located)
+	var ids []string
+	for _, path := range paths {
+		operations := getOperations(c.parser.GetPaths()[path])
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			operation := operations[method]
+			if operation.OperationID == "" {
+				continue
+			}
+			if _, seen := byID[operation.OperationID]; !seen {
+				ids = append(ids, operation.OperationID)
+			}
+			byID[operation.OperationID] = append(byID[operation.OperationID], located{
+				name:      fmt.Sprintf("%s %s", strings.ToUpper(method), path),
+				operation: operation,
+			})
+		}
+	}
+
+	duplicates := make(map[*openapi3.Operation]bool)
+	var descriptions []string
+	for _, id := range ids {
+		operations := byID[id]
+		if len(operations) < 2 {
+			continue
+		}
+
+		var dropped []string
+		for _, op := range operations[1:] {
+			duplicates[op.operation] = true
+			dropped = append(dropped, op.name)
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%q is used by %s and %s", id, operations[0].name, strings.Join(dropped, ", ")))
+		if !c.options.StrictOperationIDs {
+			c.addWarning("duplicate operationId %q: kept %s, skipped %s", id, operations[0].name, strings.Join(dropped, ", "))
+		}
+	}
+
+	if c.options.StrictOperationIDs && len(descriptions) > 0 {
+		return nil, fmt.Errorf("duplicate operationIds: %s", strings.Join(descriptions, "; "))
+	}
+	return duplicates, nil
+}
+
 // convertOperation converts an OpenAPI operation to an MCP tool
 func (c *Converter) convertOperation(path, method string, operation *openapi3.Operation) (*models.Tool, error) {
 	// Generate a tool name
-	toolName := c.parser.GetOperationID(path, method, operation)
+	operationID := c.parser.GetOperationID(path, method, operation)
+	toolName := c.baseToolName(operationID, operation)
 	if c.options.ToolNamePrefix != "" {
 		toolName = c.options.ToolNamePrefix + toolName
 	}
 
 	// Create the tool
+	description := getDescription(operation, c.options.DescriptionSource)
+	if c.options.DescriptionTemplate != nil {
+		var err error
+		description, err = renderDescription(c.options.DescriptionTemplate, path, method, operationID, operation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render description template: %w", err)
+		}
+	}
+	description = c.describeResponseCodes(description, operation)
+	c.currentTool = toolName
 	tool := &models.Tool{
 		Name:        toolName,
-		Description: getDescription(operation),
+		Description: description,
 		Args:        []models.Arg{},
 	}
+	if c.options.PropagateTags && len(operation.Tags) > 0 {
+		tool.Tags = append([]string(nil), operation.Tags...)
+	}
+	if c.options.AnnotateAuth {
+		tool.Auth = c.toolAuth(operation)
+	}
+	if c.options.AnnotateSource {
+		tool.Source = &models.SourceLocation{Method: strings.ToUpper(method), Path: path}
+	}
 
-	// Convert parameters to arguments
-	args, err := c.convertParameters(operation.Parameters)
+	// Convert parameters to arguments, with nested schemas limited to the
+	// configured depth
+	c.schemaTruncated = false
+	parameters, err := c.operationParameters(path, method, operation)
+	if err != nil {
+		return nil, err
+	}
+	args, err := c.convertParameters(path, method, parameters)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert parameters: %w", err)
 	}
 	tool.Args = append(tool.Args, args...)
 
+	// Pick the request body content type used by the tool
+	mediaType := c.requestMediaType(path, method, operation)
+
 	// Convert request body to arguments
-	bodyArgs, err := c.convertRequestBody(operation.RequestBody)
+	bodyArgs, err := c.convertRequestBody(operation.RequestBody, mediaType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert request body: %w", err)
 	}
+	// Take a request body without a schema as a single argument
+	missingBody := bodySchemaMissing(operation)
+	if missingBody {
+		bodyArgs = append(bodyArgs, c.missingBodyArg(operation))
+	}
 	tool.Args = append(tool.Args, bodyArgs...)
 
+	// Mark the arguments the caller knows the backend needs as required
+	if names, ok := c.options.ForceRequired[operationID]; ok {
+		c.forcedOperations[operationID] = true
+		for _, name := range names {
+			found := false
+			for i := range tool.Args {
+				if tool.Args[i].Name == name {
+					tool.Args[i].Required = true
+					found = true
+				}
+			}
+			if !found {
+				c.addWarning("force_required: operation %q has no parameter %q", operationID, name)
+			}
+		}
+	}
+
+	c.applyResponseSizeHint(tool, operationID, operation)
+	c.applyExtensionPassthrough(tool, operation)
+
+	// Rename the arguments once force_required has matched the spec names
+	if c.options.ParamNameCase != "" {
+		c.normalizeArgNames(tool)
+	}
+
+	c.applyCoercion(tool)
+
 	// Sort arguments by name for consistent output
 	sort.Slice(tool.Args, func(i, j int) bool {
 		return tool.Args[i].Name < tool.Args[j].Name
 	})
 
 	// Create request template
-	requestTemplate, err := c.createRequestTemplate(path, method, operation)
+	requestTemplate, err := c.createRequestTemplate(path, method, operation, mediaType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request template: %w", err)
 	}
 	tool.RequestTemplate = *requestTemplate
+	if missingBody {
+		c.applyMissingBodyTemplate(tool)
+	} else {
+		c.applyBodyWrapper(tool, path, method, mediaType, operation)
+	}
 
 	// Create response template
 	responseTemplate, err := c.createResponseTemplate(operation)
@@ -266,11 +831,19 @@ func (c *Converter) convertOperation(path, method string, operation *openapi3.Op
 	}
 	tool.ResponseTemplate = *responseTemplate
 
+	if c.options.IncludeResponseHeaders {
+		c.applyResponseHeaders(tool, operation)
+	}
+
+	if c.schemaTruncated {
+		c.addWarning("schemas of %s were truncated at depth %d", toolName, c.options.MaxSchemaDepth)
+	}
+
 	return tool, nil
 }
 
 // convertParameters converts OpenAPI parameters to MCP arguments
-func (c *Converter) convertParameters(parameters openapi3.Parameters) ([]models.Arg, error) {
+func (c *Converter) convertParameters(path, method string, parameters openapi3.Parameters) ([]models.Arg, error) {
 	args := []models.Arg{}
 
 	for _, paramRef := range parameters {
@@ -292,43 +865,142 @@ func (c *Converter) convertParameters(parameters openapi3.Parameters) ([]models.
 
 			// Set the type based on the schema type
 			arg.Type = schema.Type
+			c.applyFormatMapping(&arg, schema)
+			c.applyNumericConstraints(&arg, schema)
+			c.applyNullable(&arg, schema)
 
 			// Handle enum values
 			if len(schema.Enum) > 0 {
 				arg.Enum = schema.Enum
+				c.limitEnum(&arg)
+				c.appendEnumDescriptions(&arg, schema)
 			}
 
 			// Handle array type
 			if schema.Type == "array" && schema.Items != nil && schema.Items.Value != nil {
-				arg.Items = map[string]interface{}{
-					"type": schema.Items.Value.Type,
+				if ref, ok := c.sharedRef(schema.Items); ok {
+					arg.Items = refSchema(ref)
+				} else {
+					arg.Items = map[string]interface{}{
+						"type": schema.Items.Value.Type,
+					}
 				}
 			}
+			c.applyArrayStyle(&arg, param, path, method)
 
 			// Handle object type
-			if schema.Type == "object" && len(schema.Properties) > 0 {
-				arg.Properties = make(map[string]interface{})
-				for propName, propRef := range schema.Properties {
-					if propRef.Value != nil {
-						arg.Properties[propName] = map[string]interface{}{
-							"type": propRef.Value.Type,
-						}
-						if propRef.Value.Description != "" {
-							arg.Properties[propName].(map[string]interface{})["description"] = propRef.Value.Description
-						}
-					}
-				}
+			if ref, ok := c.sharedRef(param.Schema); ok && schema.Type == "object" {
+				arg.Ref = ref
+			} else if schema.Type == "object" {
+				c.applyArgProperties(&arg, schema, param.Name)
 			}
 		}
 
+		if c.options.PromoteExamplesToDefaults {
+			if example, ok := promotableExample(param); ok {
+				arg.Default = example
+				c.promotedDefaults++
+			}
+		}
+		c.applyEmptyParamHandling(&arg, param)
+		if param.Schema != nil && param.Schema.Value != nil {
+			c.applySynthesizedExample(&arg, param.Schema.Value, param.Example != nil || len(param.Examples) > 0)
+		}
+
 		args = append(args, arg)
 	}
 
 	return args, nil
 }
 
-// convertRequestBody converts an OpenAPI request body to MCP arguments
-func (c *Converter) convertRequestBody(requestBodyRef *openapi3.RequestBodyRef) ([]models.Arg, error) {
+// promotableExample returns the example of a parameter when it makes a
+// sensible default: the parameter is optional, not part of the path, has no
+// default of its own, and the example is one of its enum values if it has any
+func promotableExample(param *openapi3.Parameter) (interface{}, bool) {
+	if param.Required || param.In == openapi3.ParameterInPath {
+		return nil, false
+	}
+
+	var schema *openapi3.Schema
+	if param.Schema != nil {
+		schema = param.Schema.Value
+	}
+	if schema != nil && schema.Default != nil {
+		return nil, false
+	}
+
+	example := param.Example
+	if example == nil && schema != nil {
+		example = schema.Example
+	}
+	if example == nil {
+		return nil, false
+	}
+
+	if schema != nil && len(schema.Enum) > 0 {
+		for _, value := range schema.Enum {
+			if reflect.DeepEqual(value, example) {
+				return example, true
+			}
+		}
+		return nil, false
+	}
+	return example, true
+}
+
+// DefaultRequestContentType is sent for request bodies that declare no
+// content type unless ConvertOptions.DefaultRequestContentType is set
+const DefaultRequestContentType = "application/json"
+
+// requestMediaType selects the request body content type for an operation.
+// The first entry of PreferredRequestMedia declared by the operation wins;
+// otherwise the alphabetically first content type is used. A request body
+// without content gets the default content type with a warning.
+func (c *Converter) requestMediaType(path, method string, operation *openapi3.Operation) string {
+	if operation.RequestBody == nil || operation.RequestBody.Value == nil {
+		return ""
+	}
+	if len(operation.RequestBody.Value.Content) == 0 {
+		contentType := c.options.DefaultRequestContentType
+		if contentType == "" {
+			contentType = DefaultRequestContentType
+		}
+		c.addWarning("%s %s declares a request body without a content type, using %s", strings.ToUpper(method), path, contentType)
+		return contentType
+	}
+
+	contentTypes := make([]string, 0, len(operation.RequestBody.Value.Content))
+	for contentType := range operation.RequestBody.Value.Content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+
+	for _, preferred := range c.options.PreferredRequestMedia {
+		for _, contentType := range contentTypes {
+			if strings.EqualFold(mediaTypeBase(contentType), mediaTypeBase(preferred)) {
+				return contentType
+			}
+		}
+	}
+
+	if len(c.options.PreferredRequestMedia) > 0 {
+		c.addWarning("%s %s declares none of the preferred request media types, using %s", strings.ToUpper(method), path, contentTypes[0])
+	}
+	return contentTypes[0]
+}
+
+// mediaTypeBase strips parameters such as charset from a media type
+func mediaTypeBase(mediaType string) string {
+	if i := strings.Index(mediaType, ";"); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+	return strings.TrimSpace(mediaType)
+}
+
+// convertRequestBody converts an OpenAPI request body to MCP arguments. When
+// preferred request media types are configured only the selected media type
+// is converted.
+func (c *Converter) convertRequestBody(requestBodyRef *openapi3.RequestBodyRef, selectedMediaType string) ([]models.Arg, error) {
 	args := []models.Arg{}
 
 	if requestBodyRef == nil || requestBodyRef.Value == nil {
@@ -339,6 +1011,9 @@ func (c *Converter) convertRequestBody(requestBodyRef *openapi3.RequestBodyRef)
 
 	// Process each content type
 	for contentType, mediaType := range requestBody.Content {
+		if len(c.options.PreferredRequestMedia) > 0 && contentType != selectedMediaType {
+			continue
+		}
 		if mediaType.Schema == nil || mediaType.Schema.Value == nil {
 			continue
 		}
@@ -363,33 +1038,35 @@ func (c *Converter) convertRequestBody(requestBodyRef *openapi3.RequestBodyRef)
 						Required:    contains(schema.Required, propName),
 						Position:    "body", // Set position to "body" for request body parameters
 					}
+					c.applyFormatMapping(&arg, propRef.Value)
+					c.applyNumericConstraints(&arg, propRef.Value)
+					c.applyNullable(&arg, propRef.Value)
 
 					// Handle enum values
 					if len(propRef.Value.Enum) > 0 {
 						arg.Enum = propRef.Value.Enum
+						c.limitEnum(&arg)
+						c.appendEnumDescriptions(&arg, propRef.Value)
 					}
 
 					// Handle array type
 					if propRef.Value.Type == "array" && propRef.Value.Items != nil && propRef.Value.Items.Value != nil {
-						arg.Items = map[string]interface{}{
-							"type": propRef.Value.Items.Value.Type,
+						if ref, ok := c.sharedRef(propRef.Value.Items); ok {
+							arg.Items = refSchema(ref)
+						} else {
+							arg.Items = map[string]interface{}{
+								"type": propRef.Value.Items.Value.Type,
+							}
 						}
 					}
 
 					// Handle object type
-					if propRef.Value.Type == "object" && len(propRef.Value.Properties) > 0 {
-						arg.Properties = make(map[string]interface{})
-						for subPropName, subPropRef := range propRef.Value.Properties {
-							if subPropRef.Value != nil {
-								arg.Properties[subPropName] = map[string]interface{}{
-									"type": subPropRef.Value.Type,
-								}
-								if subPropRef.Value.Description != "" {
-									arg.Properties[subPropName].(map[string]interface{})["description"] = subPropRef.Value.Description
-								}
-							}
-						}
+					if ref, ok := c.sharedRef(propRef); ok && propRef.Value.Type == "object" {
+						arg.Ref = ref
+					} else if propRef.Value.Type == "object" {
+						c.applyArgProperties(&arg, propRef.Value, propName)
 					}
+					c.applySynthesizedExample(&arg, propRef.Value, false)
 
 					args = append(args, arg)
 				}
@@ -401,19 +1078,16 @@ func (c *Converter) convertRequestBody(requestBodyRef *openapi3.RequestBodyRef)
 }
 
 // createRequestTemplate creates an MCP request template from an OpenAPI operation
-func (c *Converter) createRequestTemplate(path, method string, operation *openapi3.Operation) (*models.RequestTemplate, error) {
+func (c *Converter) createRequestTemplate(path, method string, operation *openapi3.Operation, mediaType string) (*models.RequestTemplate, error) {
 	// Get the server URL from the OpenAPI specification
-	var serverURL string
-	if servers := c.parser.GetDocument().Servers; len(servers) > 0 {
-		serverURL = servers[0].URL
-	}
+	serverURL := c.operationServerURL(path, method, operation)
 
 	// Remove trailing slash from server URL if present
 	serverURL = strings.TrimSuffix(serverURL, "/")
 
 	// Create the request template
 	template := &models.RequestTemplate{
-		URL:     serverURL + path,
+		URL:     serverURL + c.requestPath(path, method),
 		Method:  strings.ToUpper(method),
 		Headers: []models.Header{},
 	}
@@ -433,21 +1107,74 @@ func (c *Converter) createRequestTemplate(path, method string, operation *openap
 		}
 	}
 
-	// Add Content-Type header based on request body content type
-	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
-		for contentType := range operation.RequestBody.Value.Content {
-			// Add the Content-Type header
-			template.Headers = append(template.Headers, models.Header{
-				Key:   "Content-Type",
-				Value: contentType,
-			})
-			break // Just use the first content type
-		}
+	// Add Content-Type header based on the selected request body content type
+	if mediaType != "" {
+		template.Headers = append(template.Headers, models.Header{
+			Key:   "Content-Type",
+			Value: mediaType,
+		})
 	}
 
 	return template, nil
 }
 
+// operationServerURL returns the base URL of an operation. Operation-level
+// servers take precedence over path-level servers, which take precedence over
+// the document-level servers. An override that cannot be resolved is reported
+// as a warning and the next level is used instead.
+func (c *Converter) operationServerURL(path, method string, operation *openapi3.Operation) string {
+	if operation.Servers != nil && len(*operation.Servers) > 0 {
+		serverURL, err := resolveServerURL((*operation.Servers)[0])
+		if err == nil {
+			return serverURL
+		}
+		c.addWarning("ignoring operation-level server of %s %s: %v", strings.ToUpper(method), path, err)
+	}
+
+	if pathItem := c.parser.GetPaths()[path]; pathItem != nil && len(pathItem.Servers) > 0 {
+		serverURL, err := resolveServerURL(pathItem.Servers[0])
+		if err == nil {
+			return serverURL
+		}
+		c.addWarning("ignoring path-level server of %s %s: %v", strings.ToUpper(method), path, err)
+	}
+
+	if servers := c.parser.GetDocument().Servers; len(servers) > 0 && servers[0] != nil {
+		serverURL, err := resolveServerURL(servers[0])
+		if err != nil {
+			// Keep the raw URL so the template can still be fixed up by hand
+			return servers[0].URL
+		}
+		return serverURL
+	}
+
+	return ""
+}
+
+// resolveServerURL substitutes the server variables in a server URL with
+// their default values
+func resolveServerURL(server *openapi3.Server) (string, error) {
+	if server == nil || server.URL == "" {
+		return "", fmt.Errorf("server has no URL")
+	}
+
+	names, err := server.ParameterNames()
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", server.URL, err)
+	}
+
+	serverURL := server.URL
+	for _, name := range names {
+		variable, ok := server.Variables[name]
+		if !ok || variable == nil || variable.Default == "" {
+			return "", fmt.Errorf("server variable %q of %q has no default value", name, server.URL)
+		}
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+	}
+
+	return serverURL, nil
+}
+
 // createResponseTemplate creates an MCP response template from an OpenAPI operation
 func (c *Converter) createResponseTemplate(operation *openapi3.Operation) (*models.ResponseTemplate, error) {
 	// Find the success response (200, 201, etc.)
@@ -492,7 +1219,7 @@ func (c *Converter) createResponseTemplate(operation *openapi3.Operation) (*mode
 			// Handle array type
 			prependBody.WriteString(fmt.Sprintf("- **items**: Array of items (Type: array)\n"))
 			// Process array items recursively
-			c.processSchemaProperties(&prependBody, schema.Items.Value, "items", 1, 10)
+			c.processSchemaProperties(&prependBody, schema.Items.Value, "items", 1, c.responseSchemaDepth())
 		} else if schema.Type == "object" && len(schema.Properties) > 0 {
 			// Get property names and sort them alphabetically for consistent output
 			propNames := make([]string, 0, len(schema.Properties))
@@ -516,7 +1243,7 @@ func (c *Converter) createResponseTemplate(operation *openapi3.Operation) (*mode
 				prependBody.WriteString("\n")
 
 				// Process nested properties recursively
-				c.processSchemaProperties(&prependBody, propRef.Value, propName, 1, 10)
+				c.processSchemaProperties(&prependBody, propRef.Value, propName, 1, c.responseSchemaDepth())
 			}
 		}
 	}
@@ -527,12 +1254,27 @@ func (c *Converter) createResponseTemplate(operation *openapi3.Operation) (*mode
 	return template, nil
 }
 
+// responseSchemaDepth returns the nesting depth processSchemaProperties may
+// descend to. Top-level response fields are written before recursing, so a
+// MaxSchemaDepth of N allows N-1 further levels.
+func (c *Converter) responseSchemaDepth() int {
+	if c.options.MaxSchemaDepth > 0 && c.options.MaxSchemaDepth-1 < defaultSchemaDepth {
+		return c.options.MaxSchemaDepth - 1
+	}
+	return defaultSchemaDepth
+}
+
 // processSchemaProperties recursively processes schema properties and writes them to the prependBody
 // path is the current property path (e.g., "data.items")
 // depth is the current nesting depth (starts at 1)
 // maxDepth is the maximum allowed nesting depth
 func (c *Converter) processSchemaProperties(prependBody *strings.Builder, schema *openapi3.Schema, path string, depth, maxDepth int) {
 	if depth > maxDepth {
+		// Note fields dropped by a configured depth limit
+		if c.options.MaxSchemaDepth > 0 && (len(schema.Properties) > 0 || schema.Items != nil) {
+			prependBody.WriteString(fmt.Sprintf("%s- **%s.***: Nested fields omitted (schema depth limit reached)\n", strings.Repeat("  ", depth), path))
+			c.schemaTruncated = true
+		}
 		return // Stop recursion if max depth is reached
 	}
 
@@ -613,8 +1355,142 @@ func (c *Converter) processSchemaProperties(prependBody *strings.Builder, schema
 	}
 }
 
+// DescriptionData holds the operation fields available to description templates
+type DescriptionData struct {
+	Method       string
+	Path         string
+	Summary      string
+	Description  string
+	OperationId  string
+	ExternalDocs string
+}
+
+// renderDescription executes a description template for an operation
+func renderDescription(tmpl *template.Template, path, method, operationID string, operation *openapi3.Operation) (string, error) {
+	data := DescriptionData{
+		Method:      strings.ToUpper(method),
+		Path:        path,
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		OperationId: operationID,
+	}
+	if operation.ExternalDocs != nil {
+		data.ExternalDocs = operation.ExternalDocs.URL
+	}
+
+	var description strings.Builder
+	if err := tmpl.Execute(&description, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(description.String()), nil
+}
+
+// supportedSecurityTypes are the security scheme types MCP servers can apply
+var supportedSecurityTypes = []string{"http", "apiKey"}
+
+// toolAuth describes the security requirements of an operation, falling back
+// to the document-level requirements when the operation declares none
+func (c *Converter) toolAuth(operation *openapi3.Operation) *models.ToolAuth {
+	requirements := c.parser.GetDocument().Security
+	if operation.Security != nil {
+		requirements = *operation.Security
+	}
+
+	auth := &models.ToolAuth{Required: len(requirements) > 0}
+	for _, requirement := range requirements {
+		// An empty requirement makes authentication optional
+		if len(requirement) == 0 {
+			auth.Required = false
+			continue
+		}
+
+		schemes := make([]string, 0, len(requirement))
+		for name := range requirement {
+			schemes = append(schemes, name)
+			c.checkSecurityScheme(name)
+		}
+		sort.Strings(schemes)
+		auth.Requirements = append(auth.Requirements, schemes)
+	}
+
+	return auth
+}
+
+// checkSecurityScheme warns once per conversion about a referenced security
+// scheme that is undefined or of a type MCP servers can't apply
+func (c *Converter) checkSecurityScheme(name string) {
+	if c.reportedSchemes[name] {
+		return
+	}
+
+	var scheme *openapi3.SecurityScheme
+	if components := c.parser.GetDocument().Components; components != nil {
+		if ref := components.SecuritySchemes[name]; ref != nil {
+			scheme = ref.Value
+		}
+	}
+
+	switch {
+	case scheme == nil:
+		c.addWarning("security scheme %q is referenced but not defined", name)
+	case !contains(supportedSecurityTypes, scheme.Type):
+		c.addWarning("security scheme %q of type %q can't be represented in the MCP config", name, scheme.Type)
+	default:
+		return
+	}
+	c.reportedSchemes[name] = true
+}
+
+// collectTags lists the tags used by the tools, sorted by name, with the
+// descriptions from the document-level tags where available
+func (c *Converter) collectTags(tools []models.Tool) []models.Tag {
+	descriptions := make(map[string]string)
+	for _, tag := range c.parser.GetDocument().Tags {
+		if tag != nil {
+			descriptions[tag.Name] = tag.Description
+		}
+	}
+
+	var names []string
+	for _, tool := range tools {
+		for _, name := range tool.Tags {
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var tags []models.Tag
+	for _, name := range names {
+		tags = append(tags, models.Tag{Name: name, Description: descriptions[name]})
+	}
+	return tags
+}
+
+// hasSuccessResponse reports whether an operation declares any 2xx response
+func hasSuccessResponse(operation *openapi3.Operation) bool {
+	for code := range operation.Responses {
+		if strings.HasPrefix(code, "2") {
+			return true
+		}
+	}
+	return false
+}
+
 // getDescription returns a description for an operation
-func getDescription(operation *openapi3.Operation) string {
+func getDescription(operation *openapi3.Operation, source string) string {
+	switch source {
+	case DescriptionFromDescription:
+		return operation.Description
+	case DescriptionFromSummary:
+		return operation.Summary
+	case DescriptionFromSummaryThenDescription:
+		if operation.Summary != "" {
+			return operation.Summary
+		}
+		return operation.Description
+	}
 	if operation.Summary != "" {
 		if operation.Description != "" {
 			return fmt.Sprintf("%s - %s", operation.Summary, operation.Description)
@@ -2,9 +2,15 @@ package converter
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
 	"github.com/higress-group/openapi-to-mcpserver/pkg/parser"
@@ -113,3 +119,2979 @@ func TestEndToEndConversion(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireSuccessResponse(t *testing.T) {
+	testCases := []struct {
+		name            string
+		requireSuccess  bool
+		expectedTools   []string
+		expectedWarning bool
+	}{
+		{
+			name:          "Default keeps error-only operations",
+			expectedTools: []string{"getStatus", "legacyOperation"},
+		},
+		{
+			name:            "Skips error-only operations",
+			requireSuccess:  true,
+			expectedTools:   []string{"getStatus"},
+			expectedWarning: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/error-only-responses.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{
+				RequireSuccessResponse: tc.requireSuccess,
+			})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			var toolNames []string
+			for _, tool := range config.Tools {
+				toolNames = append(toolNames, tool.Name)
+			}
+			assert.Equal(t, tc.expectedTools, toolNames)
+
+			if tc.expectedWarning {
+				assert.Len(t, c.GetWarnings(), 1)
+				assert.Contains(t, c.GetWarnings()[0], "POST /legacy")
+			} else {
+				assert.Empty(t, c.GetWarnings())
+			}
+		})
+	}
+}
+
+func TestPreferredRequestMedia(t *testing.T) {
+	testCases := []struct {
+		name                string
+		preferred           []string
+		expectedContentType string
+		expectedArgs        []string
+		expectedWarning     bool
+	}{
+		{
+			name:                "Prefers multipart",
+			preferred:           []string{"multipart/form-data", "application/json"},
+			expectedContentType: "multipart/form-data",
+			expectedArgs:        []string{},
+		},
+		{
+			name:                "Prefers JSON",
+			preferred:           []string{"application/json"},
+			expectedContentType: "application/json",
+			expectedArgs:        []string{"title"},
+		},
+		{
+			name:                "Falls back when nothing matches",
+			preferred:           []string{"application/xml"},
+			expectedContentType: "application/json",
+			expectedArgs:        []string{"title"},
+			expectedWarning:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/dual-content-type.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{
+				PreferredRequestMedia: tc.preferred,
+			})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+			assert.Len(t, config.Tools, 1)
+
+			tool := config.Tools[0]
+			assert.Equal(t, []models.Header{{Key: "Content-Type", Value: tc.expectedContentType}}, tool.RequestTemplate.Headers)
+
+			argNames := []string{}
+			for _, arg := range tool.Args {
+				argNames = append(argNames, arg.Name)
+			}
+			assert.Equal(t, tc.expectedArgs, argNames)
+
+			if tc.expectedWarning {
+				assert.Len(t, c.GetWarnings(), 1)
+			} else {
+				assert.Empty(t, c.GetWarnings())
+			}
+		})
+	}
+}
+
+func TestPropagateTags(t *testing.T) {
+	testCases := []struct {
+		name           string
+		propagateTags  bool
+		expectedTags   map[string][]string
+		expectedServer []models.Tag
+	}{
+		{
+			name:         "Tags dropped by default",
+			expectedTags: map[string][]string{"getHealth": nil, "listUsers": nil},
+		},
+		{
+			name:          "Tags propagated",
+			propagateTags: true,
+			expectedTags:  map[string][]string{"getHealth": nil, "listUsers": {"users", "admin"}},
+			expectedServer: []models.Tag{
+				{Name: "admin"},
+				{Name: "users", Description: "User account management"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/tagged-operations.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{
+				PropagateTags: tc.propagateTags,
+			})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			tags := make(map[string][]string)
+			for _, tool := range config.Tools {
+				tags[tool.Name] = tool.Tags
+			}
+			assert.Equal(t, tc.expectedTags, tags)
+			assert.Equal(t, tc.expectedServer, config.Server.Tags)
+		})
+	}
+}
+
+func TestOperationServerOverrides(t *testing.T) {
+	p := parser.NewParser()
+	err := p.ParseFile("../../test/server-overrides.json")
+	assert.NoError(t, err)
+
+	c := NewConverter(p, models.ConvertOptions{})
+	config, err := c.Convert()
+	assert.NoError(t, err)
+
+	urls := make(map[string]string)
+	for _, tool := range config.Tools {
+		urls[tool.Name] = tool.RequestTemplate.URL
+	}
+	assert.Equal(t, map[string]string{
+		"getReports": "https://api.example.com/v1/reports",
+		"listFiles":  "https://eu.files.example.com/files",
+		"listUsers":  "https://api.example.com/v1/users",
+		"uploadFile": "https://upload.example.com/files",
+	}, urls)
+
+	assert.Len(t, c.GetWarnings(), 1)
+	assert.Contains(t, c.GetWarnings()[0], "GET /reports")
+}
+
+func TestDuplicateOperationIDs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		strict        bool
+		expectedError bool
+		expectedTools []string
+	}{
+		{
+			name:          "Keeps the first operation with a warning",
+			expectedTools: []string{"createOrder", "getItems"},
+		},
+		{
+			name:          "Strict mode fails",
+			strict:        true,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/duplicate-operation-ids.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{
+				StrictOperationIDs: tc.strict,
+			})
+			config, err := c.Convert()
+			if tc.expectedError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), `"getItems" is used by GET /orders and GET /users`)
+				return
+			}
+			assert.NoError(t, err)
+
+			var toolNames []string
+			for _, tool := range config.Tools {
+				toolNames = append(toolNames, tool.Name)
+			}
+			assert.Equal(t, tc.expectedTools, toolNames)
+			assert.Equal(t, "http://api.example.com/v1/orders", config.Tools[1].RequestTemplate.URL)
+
+			assert.Equal(t, []string{`duplicate operationId "getItems": kept GET /orders, skipped GET /users`}, c.GetWarnings())
+			assert.Equal(t, []models.SkippedOperation{
+				{Path: "/users", Method: "GET", Category: models.SkipError, Reason: `duplicate operationId "getItems"`},
+			}, c.GetSkippedOperations())
+		})
+	}
+}
+
+func TestPathRegexFilters(t *testing.T) {
+	testCases := []struct {
+		name          string
+		include       string
+		exclude       string
+		expectedTools []string
+	}{
+		{
+			name:          "No filters",
+			expectedTools: []string{"getDebugInfo", "getHealth", "listUsersV1", "listUsersV2"},
+		},
+		{
+			name:          "Include versioned paths",
+			include:       `^/v[0-9]+/`,
+			expectedTools: []string{"getDebugInfo", "listUsersV1", "listUsersV2"},
+		},
+		{
+			name:          "Exclude internal paths",
+			exclude:       `/internal/`,
+			expectedTools: []string{"getHealth", "listUsersV1", "listUsersV2"},
+		},
+		{
+			name:          "Exclude wins over include",
+			include:       `^/v[0-9]+/`,
+			exclude:       `^/v2/internal/`,
+			expectedTools: []string{"listUsersV1", "listUsersV2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/versioned-paths.json")
+			assert.NoError(t, err)
+
+			options := models.ConvertOptions{}
+			if tc.include != "" {
+				options.IncludePathRegex = regexp.MustCompile(tc.include)
+			}
+			if tc.exclude != "" {
+				options.ExcludePathRegex = regexp.MustCompile(tc.exclude)
+			}
+
+			c := NewConverter(p, options)
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			var toolNames []string
+			for _, tool := range config.Tools {
+				toolNames = append(toolNames, tool.Name)
+			}
+			assert.Equal(t, tc.expectedTools, toolNames)
+			assert.Len(t, c.GetSkippedOperations(), 4-len(tc.expectedTools))
+		})
+	}
+}
+
+func TestPathGlobFilters(t *testing.T) {
+	testCases := []struct {
+		name             string
+		includeRegex     string
+		includeGlobs     []string
+		excludeGlobs     []string
+		expectedTools    []string
+		expectedWarnings []string
+	}{
+		{
+			name:          "Include single segment glob",
+			includeGlobs:  []string{"/v*/users"},
+			expectedTools: []string{"listUsersV1", "listUsersV2"},
+			expectedWarnings: []string{
+				"path filters selected 2 of 4 operation(s)",
+			},
+		},
+		{
+			name:          "Include multi segment glob",
+			includeGlobs:  []string{"/v2/**"},
+			expectedTools: []string{"getDebugInfo", "listUsersV2"},
+			expectedWarnings: []string{
+				"path filters selected 2 of 4 operation(s)",
+			},
+		},
+		{
+			name:          "Overlapping include globs count each operation once",
+			includeGlobs:  []string{"/v2/**", "/v?/users", "/v2/users"},
+			expectedTools: []string{"getDebugInfo", "listUsersV1", "listUsersV2"},
+			expectedWarnings: []string{
+				"path filters selected 3 of 4 operation(s)",
+			},
+		},
+		{
+			name:          "Exclude wins over an overlapping include",
+			includeGlobs:  []string{"/v2/**"},
+			excludeGlobs:  []string{"/*/internal/**"},
+			expectedTools: []string{"listUsersV2"},
+			expectedWarnings: []string{
+				"path filters selected 1 of 4 operation(s)",
+				"1 operation(s) matched both the include and exclude filters and were excluded: GET /v2/internal/debug",
+			},
+		},
+		{
+			name:          "Regex and glob filters combine",
+			includeRegex:  `^/health$`,
+			includeGlobs:  []string{"/v1/**"},
+			excludeGlobs:  []string{"/health"},
+			expectedTools: []string{"listUsersV1"},
+			expectedWarnings: []string{
+				"path filters selected 1 of 4 operation(s)",
+				"1 operation(s) matched both the include and exclude filters and were excluded: GET /health",
+			},
+		},
+		{
+			name:          "Exclude only",
+			excludeGlobs:  []string{"/**/internal/**"},
+			expectedTools: []string{"getHealth", "listUsersV1", "listUsersV2"},
+			expectedWarnings: []string{
+				"path filters selected 3 of 4 operation(s)",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/versioned-paths.json")
+			assert.NoError(t, err)
+
+			options := models.ConvertOptions{
+				IncludePathGlobs: tc.includeGlobs,
+				ExcludePathGlobs: tc.excludeGlobs,
+			}
+			if tc.includeRegex != "" {
+				options.IncludePathRegex = regexp.MustCompile(tc.includeRegex)
+			}
+
+			c := NewConverter(p, options)
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			var toolNames []string
+			for _, tool := range config.Tools {
+				toolNames = append(toolNames, tool.Name)
+			}
+			assert.Equal(t, tc.expectedTools, toolNames)
+			assert.Len(t, c.GetSkippedOperations(), 4-len(tc.expectedTools))
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+		})
+	}
+}
+
+func TestAnnotateAuth(t *testing.T) {
+	p := parser.NewParser()
+	err := p.ParseFile("../../test/auth-requirements.json")
+	assert.NoError(t, err)
+
+	c := NewConverter(p, models.ConvertOptions{
+		AnnotateAuth: true,
+	})
+	config, err := c.Convert()
+	assert.NoError(t, err)
+
+	auth := make(map[string]*models.ToolAuth)
+	for _, tool := range config.Tools {
+		auth[tool.Name] = tool.Auth
+	}
+	assert.Equal(t, map[string]*models.ToolAuth{
+		"getHealth": {Required: false},
+		"getReports": {Required: true, Requirements: [][]string{
+			{"ApiKeyAuth", "BearerAuth"},
+			{"OAuth"},
+		}},
+		"listUsers": {Required: true, Requirements: [][]string{{"BearerAuth"}}},
+		"search":    {Required: false, Requirements: [][]string{{"ApiKeyAuth"}}},
+	}, auth)
+
+	assert.Equal(t, []string{`security scheme "OAuth" of type "oauth2" can't be represented in the MCP config`}, c.GetWarnings())
+}
+
+func TestAnnotateSource(t *testing.T) {
+	p := parser.NewParser()
+	err := p.ParseFile("../../test/petstore.json")
+	assert.NoError(t, err)
+
+	c := NewConverter(p, models.ConvertOptions{AnnotateSource: true})
+	config, err := c.Convert()
+	assert.NoError(t, err)
+
+	// Every tool points back at the operation it was generated from
+	expected := make(map[string]models.SourceLocation)
+	for path, pathItem := range p.GetPaths() {
+		for method, operation := range pathItem.Operations() {
+			expected[p.GetOperationID(path, method, operation)] = models.SourceLocation{Method: strings.ToUpper(method), Path: path}
+		}
+	}
+	assert.NotEmpty(t, config.Tools)
+	for _, tool := range config.Tools {
+		if assert.NotNil(t, tool.Source, tool.Name) {
+			assert.Equal(t, expected[tool.Name], *tool.Source, tool.Name)
+		}
+	}
+
+	c = NewConverter(p, models.ConvertOptions{})
+	config, err = c.Convert()
+	assert.NoError(t, err)
+	for _, tool := range config.Tools {
+		assert.Nil(t, tool.Source, tool.Name)
+	}
+}
+
+func TestStripExamples(t *testing.T) {
+	p := parser.NewParser()
+	err := p.ParseFile("../../test/examples.json")
+	assert.NoError(t, err)
+
+	c := NewConverter(p, models.ConvertOptions{
+		StripExamples: true,
+	})
+	_, err = c.Convert()
+	assert.NoError(t, err)
+
+	doc := p.GetDocument()
+	user := doc.Components.Schemas["User"].Value
+	assert.Nil(t, user.Example)
+	assert.Nil(t, user.Properties["id"].Value.Example)
+	assert.Nil(t, user.Properties["name"].Value.Example)
+	// The untyped status property is only described by its example
+	assert.Equal(t, "active", user.Properties["status"].Value.Example)
+
+	operation := doc.Paths["/users"].Get
+	assert.Nil(t, operation.Parameters[0].Value.Example)
+	assert.Empty(t, operation.Responses["200"].Value.Content["application/json"].Examples)
+
+	assert.Len(t, c.GetWarnings(), 1)
+	assert.Contains(t, c.GetWarnings()[0], "stripped 5 example(s)")
+}
+
+func TestPromoteExamplesToDefaults(t *testing.T) {
+	testCases := []struct {
+		name             string
+		promote          bool
+		expectedDefaults map[string]interface{}
+		expectedWarnings []string
+	}{
+		{
+			name: "Examples ignored by default",
+			expectedDefaults: map[string]interface{}{
+				"currency": nil, "limit": nil, "page": nil, "sort": nil, "status": nil, "userId": nil,
+			},
+		},
+		{
+			name:    "Examples promoted",
+			promote: true,
+			expectedDefaults: map[string]interface{}{
+				"currency": nil, "limit": float64(20), "page": nil, "sort": "created_at", "status": nil, "userId": nil,
+			},
+			expectedWarnings: []string{"promoted 2 parameter example(s) to defaults"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/parameter-examples.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{
+				PromoteExamplesToDefaults: tc.promote,
+			})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			defaults := make(map[string]interface{})
+			for _, arg := range config.Tools[0].Args {
+				defaults[arg.Name] = arg.Default
+			}
+			assert.Equal(t, tc.expectedDefaults, defaults)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+		})
+	}
+}
+
+func TestOperationOrder(t *testing.T) {
+	testCases := []struct {
+		order         string
+		expectedTools []string
+	}{
+		{order: "", expectedTools: []string{"checkHealth", "createUser", "deleteAccount", "listUsers"}},
+		{order: OrderOperationID, expectedTools: []string{"checkHealth", "createUser", "deleteAccount", "listUsers"}},
+		{order: OrderPath, expectedTools: []string{"deleteAccount", "checkHealth", "listUsers", "createUser"}},
+		{order: OrderMethod, expectedTools: []string{"checkHealth", "listUsers", "createUser", "deleteAccount"}},
+		{order: OrderTag, expectedTools: []string{"deleteAccount", "createUser", "listUsers", "checkHealth"}},
+		{order: OrderSpec, expectedTools: []string{"createUser", "listUsers", "deleteAccount", "checkHealth"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Order %q", tc.order), func(t *testing.T) {
+			// Convert several times to catch map iteration order leaking into the output
+			for i := 0; i < 5; i++ {
+				p := parser.NewParser()
+				err := p.ParseFile("../../test/operation-order.json")
+				assert.NoError(t, err)
+
+				c := NewConverter(p, models.ConvertOptions{
+					OperationOrder: tc.order,
+				})
+				config, err := c.Convert()
+				assert.NoError(t, err)
+
+				var toolNames []string
+				for _, tool := range config.Tools {
+					toolNames = append(toolNames, tool.Name)
+				}
+				assert.Equal(t, tc.expectedTools, toolNames)
+			}
+		})
+	}
+
+	p := parser.NewParser()
+	err := p.ParseFile("../../test/operation-order.json")
+	assert.NoError(t, err)
+	_, err = NewConverter(p, models.ConvertOptions{OperationOrder: "random"}).Convert()
+	assert.Error(t, err)
+}
+
+func TestForceRequired(t *testing.T) {
+	p := parser.NewParser()
+	err := p.ParseFile("../../test/parameter-examples.json")
+	assert.NoError(t, err)
+
+	c := NewConverter(p, models.ConvertOptions{
+		ForceRequired: map[string][]string{
+			"listOrders": {"limit", "sort", "cursor"},
+			"getOrder":   {"orderId"},
+		},
+	})
+	config, err := c.Convert()
+	assert.NoError(t, err)
+
+	required := make(map[string]bool)
+	for _, arg := range config.Tools[0].Args {
+		required[arg.Name] = arg.Required
+	}
+	assert.Equal(t, map[string]bool{
+		"currency": true,
+		"limit":    true,
+		"page":     false,
+		"sort":     true,
+		"status":   false,
+		"userId":   true,
+	}, required)
+
+	assert.Equal(t, []string{
+		`force_required: operation "listOrders" has no parameter "cursor"`,
+		`force_required: no operation with operationId "getOrder"`,
+	}, c.GetWarnings())
+}
+
+func TestTransportOptions(t *testing.T) {
+	http2 := true
+	maxRedirects := 3
+
+	p := parser.NewParser()
+	err := p.ParseFile("../../test/parameter-examples.json")
+	assert.NoError(t, err)
+
+	withoutTransport, err := NewConverter(p, models.ConvertOptions{}).Convert()
+	assert.NoError(t, err)
+
+	c := NewConverter(p, models.ConvertOptions{
+		Transport: models.TransportOptions{
+			HTTP2:        &http2,
+			MaxRedirects: &maxRedirects,
+		},
+	})
+	config, err := c.Convert()
+	assert.NoError(t, err)
+
+	// The request template has no transport settings, so the config is unchanged
+	assert.Equal(t, withoutTransport, config)
+	assert.Equal(t, []string{
+		"request_http2 can't be represented in the MCP request template and was ignored",
+		"request_max_redirects can't be represented in the MCP request template and was ignored",
+	}, c.GetWarnings())
+}
+
+func TestEmitSpecInfo(t *testing.T) {
+	data, err := os.ReadFile("../../test/petstore.json")
+	assert.NoError(t, err)
+	sum := sha256.Sum256(data)
+
+	p := parser.NewParser()
+	assert.NoError(t, p.Parse(data))
+
+	config, err := NewConverter(p, models.ConvertOptions{ServerName: "petstore"}).Convert()
+	assert.NoError(t, err)
+	assert.Empty(t, config.ID)
+	assert.Nil(t, config.Info)
+
+	config, err = NewConverter(p, models.ConvertOptions{ServerName: "petstore", EmitSpecInfo: true}).Convert()
+	assert.NoError(t, err)
+	assert.Equal(t, "urn:openapi:petstore-api:1.0.0", config.ID)
+	assert.Equal(t, &models.SpecInfo{
+		Title:          "Petstore API",
+		Version:        "1.0.0",
+		OpenAPIVersion: "3.0.0",
+		SourceSHA256:   hex.EncodeToString(sum[:]),
+	}, config.Info)
+
+	out, err := yaml.Marshal(config)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(out), "$id: urn:openapi:petstore-api:1.0.0\ninfo:\n"))
+
+	// JSON configs use the same names
+	out, err = json.MarshalIndent(config, "", "  ")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(out), "{\n  \"$id\": \"urn:openapi:petstore-api:1.0.0\",\n  \"info\": {\n    \"title\": \"Petstore API\","), string(out))
+	assert.Contains(t, string(out), `"sourceSha256": "`+hex.EncodeToString(sum[:])+`"`)
+	var roundTrip models.MCPConfig
+	assert.NoError(t, json.Unmarshal(out, &roundTrip))
+	assert.Equal(t, config.ID, roundTrip.ID)
+	assert.Equal(t, config.Info, roundTrip.Info)
+
+	// Without emit_spec_info, neither is written
+	config, err = NewConverter(p, models.ConvertOptions{ServerName: "petstore"}).Convert()
+	assert.NoError(t, err)
+	out, err = json.Marshal(config)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), `"$id"`)
+	assert.NotContains(t, string(out), `"info"`)
+}
+
+// mapToolCache is an unbounded models.ToolCache for tests
+type mapToolCache map[string]models.CachedTool
+
+func (m mapToolCache) Get(key string) (models.CachedTool, bool) {
+	tool, ok := m[key]
+	return tool, ok
+}
+
+func (m mapToolCache) Add(key string, tool models.CachedTool) {
+	m[key] = tool
+}
+
+func TestToolCache(t *testing.T) {
+	p := parser.NewParser()
+	err := p.ParseFile("../../test/parameter-examples.json")
+	assert.NoError(t, err)
+
+	options := models.ConvertOptions{PromoteExamplesToDefaults: true}
+	uncached := NewConverter(p, options)
+	expected, err := uncached.Convert()
+	assert.NoError(t, err)
+
+	options.ToolCache = mapToolCache{}
+	first := NewConverter(p, options)
+	_, err = first.Convert()
+	assert.NoError(t, err)
+	hits, misses := first.GetCacheStats()
+	assert.Equal(t, 0, hits)
+	assert.Equal(t, 1, misses)
+
+	// A second conversion reuses the tool and replays its side effects
+	second := NewConverter(p, options)
+	config, err := second.Convert()
+	assert.NoError(t, err)
+	hits, misses = second.GetCacheStats()
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 0, misses)
+	assert.Equal(t, expected, config)
+	assert.ElementsMatch(t, uncached.GetWarnings(), second.GetWarnings())
+
+	// Changing an option that affects tools misses the cache
+	options.ToolNamePrefix = "api_"
+	prefixed := NewConverter(p, options)
+	_, err = prefixed.Convert()
+	assert.NoError(t, err)
+	hits, misses = prefixed.GetCacheStats()
+	assert.Equal(t, 0, hits)
+	assert.Equal(t, 1, misses)
+}
+
+func TestToolCacheChangedOperation(t *testing.T) {
+	p := parser.NewParser()
+	err := p.ParseFile("../../test/petstore.json")
+	assert.NoError(t, err)
+
+	options := models.ConvertOptions{ServerName: "petstore", ToolCache: mapToolCache{}}
+	_, err = NewConverter(p, options).Convert()
+	assert.NoError(t, err)
+
+	// Only the edited operation is converted again
+	p.GetPaths()["/pets"].Get.Summary = "List every pet"
+	c := NewConverter(p, options)
+	config, err := c.Convert()
+	assert.NoError(t, err)
+	hits, misses := c.GetCacheStats()
+	assert.Equal(t, 2, hits)
+	assert.Equal(t, 1, misses)
+
+	expected, err := NewConverter(p, models.ConvertOptions{ServerName: "petstore"}).Convert()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, config)
+}
+
+func TestParamNameCase(t *testing.T) {
+	testCases := []struct {
+		name            string
+		paramNameCase   string
+		expectedArgs    []string
+		expectedMapping map[string]string
+	}{
+		{
+			name:          "camel",
+			paramNameCase: CaseCamel,
+			expectedArgs:  []string{"filterTag", "filter_tag", "pageSize", "sortOrder", "xRequestId"},
+			expectedMapping: map[string]string{
+				"pageSize":   "page_size",
+				"xRequestId": "X-Request-Id",
+			},
+		},
+		{
+			name:          "snake",
+			paramNameCase: CaseSnake,
+			expectedArgs:  []string{"filterTag", "filter_tag", "page_size", "sort_order", "x_request_id"},
+			expectedMapping: map[string]string{
+				"sort_order":   "sortOrder",
+				"x_request_id": "X-Request-Id",
+			},
+		},
+		{
+			name:          "kebab",
+			paramNameCase: CaseKebab,
+			expectedArgs:  []string{"filterTag", "filter_tag", "page-size", "sort-order", "x-request-id"},
+			expectedMapping: map[string]string{
+				"page-size":    "page_size",
+				"sort-order":   "sortOrder",
+				"x-request-id": "X-Request-Id",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/mixed-case-params.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{ParamNameCase: tc.paramNameCase})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+			assert.Len(t, config.Tools, 1)
+
+			var names []string
+			for _, arg := range config.Tools[0].Args {
+				names = append(names, arg.Name)
+			}
+			assert.Equal(t, tc.expectedArgs, names)
+			assert.Equal(t, tc.expectedMapping, config.Tools[0].ParamMapping)
+			assert.Contains(t, c.GetWarnings(), fmt.Sprintf("param_name_case: searchItems parameters filter_tag, filterTag all become %q, keeping their original names", convertCase("filter_tag", tc.paramNameCase)))
+		})
+	}
+
+	t.Run("unset", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/mixed-case-params.json")
+		assert.NoError(t, err)
+
+		config, err := NewConverter(p, models.ConvertOptions{}).Convert()
+		assert.NoError(t, err)
+		assert.Nil(t, config.Tools[0].ParamMapping)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/mixed-case-params.json")
+		assert.NoError(t, err)
+
+		_, err = NewConverter(p, models.ConvertOptions{ParamNameCase: "pascal"}).Convert()
+		assert.Error(t, err)
+	})
+}
+
+func TestConvertCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"userID":      "userId",
+		"user_id":     "userId",
+		"User-Id":     "userId",
+		"HTTPServer":  "httpServer",
+		"api.version": "apiVersion",
+		"v2Name":      "v2Name",
+	} {
+		assert.Equal(t, expected, convertCase(name, CaseCamel), name)
+	}
+}
+
+func TestFormatMapping(t *testing.T) {
+	uuidPattern := DefaultFormatMapping["uuid"].Pattern
+
+	// argConstraints returns "format|pattern" of every argument by tool and name
+	argConstraints := func(config *models.MCPConfig) map[string]string {
+		constraints := make(map[string]string)
+		for _, tool := range config.Tools {
+			for _, arg := range tool.Args {
+				constraints[tool.Name+"."+arg.Name] = arg.Format + "|" + arg.Pattern
+			}
+		}
+		return constraints
+	}
+
+	t.Run("Default mapping", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/formats.json")
+		assert.NoError(t, err)
+
+		c := NewConverter(p, models.ConvertOptions{FormatMapping: DefaultFormatMapping})
+		config, err := c.Convert()
+		assert.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"createEvent.organizerId": "|" + uuidPattern,
+			"createEvent.startsAt":    "date-time|",
+			"getEvent.color":          "|",
+			"getEvent.eventId":        "|" + uuidPattern,
+			"getEvent.limit":          "|",
+			"getEvent.since":          "date-time|",
+		}, argConstraints(config))
+		assert.Equal(t, []string{`format "hex-color" has no mapping and is left out of the tool arguments`}, c.GetWarnings())
+	})
+
+	t.Run("Custom mapping", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/formats.json")
+		assert.NoError(t, err)
+
+		c := NewConverter(p, models.ConvertOptions{FormatMapping: map[string]models.FormatConstraint{
+			"hex-color": {Pattern: "^#[0-9a-f]{6}$"},
+		}})
+		config, err := c.Convert()
+		assert.NoError(t, err)
+
+		constraints := argConstraints(config)
+		assert.Equal(t, "|^#[0-9a-f]{6}$", constraints["getEvent.color"])
+		assert.Equal(t, "|", constraints["getEvent.eventId"])
+		assert.ElementsMatch(t, []string{
+			`format "uuid" has no mapping and is left out of the tool arguments`,
+			`format "date-time" has no mapping and is left out of the tool arguments`,
+		}, c.GetWarnings())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/formats.json")
+		assert.NoError(t, err)
+
+		c := NewConverter(p, models.ConvertOptions{})
+		config, err := c.Convert()
+		assert.NoError(t, err)
+
+		for name, constraint := range argConstraints(config) {
+			assert.Equal(t, "|", constraint, name)
+		}
+		assert.Empty(t, c.GetWarnings())
+	})
+}
+
+func TestEnumDescriptions(t *testing.T) {
+	// argDescriptions returns the description of every argument by tool and name
+	argDescriptions := func(config *models.MCPConfig) map[string]string {
+		descriptions := make(map[string]string)
+		for _, tool := range config.Tools {
+			for _, arg := range tool.Args {
+				descriptions[tool.Name+"."+arg.Name] = arg.Description
+			}
+		}
+		return descriptions
+	}
+
+	t.Run("Enabled", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/enum-descriptions.json")
+		assert.NoError(t, err)
+
+		c := NewConverter(p, models.ConvertOptions{IncludeEnumDescriptions: true})
+		config, err := c.Convert()
+		assert.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"listOrders.status":    "Order status\n\nAllowed values:\n- pending: Awaiting payment\n- shipped: Handed to the carrier\n- cancelled: Cancelled by the customer",
+			"listOrders.sort":      "",
+			"createOrder.priority": "Allowed values:\n- 1: Standard\n- 2: Express",
+		}, argDescriptions(config))
+		assert.Equal(t, []string{`x-enum-descriptions of "sort" has 1 entries for 2 enum values and was ignored`}, c.GetWarnings())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/enum-descriptions.json")
+		assert.NoError(t, err)
+
+		c := NewConverter(p, models.ConvertOptions{})
+		config, err := c.Convert()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Order status", argDescriptions(config)["listOrders.status"])
+		assert.Empty(t, c.GetWarnings())
+	})
+}
+
+func TestToolNameValidation(t *testing.T) {
+	t.Run("Sanitized", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/invalid-tool-names.json")
+		assert.NoError(t, err)
+
+		c := NewConverter(p, models.ConvertOptions{})
+		config, err := c.Convert()
+		assert.NoError(t, err)
+
+		var names []string
+		for _, tool := range config.Tools {
+			names = append(names, tool.Name)
+		}
+		assert.ElementsMatch(t, []string{"accounts_list", "accounts_list_2", "create_account"}, names)
+		assert.ElementsMatch(t, []string{
+			`tool name "accounts.list" is not a valid MCP identifier, renamed to "accounts_list_2"`,
+			`tool name "create account" is not a valid MCP identifier, renamed to "create_account"`,
+		}, c.GetWarnings())
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/invalid-tool-names.json")
+		assert.NoError(t, err)
+
+		c := NewConverter(p, models.ConvertOptions{StrictNames: true})
+		_, err = c.Convert()
+		assert.EqualError(t, err, `tool names are not valid MCP identifiers (letters, digits, _ and -, at most 64 characters): "accounts.list", "create account"`)
+	})
+}
+
+func TestSanitizeToolName(t *testing.T) {
+	long := strings.Repeat("a", 70)
+	tests := []struct {
+		name  string
+		taken map[string]bool
+		want  string
+	}{
+		{"get.pet", nil, "get_pet"},
+		{" list  pets! ", nil, "list_pets"},
+		{"...", nil, "tool"},
+		{long, nil, strings.Repeat("a", 64)},
+		{long, map[string]bool{strings.Repeat("a", 64): true}, strings.Repeat("a", 62) + "_2"},
+		{"get.pet", map[string]bool{"get_pet": true, "get_pet_2": true}, "get_pet_3"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sanitizeToolName(tt.name, tt.taken), tt.name)
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	t.Run("Enabled", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/response-headers.json")
+		assert.NoError(t, err)
+
+		c := NewConverter(p, models.ConvertOptions{IncludeResponseHeaders: true})
+		config, err := c.Convert()
+		assert.NoError(t, err)
+
+		tools := make(map[string]models.Tool)
+		for _, tool := range config.Tools {
+			tools[tool.Name] = tool
+		}
+
+		listMessages := tools["listMessages"]
+		assert.Equal(t, []models.ResponseHeader{
+			{Name: "X-Next-Cursor", Type: "string", Description: "Cursor of the next page"},
+			{Name: "X-RateLimit-Remaining", Type: "integer", Description: "Requests left in the current window"},
+		}, listMessages.ResponseHeaders)
+		assert.True(t, strings.HasSuffix(listMessages.Description, "\n\nResponse headers:\n- X-Next-Cursor (string): Cursor of the next page\n- X-RateLimit-Remaining (integer): Requests left in the current window"))
+
+		// Without 2xx headers the default response is used
+		sendMessage := tools["sendMessage"]
+		assert.Equal(t, []models.ResponseHeader{
+			{Name: "X-Request-Id", Type: "string", Description: "ID to quote when reporting the error"},
+		}, sendMessage.ResponseHeaders)
+
+		assert.Equal(t, []string{"sendMessage mentions undocumented response header(s) Location"}, c.GetWarnings())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/response-headers.json")
+		assert.NoError(t, err)
+
+		c := NewConverter(p, models.ConvertOptions{})
+		config, err := c.Convert()
+		assert.NoError(t, err)
+
+		for _, tool := range config.Tools {
+			assert.Nil(t, tool.ResponseHeaders, tool.Name)
+			assert.NotContains(t, tool.Description, "Response headers:", tool.Name)
+		}
+		assert.Empty(t, c.GetWarnings())
+	})
+}
+
+func TestDefaultRequestContentType(t *testing.T) {
+	// contentTypes returns the Content-Type header of every tool by name
+	contentTypes := func(config *models.MCPConfig) map[string]string {
+		types := make(map[string]string)
+		for _, tool := range config.Tools {
+			for _, header := range tool.RequestTemplate.Headers {
+				if header.Key == "Content-Type" {
+					types[tool.Name] = header.Value
+				}
+			}
+		}
+		return types
+	}
+
+	tests := []struct {
+		name         string
+		defaultType  string
+		expectedType string
+	}{
+		{"Built-in default", "", "application/json"},
+		{"Configured default", "application/x-www-form-urlencoded", "application/x-www-form-urlencoded"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/request-body-without-content.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{DefaultRequestContentType: tc.defaultType})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			assert.Equal(t, map[string]string{
+				"startExport": "application/xml",
+				"startImport": tc.expectedType,
+			}, contentTypes(config))
+			assert.Equal(t, []string{"POST /imports declares a request body without a content type, using " + tc.expectedType}, c.GetWarnings())
+		})
+	}
+}
+
+func TestPathPrefix(t *testing.T) {
+	tests := []struct {
+		name             string
+		options          models.ConvertOptions
+		expectedURLs     map[string]string
+		expectedWarnings []string
+		expectedError    string
+	}{
+		{
+			name:    "Prepend",
+			options: models.ConvertOptions{PathPrefix: "/api/v2/"},
+			expectedURLs: map[string]string{
+				"getDebugInfo": "http://api.example.com/api/v2/v2/internal/debug",
+				"getHealth":    "http://api.example.com/api/v2/health",
+				"listUsersV1":  "http://api.example.com/api/v2/v1/users",
+				"listUsersV2":  "http://api.example.com/api/v2/v2/users",
+			},
+		},
+		{
+			name:    "Strip",
+			options: models.ConvertOptions{StripPathPrefix: "/v2"},
+			expectedURLs: map[string]string{
+				"getDebugInfo": "http://api.example.com/internal/debug",
+				"getHealth":    "http://api.example.com/health",
+				"listUsersV1":  "http://api.example.com/v1/users",
+				"listUsersV2":  "http://api.example.com/users",
+			},
+			expectedWarnings: []string{
+				`GET /health does not start with strip path prefix "/v2" and was left unchanged`,
+				`GET /v1/users does not start with strip path prefix "/v2" and was left unchanged`,
+			},
+		},
+		{
+			name:    "Strip then prepend",
+			options: models.ConvertOptions{StripPathPrefix: "/v2", PathPrefix: "/api/v2"},
+			expectedURLs: map[string]string{
+				"getDebugInfo": "http://api.example.com/api/v2/internal/debug",
+				"getHealth":    "http://api.example.com/api/v2/health",
+				"listUsersV1":  "http://api.example.com/api/v2/v1/users",
+				"listUsersV2":  "http://api.example.com/api/v2/users",
+			},
+			expectedWarnings: []string{
+				`GET /health does not start with strip path prefix "/v2" and was left unchanged`,
+				`GET /v1/users does not start with strip path prefix "/v2" and was left unchanged`,
+			},
+		},
+		{
+			name:          "Relative prefix",
+			options:       models.ConvertOptions{PathPrefix: "api"},
+			expectedError: `path prefix "api" must start with /`,
+		},
+		{
+			name:          "Relative strip prefix",
+			options:       models.ConvertOptions{StripPathPrefix: "v2"},
+			expectedError: `strip path prefix "v2" must start with /`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/versioned-paths.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, tc.options)
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			urls := make(map[string]string)
+			for _, tool := range config.Tools {
+				urls[tool.Name] = tool.RequestTemplate.URL
+			}
+			assert.Equal(t, tc.expectedURLs, urls)
+			assert.ElementsMatch(t, tc.expectedWarnings, c.GetWarnings())
+		})
+	}
+}
+
+func TestNullHandling(t *testing.T) {
+	tests := []struct {
+		name             string
+		specPath         string
+		nullHandling     string
+		dialect          string
+		expectedYAML     []string
+		expectedJSON     []string
+		unexpectedJSON   []string
+		expectedWarnings []string
+		expectedError    string
+	}{
+		{
+			name:     "OpenAPI 3.0 defaults to nullable",
+			specPath: "../../test/nullable-30.json",
+			expectedYAML: []string{
+				"type: string\n          nullable: true",
+				"unit:\n                nullable: true\n                type: string",
+			},
+			expectedWarnings: []string{
+				`nullability of "manager" couldn't be determined from its oneOf/anyOf and was left out`,
+			},
+		},
+		{
+			name:         "OpenAPI 3.0 as type arrays",
+			specPath:     "../../test/nullable-30.json",
+			nullHandling: models.NullHandlingTypeArray,
+			expectedYAML: []string{
+				`type: [string, "null"]`,
+				"unit:\n                type:\n                    - string\n                    - \"null\"",
+			},
+			expectedJSON: []string{
+				`"Name":"team","Description":"Team filter","Type":["string","null"],"Required":false`,
+				`"unit":{"type":["string","null"]}`,
+			},
+			unexpectedJSON: []string{`"Nullable":true`},
+			expectedWarnings: []string{
+				`nullability of "manager" couldn't be determined from its oneOf/anyOf and was left out`,
+			},
+		},
+		{
+			name:     "OpenAPI 3.1 defaults to type arrays",
+			specPath: "../../test/nullable-31.json",
+			expectedYAML: []string{
+				`type: [string, "null"]`,
+				"unit:\n                type:\n                    - string\n                    - \"null\"",
+			},
+			expectedJSON: []string{
+				`"Name":"team","Description":"Team filter","Type":["string","null"],"Required":false`,
+			},
+			expectedWarnings: []string{
+				"schema at #/paths/~1users/post/requestBody/content/application~1json/schema/properties/externalId (type [string, integer, null]) has several non-null types and was left untyped",
+				`nullable "externalId" has no type to add null to, marked nullable instead`,
+			},
+		},
+		{
+			name:         "OpenAPI 3.1 as nullable",
+			specPath:     "../../test/nullable-31.json",
+			nullHandling: models.NullHandlingNullable,
+			expectedYAML: []string{
+				"type: string\n          nullable: true",
+				"unit:\n                nullable: true\n                type: string",
+			},
+			expectedWarnings: []string{
+				"schema at #/paths/~1users/post/requestBody/content/application~1json/schema/properties/externalId (type [string, integer, null]) has several non-null types and was left untyped",
+			},
+		},
+		{
+			name:     "OpenAPI 3.1 with the 3.0 schema dialect defaults to nullable",
+			specPath: "../../test/nullable-31.json",
+			dialect:  "https://spec.openapis.org/oas/3.0/schema/2021-09-28",
+			expectedYAML: []string{
+				"type: string\n          nullable: true",
+			},
+			expectedJSON: []string{
+				`"Name":"team","Description":"Team filter","Type":"string","Nullable":true`,
+			},
+			expectedWarnings: []string{
+				"schema at #/paths/~1users/post/requestBody/content/application~1json/schema/properties/externalId (type [string, integer, null]) has several non-null types and was left untyped",
+			},
+		},
+		{
+			name:          "Unsupported null handling",
+			specPath:      "../../test/nullable-30.json",
+			nullHandling:  "optional",
+			expectedError: `unsupported null handling "optional", must be one of: nullable, type-array`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			if tc.dialect != "" {
+				data, err := os.ReadFile(tc.specPath)
+				assert.NoError(t, err)
+				var doc map[string]interface{}
+				assert.NoError(t, json.Unmarshal(data, &doc))
+				doc["jsonSchemaDialect"] = tc.dialect
+				data, err = json.Marshal(doc)
+				assert.NoError(t, err)
+				assert.NoError(t, p.Parse(data))
+			} else {
+				err := p.ParseFile(tc.specPath)
+				assert.NoError(t, err)
+			}
+
+			c := NewConverter(p, models.ConvertOptions{NullHandling: tc.nullHandling})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectedWarnings, c.GetWarnings())
+
+			data, err := yaml.Marshal(config)
+			assert.NoError(t, err)
+			for _, expected := range tc.expectedYAML {
+				assert.Contains(t, string(data), expected)
+			}
+
+			jsonData, err := json.Marshal(config)
+			assert.NoError(t, err)
+			for _, expected := range tc.expectedJSON {
+				assert.Contains(t, string(jsonData), expected)
+			}
+			for _, unexpected := range tc.unexpectedJSON {
+				assert.NotContains(t, string(jsonData), unexpected)
+			}
+
+			var roundTrip, jsonRoundTrip models.MCPConfig
+			assert.NoError(t, yaml.Unmarshal(data, &roundTrip))
+			assert.NoError(t, json.Unmarshal(jsonData, &jsonRoundTrip))
+			for i, tool := range roundTrip.Tools {
+				assert.Equal(t, tool.Args, jsonRoundTrip.Tools[i].Args, tool.Name)
+			}
+			for _, tool := range roundTrip.Tools {
+				for _, arg := range tool.Args {
+					switch arg.Name {
+					case "team", "nickname":
+						assert.True(t, arg.Nullable, arg.Name)
+						assert.Equal(t, "string", arg.Type, arg.Name)
+					case "limit", "name":
+						assert.False(t, arg.Nullable, arg.Name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestArrayStyle(t *testing.T) {
+	deepObjectWarning := `GET /items/{ids} parameter "filter" uses array style "deepObject", which the request template can't represent; the MCP server's default serialization is used`
+
+	tests := []struct {
+		name             string
+		defaultStyle     string
+		expectedStyles   map[string]string
+		expectedWarnings []string
+		expectedError    string
+	}{
+		{
+			name: "Styles from the spec",
+			expectedStyles: map[string]string{
+				"ids":     "",
+				"tags":    "",
+				"colors":  ArrayStyleCSV,
+				"sizes":   ArrayStyleMulti,
+				"brands":  ArrayStylePipe,
+				"words":   ArrayStyleSpace,
+				"X-Trace": ArrayStyleCSV,
+				"filter":  "",
+				"limit":   "",
+			},
+			expectedWarnings: []string{deepObjectWarning},
+		},
+		{
+			name:         "Default for parameters without a style",
+			defaultStyle: ArrayStyleCSV,
+			expectedStyles: map[string]string{
+				"ids":     ArrayStyleCSV,
+				"tags":    ArrayStyleCSV,
+				"colors":  ArrayStyleCSV,
+				"sizes":   ArrayStyleMulti,
+				"brands":  ArrayStylePipe,
+				"words":   ArrayStyleSpace,
+				"X-Trace": ArrayStyleCSV,
+				"filter":  "",
+				"limit":   "",
+			},
+			expectedWarnings: []string{deepObjectWarning},
+		},
+		{
+			name:          "Unsupported default",
+			defaultStyle:  "tsv",
+			expectedError: `unsupported default array style "tsv", must be one of: csv, multi, pipe, space`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/array-params.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{DefaultArrayStyle: tc.defaultStyle})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectedWarnings, c.GetWarnings())
+
+			styles := make(map[string]string)
+			for _, arg := range config.Tools[0].Args {
+				styles[arg.Name] = arg.ArrayStyle
+			}
+			assert.Equal(t, tc.expectedStyles, styles)
+
+			data, err := yaml.Marshal(config)
+			assert.NoError(t, err)
+			assert.Contains(t, string(data), "name: colors\n          description: \"\"\n          type: array\n          items:\n            type: string\n          position: query\n          arrayStyle: csv\n")
+		})
+	}
+}
+
+func TestRefStrategy(t *testing.T) {
+	tests := []struct {
+		name               string
+		refStrategy        string
+		expectedRefs       map[string]string
+		expectedComponents []string
+		expectedError      string
+	}{
+		{
+			name:        "Inline by default",
+			refStrategy: "",
+			expectedRefs: map[string]string{
+				"billingAddress": "",
+				"address":        "",
+				"manager":        "",
+				"near":           "",
+			},
+		},
+		{
+			name:        "Shared components",
+			refStrategy: RefStrategySharedComponents,
+			expectedRefs: map[string]string{
+				"billingAddress": "#/components/schemas/Address",
+				"address":        "#/components/schemas/Address",
+				"manager":        "#/components/schemas/Person",
+				"near":           "#/components/schemas/Address",
+			},
+			expectedComponents: []string{"Address", "Coordinates", "Person"},
+		},
+		{
+			name:          "Unsupported strategy",
+			refStrategy:   "external",
+			expectedError: `unsupported ref strategy "external", must be one of: inline, shared-components`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/shared-schemas.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{RefStrategy: tc.refStrategy})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			args := make(map[string]models.Arg)
+			for _, tool := range config.Tools {
+				for _, arg := range tool.Args {
+					args[arg.Name] = arg
+				}
+			}
+			for name, ref := range tc.expectedRefs {
+				assert.Equal(t, ref, args[name].Ref, name)
+				if ref != "" {
+					assert.Nil(t, args[name].Properties, name)
+				} else {
+					assert.NotEmpty(t, args[name].Properties, name)
+				}
+			}
+
+			if tc.expectedComponents == nil {
+				assert.Nil(t, config.Components)
+				assert.Empty(t, c.GetWarnings())
+				return
+			}
+			names := make([]string, 0, len(config.Components.Schemas))
+			for name := range config.Components.Schemas {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tc.expectedComponents, names)
+
+			shared := refSchema("#/components/schemas/Address")
+			assert.Equal(t, shared, args["shippingAddresses"].Items)
+			assert.Equal(t, shared, args["contact"].Properties["postalAddress"])
+			assert.Equal(t, refSchema("#/components/schemas/Coordinates"), config.Components.Schemas["Address"]["properties"].(map[string]interface{})["geo"])
+			assert.Equal(t, []interface{}{"street", "city"}, config.Components.Schemas["Address"]["required"])
+
+			assert.Len(t, c.GetWarnings(), 1)
+			assert.Regexp(t, `^shared components: config is \d+ bytes against \d+ bytes with inlined schemas \([+-]\d+ bytes\)$`, c.GetWarnings()[0])
+
+			data, err := yaml.Marshal(config)
+			assert.NoError(t, err)
+			assert.Contains(t, string(data), "$ref: '#/components/schemas/Address'")
+			var roundTrip models.MCPConfig
+			assert.NoError(t, yaml.Unmarshal(data, &roundTrip))
+			assert.Equal(t, config.Components, roundTrip.Components)
+		})
+	}
+}
+
+func TestResponseSizeHints(t *testing.T) {
+	invalidWarning := `x-response-size of operation "getRawReport" was ignored: response size hint must be a positive number of bytes or "paginate"`
+
+	tests := []struct {
+		name             string
+		hints            map[string]models.ResponseSizeHint
+		expectedHints    map[string]*models.ResponseSizeHint
+		expectedWarnings []string
+	}{
+		{
+			name: "From the x-response-size extension",
+			expectedHints: map[string]*models.ResponseSizeHint{
+				"listReports":  {Paginate: true},
+				"getReport":    {MaxBytes: 65536},
+				"getRawReport": nil,
+				"getStatus":    nil,
+			},
+			expectedWarnings: []string{invalidWarning},
+		},
+		{
+			name: "Options override the extension",
+			hints: map[string]models.ResponseSizeHint{
+				"getReport":    {Paginate: true},
+				"getRawReport": {MaxBytes: 1024},
+				"getStatus":    {MaxBytes: 512},
+				"listInvoices": {MaxBytes: 2048},
+			},
+			expectedHints: map[string]*models.ResponseSizeHint{
+				"listReports":  {Paginate: true},
+				"getReport":    {Paginate: true},
+				"getRawReport": {MaxBytes: 1024},
+				"getStatus":    {MaxBytes: 512},
+			},
+			expectedWarnings: []string{
+				`response_size_hint: no operation with operationId "listInvoices"`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/response-size.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{ResponseSizeHints: tc.hints})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectedWarnings, c.GetWarnings())
+
+			hints := make(map[string]*models.ResponseSizeHint)
+			for _, tool := range config.Tools {
+				hints[tool.Name] = tool.ResponseSize
+			}
+			assert.Equal(t, tc.expectedHints, hints)
+		})
+	}
+}
+
+func TestResponseSizeHintJSON(t *testing.T) {
+	var hints map[string]models.ResponseSizeHint
+	assert.NoError(t, json.Unmarshal([]byte(`{"a": 4096, "b": "paginate"}`), &hints))
+	assert.Equal(t, map[string]models.ResponseSizeHint{"a": {MaxBytes: 4096}, "b": {Paginate: true}}, hints)
+
+	// Written like the YAML config, which is read back too
+	data, err := json.Marshal(hints)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a": {"maxBytes": 4096}, "b": {"paginate": true}}`, string(data))
+	var roundTrip map[string]models.ResponseSizeHint
+	assert.NoError(t, json.Unmarshal(data, &roundTrip))
+	assert.Equal(t, hints, roundTrip)
+
+	yamlData, err := yaml.Marshal(hints)
+	assert.NoError(t, err)
+	assert.Equal(t, "a:\n    maxBytes: 4096\nb:\n    paginate: true\n", string(yamlData))
+
+	for _, invalid := range []string{`0`, `-1`, `1.5`, `"all"`, `true`, `{}`, `{"maxBytes": 0}`, `{"maxBytes": 10, "paginate": true}`, `{"max_bytes": 10}`} {
+		var hint models.ResponseSizeHint
+		assert.Error(t, json.Unmarshal([]byte(invalid), &hint), invalid)
+	}
+}
+
+func TestDescriptionSource(t *testing.T) {
+	tests := []struct {
+		name                 string
+		source               string
+		expectedDescriptions map[string]string
+		expectedError        string
+	}{
+		{
+			name:   "Default joins both",
+			source: "",
+			expectedDescriptions: map[string]string{
+				"listOrders":  "List orders - Returns the orders of the current account, newest first.",
+				"getOrder":    "Get an order",
+				"cancelOrder": "Cancels an order that has not shipped yet.",
+			},
+		},
+		{
+			name:   "Both",
+			source: DescriptionFromBoth,
+			expectedDescriptions: map[string]string{
+				"listOrders":  "List orders - Returns the orders of the current account, newest first.",
+				"getOrder":    "Get an order",
+				"cancelOrder": "Cancels an order that has not shipped yet.",
+			},
+		},
+		{
+			name:   "Description only",
+			source: DescriptionFromDescription,
+			expectedDescriptions: map[string]string{
+				"listOrders":  "Returns the orders of the current account, newest first.",
+				"getOrder":    "",
+				"cancelOrder": "Cancels an order that has not shipped yet.",
+			},
+		},
+		{
+			name:   "Summary only",
+			source: DescriptionFromSummary,
+			expectedDescriptions: map[string]string{
+				"listOrders":  "List orders",
+				"getOrder":    "Get an order",
+				"cancelOrder": "",
+			},
+		},
+		{
+			name:   "Summary then description",
+			source: DescriptionFromSummaryThenDescription,
+			expectedDescriptions: map[string]string{
+				"listOrders":  "List orders",
+				"getOrder":    "Get an order",
+				"cancelOrder": "Cancels an order that has not shipped yet.",
+			},
+		},
+		{
+			name:          "Unsupported source",
+			source:        "title",
+			expectedError: `unsupported description source "title", must be one of: description, summary, both, summary_then_description`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/descriptions.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{DescriptionSource: tc.source})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			descriptions := make(map[string]string)
+			for _, tool := range config.Tools {
+				descriptions[tool.Name] = tool.Description
+			}
+			assert.Equal(t, tc.expectedDescriptions, descriptions)
+		})
+	}
+}
+
+func TestNumericConstraints(t *testing.T) {
+	number := func(value float64) *float64 { return &value }
+
+	tests := []struct {
+		name               string
+		strict             bool
+		expectedArgs       map[string]models.Arg
+		expectedProperties map[string]interface{}
+		expectedWarnings   []string
+	}{
+		{
+			name:   "Constraints are kept as written",
+			strict: false,
+			expectedArgs: map[string]models.Arg{
+				"limit":     {Type: "integer", Minimum: number(1), Maximum: number(100)},
+				"page":      {Minimum: number(0.5), ExclusiveMaximum: number(50)},
+				"minRating": {Type: "number", ExclusiveMinimum: number(0), Maximum: number(5), MultipleOf: number(0.5)},
+				"nights":    {Type: "integer", Maximum: number(30.5), MultipleOf: number(0.5)},
+				"from":      {Type: "string", Format: "date"},
+				"checkIn":   {Type: "string", Format: "date-time"},
+			},
+			expectedProperties: map[string]interface{}{
+				"adults":   map[string]interface{}{"type": "integer", "minimum": 1.0, "maximum": 8.0},
+				"children": map[string]interface{}{"type": "", "maximum": 6.0},
+			},
+			expectedWarnings: []string{
+				`listBookings argument "from": minimum only applies to numbers and was dropped from its string type`,
+			},
+		},
+		{
+			name:   "Strict numeric constraints",
+			strict: true,
+			expectedArgs: map[string]models.Arg{
+				"limit":     {Type: "integer", Minimum: number(1), Maximum: number(100)},
+				"page":      {Type: "integer", Minimum: number(1), ExclusiveMaximum: number(50)},
+				"minRating": {Type: "number", ExclusiveMinimum: number(0), Maximum: number(5), MultipleOf: number(0.5)},
+				"nights":    {Type: "integer", Maximum: number(30)},
+				"from":      {Type: "string", Format: "date"},
+				"checkIn":   {Type: "string", Format: "date-time"},
+			},
+			expectedProperties: map[string]interface{}{
+				"adults":   map[string]interface{}{"type": "integer", "minimum": 1.0, "maximum": 8.0},
+				"children": map[string]interface{}{"type": "integer", "maximum": 6.0},
+			},
+			expectedWarnings: []string{
+				`listBookings argument "page": minimum 0.5 was rounded to minimum 1 for its integer type`,
+				`listBookings argument "nights": multipleOf 0.5 isn't an integer and was dropped from its integer type`,
+				`listBookings argument "nights": maximum 30.5 was rounded to maximum 30 for its integer type`,
+				`listBookings argument "from": minimum only applies to numbers and was dropped from its string type`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/numeric-constraints.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{
+				FormatMapping:            DefaultFormatMapping,
+				StrictNumericConstraints: tc.strict,
+			})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			args := make(map[string]models.Arg)
+			var properties map[string]interface{}
+			for _, tool := range config.Tools {
+				for _, arg := range tool.Args {
+					if arg.Name == "guests" {
+						properties = arg.Properties
+						continue
+					}
+					args[arg.Name] = models.Arg{
+						Type:             arg.Type,
+						Format:           arg.Format,
+						Minimum:          arg.Minimum,
+						Maximum:          arg.Maximum,
+						ExclusiveMinimum: arg.ExclusiveMinimum,
+						ExclusiveMaximum: arg.ExclusiveMaximum,
+						MultipleOf:       arg.MultipleOf,
+					}
+				}
+			}
+			assert.Equal(t, tc.expectedArgs, args)
+			assert.Equal(t, tc.expectedProperties, properties)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+		})
+	}
+}
+
+func TestBodyWrapper(t *testing.T) {
+	tests := []struct {
+		name             string
+		options          models.ConvertOptions
+		expectedBodies   map[string]string
+		expectedWarnings []string
+	}{
+		{
+			name:    "No wrapper",
+			options: models.ConvertOptions{},
+			expectedBodies: map[string]string{
+				"createOrder":  "",
+				"importOrders": "",
+				"listOrders":   "",
+				"replaceTags":  "",
+			},
+		},
+		{
+			name:    "Wrapped in data",
+			options: models.ConvertOptions{BodyWrapper: "data"},
+			expectedBodies: map[string]string{
+				"createOrder":  `{"data": {{toJson (pick .args "item" "quantity" "unitPrice")}}}`,
+				"importOrders": "",
+				"listOrders":   "",
+				"replaceTags":  "",
+			},
+			expectedWarnings: []string{
+				"body_wrapper: POST /orders/import sends application/x-www-form-urlencoded, which can't be wrapped; its body is sent as is",
+				"body_wrapper: PUT /orders/{orderId}/tags has no body properties to wrap; its body is sent as is",
+			},
+		},
+		{
+			name:    "Renamed body arguments",
+			options: models.ConvertOptions{BodyWrapper: "data", ParamNameCase: CaseSnake},
+			expectedBodies: map[string]string{
+				"createOrder":  "",
+				"importOrders": "",
+				"listOrders":   "",
+				"replaceTags":  "",
+			},
+			expectedWarnings: []string{
+				`body_wrapper: POST /orders renames body argument "unitPrice" to "unit_price", which the wrapped body can't map back; its body is sent as is`,
+				"body_wrapper: POST /orders/import sends application/x-www-form-urlencoded, which can't be wrapped; its body is sent as is",
+				"body_wrapper: PUT /orders/{orderId}/tags has no body properties to wrap; its body is sent as is",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/body-wrapper.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, tc.options)
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			bodies := make(map[string]string)
+			for _, tool := range config.Tools {
+				bodies[tool.Name] = tool.RequestTemplate.Body
+			}
+			assert.Equal(t, tc.expectedBodies, bodies)
+			assert.ElementsMatch(t, tc.expectedWarnings, c.GetWarnings())
+		})
+	}
+}
+
+func TestMarkdownSpec(t *testing.T) {
+	t.Run("First spec block", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/markdown-spec.md")
+		assert.NoError(t, err)
+		assert.Equal(t, "Greetings API", p.GetInfo().Title)
+
+		config, err := NewConverter(p, models.ConvertOptions{}).Convert()
+		assert.NoError(t, err)
+		assert.Len(t, config.Tools, 1)
+		assert.Equal(t, "getGreeting", config.Tools[0].Name)
+		assert.Equal(t, "https://api.example.com/greetings/{name}", config.Tools[0].RequestTemplate.URL)
+	})
+
+	t.Run("Detection", func(t *testing.T) {
+		markdown, err := os.ReadFile("../../test/markdown-spec.md")
+		assert.NoError(t, err)
+		spec, err := os.ReadFile("../../test/petstore.json")
+		assert.NoError(t, err)
+
+		assert.True(t, parser.IsMarkdownSpec(markdown))
+		assert.False(t, parser.IsMarkdownSpec(spec))
+	})
+
+	t.Run("No spec block", func(t *testing.T) {
+		p := parser.NewParser()
+		err := p.ParseFile("../../test/markdown-no-spec.md")
+		assert.ErrorIs(t, err, parser.ErrNoMarkdownSpec)
+	})
+}
+
+func TestInitiallyDisabled(t *testing.T) {
+	tests := []struct {
+		name             string
+		disabled         []string
+		expectedDisabled map[string]bool
+		expectedWarnings []string
+	}{
+		{
+			name:     "None disabled",
+			disabled: nil,
+			expectedDisabled: map[string]bool{
+				"shop_listOrders":  false,
+				"shop_getOrder":    false,
+				"shop_cancelOrder": false,
+			},
+		},
+		{
+			name:     "By operationId and tool name",
+			disabled: []string{"listOrders", "shop_cancelOrder"},
+			expectedDisabled: map[string]bool{
+				"shop_listOrders":  true,
+				"shop_getOrder":    false,
+				"shop_cancelOrder": true,
+			},
+		},
+		{
+			name:     "Unmatched entries",
+			disabled: []string{"getOrder", "deleteOrder", "cancelOrder"},
+			expectedDisabled: map[string]bool{
+				"shop_listOrders":  false,
+				"shop_getOrder":    true,
+				"shop_cancelOrder": true,
+			},
+			expectedWarnings: []string{`initially_disabled: no tool or operationId "deleteOrder"`},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/descriptions.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{ToolNamePrefix: "shop_", InitiallyDisabled: tc.disabled})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			disabled := make(map[string]bool)
+			for _, tool := range config.Tools {
+				disabled[tool.Name] = tool.Disabled
+			}
+			assert.Equal(t, tc.expectedDisabled, disabled)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+		})
+	}
+}
+
+func TestEmptyParamHandling(t *testing.T) {
+	tests := []struct {
+		name             string
+		handling         string
+		expectedDefaults map[string]interface{}
+		expectedWarnings []string
+		expectedError    string
+	}{
+		{
+			name:     "Omitted by default",
+			handling: "",
+			expectedDefaults: map[string]interface{}{
+				"catalogId": nil,
+				"q":         nil,
+				"sort":      nil,
+				"page":      nil,
+				"X-Region":  nil,
+			},
+		},
+		{
+			name:     "Omit",
+			handling: EmptyParamOmit,
+			expectedDefaults: map[string]interface{}{
+				"catalogId": nil,
+				"q":         nil,
+				"sort":      nil,
+				"page":      nil,
+				"X-Region":  nil,
+			},
+		},
+		{
+			name:     "Send empty",
+			handling: EmptyParamSendEmpty,
+			expectedDefaults: map[string]interface{}{
+				"catalogId": nil,
+				"q":         "",
+				"sort":      nil,
+				"page":      nil,
+				"X-Region":  "",
+			},
+			expectedWarnings: []string{
+				`empty_param_handling: searchItems argument "page" is of type integer, which has no empty value, and is omitted when unset`,
+			},
+		},
+		{
+			name:          "Unsupported handling",
+			handling:      "null",
+			expectedError: `unsupported empty parameter handling "null", must be one of: omit, send-empty`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/empty-params.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{EmptyParamHandling: tc.handling})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			defaults := make(map[string]interface{})
+			for _, arg := range config.Tools[0].Args {
+				defaults[arg.Name] = arg.Default
+			}
+			assert.Equal(t, tc.expectedDefaults, defaults)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+
+			data, err := yaml.Marshal(config.Tools[0].Args)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.handling == EmptyParamSendEmpty, strings.Contains(string(data), `default: ""`))
+		})
+	}
+}
+
+func TestSynthesizeExamples(t *testing.T) {
+	tests := []struct {
+		name             string
+		synthesize       bool
+		expectedExamples map[string][]interface{}
+		expectedWarnings []string
+	}{
+		{
+			name:             "Disabled",
+			synthesize:       false,
+			expectedExamples: map[string][]interface{}{},
+		},
+		{
+			name:       "Enabled",
+			synthesize: true,
+			expectedExamples: map[string][]interface{}{
+				"venueId":         {"3fa85f64-5717-4562-b3fc-2c963f66afa6"},
+				"date":            {"2024-01-15"},
+				"status":          {"pending"},
+				"page":            {int64(1)},
+				"budget":          {float64(15)},
+				"seats":           {int64(4)},
+				"ids":             {[]interface{}{int64(1), int64(1)}},
+				"includeArchived": {true},
+				"reference":       {"examplexxx"},
+				"guest":           {map[string]interface{}{"name": "Ada Lovelace", "email": "user@example.com"}},
+				"partySize":       {int64(1)},
+			},
+			expectedWarnings: []string{
+				"synthesized 11 argument example(s)",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/synthesized-examples.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{SynthesizeExamples: tc.synthesize})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			examples := make(map[string][]interface{})
+			for _, arg := range config.Tools[0].Args {
+				if arg.Examples != nil {
+					examples[arg.Name] = arg.Examples
+				}
+			}
+			assert.Equal(t, tc.expectedExamples, examples)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+
+			// Synthesized examples are the same on every conversion
+			again := NewConverter(p, models.ConvertOptions{SynthesizeExamples: tc.synthesize})
+			againConfig, err := again.Convert()
+			assert.NoError(t, err)
+			first, err := yaml.Marshal(config)
+			assert.NoError(t, err)
+			second, err := yaml.Marshal(againConfig)
+			assert.NoError(t, err)
+			assert.Equal(t, string(first), string(second))
+		})
+	}
+}
+
+func TestCoerceTypes(t *testing.T) {
+	tests := []struct {
+		name             string
+		coerceTypes      map[string]string
+		expectedCoerce   []string
+		expectedRelaxed  []string
+		expectedYAML     []string
+		expectedJSON     []string
+		expectedWarnings []string
+		expectedError    string
+	}{
+		{
+			name:        "Disabled",
+			coerceTypes: nil,
+		},
+		{
+			name:           "Default hints",
+			coerceTypes:    DefaultCoercion(),
+			expectedCoerce: []string{"archived", "express", "limit", "minTotal", "priority"},
+			expectedYAML:   []string{"type: integer\n", "coerce: true"},
+			expectedWarnings: []string{
+				"coerce_types: 5 argument(s) got coercion hints: listOrders.archived (boolean, hint), listOrders.express (boolean, hint), listOrders.limit (integer, hint), listOrders.minTotal (number, hint), listOrders.priority (integer, hint)",
+			},
+		},
+		{
+			name:            "Relaxed per type",
+			coerceTypes:     map[string]string{"integer": CoercionRelax, "number": CoercionNone, "boolean": CoercionRelax},
+			expectedCoerce:  []string{"archived", "express", "limit", "priority"},
+			expectedRelaxed: []string{"archived", "express", "limit"},
+			expectedYAML: []string{
+				"type: [integer, string]",
+				"type: [boolean, string]",
+				`type: [boolean, string, "null"]`,
+				"pattern: ^-?[0-9]+$",
+				"pattern: ^(true|false)$",
+			},
+			expectedJSON: []string{
+				`"Type":["integer","string"]`,
+				`"Type":["boolean","string"]`,
+				`"Type":["boolean","string","null"]`,
+			},
+			expectedWarnings: []string{
+				"coerce_types: 4 argument(s) got coercion hints: listOrders.archived (boolean, accepts strings), listOrders.express (boolean, accepts strings), listOrders.limit (integer, accepts strings), listOrders.priority (integer, hint)",
+			},
+		},
+		{
+			name:          "Unsupported type",
+			coerceTypes:   map[string]string{"string": CoercionHint},
+			expectedError: `unsupported coercion type "string", must be one of: integer, number, boolean`,
+		},
+		{
+			name:          "Unsupported policy",
+			coerceTypes:   map[string]string{"integer": "cast"},
+			expectedError: `unsupported coercion policy "cast" for integer, must be one of: none, hint, relax`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/coerce-types.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{CoerceTypes: tc.coerceTypes, NullHandling: models.NullHandlingTypeArray})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			var coerced, relaxed []string
+			for _, arg := range config.Tools[0].Args {
+				if arg.Coerce {
+					coerced = append(coerced, arg.Name)
+				}
+				if arg.AcceptString {
+					relaxed = append(relaxed, arg.Name)
+				}
+			}
+			assert.Equal(t, tc.expectedCoerce, coerced)
+			assert.Equal(t, tc.expectedRelaxed, relaxed)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+
+			data, err := yaml.Marshal(config.Tools[0].Args)
+			assert.NoError(t, err)
+			for _, expected := range tc.expectedYAML {
+				assert.Contains(t, string(data), expected)
+			}
+
+			// Relaxed type arrays read back as the same types
+			var args []models.Arg
+			assert.NoError(t, yaml.Unmarshal(data, &args))
+			for i, arg := range args {
+				assert.Equal(t, config.Tools[0].Args[i].Type, arg.Type)
+				assert.Equal(t, config.Tools[0].Args[i].Nullable, arg.Nullable)
+				assert.Equal(t, config.Tools[0].Args[i].AcceptString, arg.AcceptString)
+			}
+
+			// JSON configs carry the same type arrays
+			jsonData, err := json.Marshal(config.Tools[0].Args)
+			assert.NoError(t, err)
+			for _, expected := range tc.expectedJSON {
+				assert.Contains(t, string(jsonData), expected)
+			}
+			var jsonArgs []models.Arg
+			assert.NoError(t, json.Unmarshal(jsonData, &jsonArgs))
+			assert.Equal(t, config.Tools[0].Args, jsonArgs)
+		})
+	}
+}
+
+func TestPipelineOrder(t *testing.T) {
+	tests := []struct {
+		name             string
+		order            []string
+		expectedPipeline []string
+		expectedError    string
+	}{
+		{
+			name:             "Default order",
+			expectedPipeline: []string{StepInitiallyDisabled, StepPropagateTags, StepSortTools},
+		},
+		{
+			name:             "Disabling after sorting",
+			order:            []string{StepSortTools, StepPropagateTags},
+			expectedPipeline: []string{StepSortTools, StepPropagateTags, StepInitiallyDisabled},
+		},
+		{
+			name:          "Unknown step",
+			order:         []string{"minify"},
+			expectedError: `unsupported pipeline step "minify", must be one of: initially_disabled, propagate_tags, template, sort_tools, shared_components`,
+		},
+		{
+			name:          "Repeated step",
+			order:         []string{StepSortTools, StepSortTools},
+			expectedError: `pipeline step "sort_tools" is listed more than once`,
+		},
+		{
+			name:          "Dependency run later",
+			order:         []string{StepSharedComponents, StepTemplate},
+			expectedError: `pipeline step "shared_components" must run after "initially_disabled"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/descriptions.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{
+				ToolNamePrefix:    "shop_",
+				InitiallyDisabled: []string{"cancelOrder"},
+				PropagateTags:     true,
+				OperationOrder:    OrderPath,
+				PipelineOrder:     tc.order,
+			})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPipeline, c.GetPipeline())
+
+			// The disabled tool follows its operation wherever sorting moves it
+			disabled := make(map[string]bool)
+			for _, tool := range config.Tools {
+				disabled[tool.Name] = tool.Disabled
+			}
+			assert.Equal(t, map[string]bool{
+				"shop_listOrders":  false,
+				"shop_getOrder":    false,
+				"shop_cancelOrder": true,
+			}, disabled)
+		})
+	}
+}
+
+func TestSecretPlaceholderTemplate(t *testing.T) {
+	tests := []struct {
+		name             string
+		template         string
+		expectedSchemes  map[string]string
+		expectedErrorSub string
+	}{
+		{
+			name: "Default placeholder",
+			expectedSchemes: map[string]string{
+				"ApiKeyHeaderAuth": "${API_KEY_HEADER_AUTH}",
+				"ApiKeyQueryAuth":  "${API_KEY_QUERY_AUTH}",
+				"BasicAuth":        "${BASIC_AUTH}",
+				"BearerAuth":       "${BEARER_AUTH}",
+			},
+		},
+		{
+			name:     "Prefixed environment variable",
+			template: `{{printf "${MYAPP_%s}" .EnvName}}`,
+			expectedSchemes: map[string]string{
+				"ApiKeyHeaderAuth": "${MYAPP_API_KEY_HEADER_AUTH}",
+				"ApiKeyQueryAuth":  "${MYAPP_API_KEY_QUERY_AUTH}",
+				"BasicAuth":        "${MYAPP_BASIC_AUTH}",
+				"BearerAuth":       "${MYAPP_BEARER_AUTH}",
+			},
+		},
+		{
+			name:     "Runtime template call",
+			template: `{{"{{"}} env "{{.SchemeName}}_{{.Type}}" {{"}}"}}`,
+			expectedSchemes: map[string]string{
+				"ApiKeyHeaderAuth": `{{ env "ApiKeyHeaderAuth_apiKey" }}`,
+				"ApiKeyQueryAuth":  `{{ env "ApiKeyQueryAuth_apiKey" }}`,
+				"BasicAuth":        `{{ env "BasicAuth_http" }}`,
+				"BearerAuth":       `{{ env "BearerAuth_http" }}`,
+			},
+		},
+		{
+			name:             "Failing template",
+			template:         `{{.Missing}}`,
+			expectedErrorSub: "failed to render secret placeholder for",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/security-test.json")
+			assert.NoError(t, err)
+
+			var options models.ConvertOptions
+			if tc.template != "" {
+				options.SecretPlaceholderTemplate = template.Must(template.New("secret_placeholder").Parse(tc.template))
+			}
+			c := NewConverter(p, options)
+			config, err := c.Convert()
+			if tc.expectedErrorSub != "" {
+				assert.ErrorContains(t, err, tc.expectedErrorSub)
+				return
+			}
+			assert.NoError(t, err)
+
+			credentials := make(map[string]string)
+			for _, scheme := range config.Server.SecuritySchemes {
+				credentials[scheme.ID] = scheme.DefaultCredential
+			}
+			assert.Equal(t, tc.expectedSchemes, credentials)
+		})
+	}
+}
+
+func TestOperationIDStripPrefix(t *testing.T) {
+	tests := []struct {
+		name             string
+		prefix           string
+		strict           bool
+		expectedNames    []string
+		expectedWarnings []string
+	}{
+		{
+			name:          "No prefix",
+			expectedNames: []string{"shop_billing", "shop_billing_createInvoice", "shop_billing_listInvoices", "shop_createInvoice", "shop_getHealth"},
+			expectedWarnings: []string{
+				`tool name "shop_billing." is not a valid MCP identifier, renamed to "shop_billing"`,
+				`tool name "shop_billing.createInvoice" is not a valid MCP identifier, renamed to "shop_billing_createInvoice"`,
+				`tool name "shop_billing.listInvoices" is not a valid MCP identifier, renamed to "shop_billing_listInvoices"`,
+			},
+		},
+		{
+			name:          "Service prefix",
+			prefix:        `^billing\.`,
+			expectedNames: []string{"shop_billing", "shop_billing_createInvoice", "shop_createInvoice", "shop_getHealth", "shop_listInvoices"},
+			expectedWarnings: []string{
+				`operation_id_strip_prefix: billing.createInvoice would become "createInvoice" like another tool, keeping the operationId`,
+				`tool name "shop_billing." is not a valid MCP identifier, renamed to "shop_billing"`,
+				`tool name "shop_billing.createInvoice" is not a valid MCP identifier, renamed to "shop_billing_createInvoice"`,
+			},
+		},
+		{
+			name:          "Strict mode reports unmatched operationIds",
+			prefix:        `[a-z]+\.`,
+			strict:        true,
+			expectedNames: []string{"shop_billing", "shop_billing_createInvoice", "shop_createInvoice", "shop_getHealth", "shop_listInvoices"},
+			expectedWarnings: []string{
+				`operation_id_strip_prefix: billing.createInvoice would become "createInvoice" like another tool, keeping the operationId`,
+				`operation_id_strip_prefix: 2 operationId(s) don't start with "[a-z]+\\.": createInvoice, getHealth`,
+				`tool name "shop_billing." is not a valid MCP identifier, renamed to "shop_billing"`,
+				`tool name "shop_billing.createInvoice" is not a valid MCP identifier, renamed to "shop_billing_createInvoice"`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/prefixed-operation-ids.json")
+			assert.NoError(t, err)
+
+			options := models.ConvertOptions{ToolNamePrefix: "shop_", StrictOperationIDs: tc.strict}
+			if tc.prefix != "" {
+				options.OperationIDStripPrefix = regexp.MustCompile(tc.prefix)
+			}
+			c := NewConverter(p, options)
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			var names []string
+			for _, tool := range config.Tools {
+				names = append(names, tool.Name)
+			}
+			assert.Equal(t, tc.expectedNames, names)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+		})
+	}
+}
+
+func TestSafeMode(t *testing.T) {
+	tests := []struct {
+		name             string
+		safeMode         bool
+		excludeGlobs     []string
+		expectedTools    []string
+		expectedWarnings []string
+	}{
+		{
+			name:          "All methods by default",
+			expectedTools: []string{"checkHealth", "createUser", "deleteAccount", "listUsers"},
+		},
+		{
+			name:             "Mutating operations dropped",
+			safeMode:         true,
+			expectedTools:    []string{"checkHealth", "listUsers"},
+			expectedWarnings: []string{"safe_mode: dropped 2 mutating operation(s): DELETE /accounts, POST /users"},
+		},
+		{
+			name:          "Combined with path filters",
+			safeMode:      true,
+			excludeGlobs:  []string{"/accounts"},
+			expectedTools: []string{"checkHealth", "listUsers"},
+			expectedWarnings: []string{
+				"path filters selected 3 of 4 operation(s)",
+				"safe_mode: dropped 1 mutating operation(s): POST /users",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/operation-order.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{SafeMode: tc.safeMode, ExcludePathGlobs: tc.excludeGlobs})
+			config, err := c.Convert()
+			assert.NoError(t, err)
+
+			var names []string
+			for _, tool := range config.Tools {
+				names = append(names, tool.Name)
+			}
+			assert.Equal(t, tc.expectedTools, names)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+			assert.Equal(t, tc.safeMode, config.Server.ReadOnly)
+
+			data, err := yaml.Marshal(config)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.safeMode, strings.Contains(string(data), "readOnly: true"))
+		})
+	}
+}
+
+func TestMissingBodySchema(t *testing.T) {
+	tests := []struct {
+		name              string
+		missingBodySchema string
+		bodyWrapper       string
+		expectedTools     []string
+		expectedArg       *models.Arg
+		expectedBody      string
+		expectedWarnings  []string
+		expectedError     string
+	}{
+		{
+			name:          "Free-form object by default",
+			expectedTools: []string{"createNote", "listNotes", "updateNote"},
+			expectedArg:   &models.Arg{Name: "body", Description: "Request body, a JSON object of any shape", Type: "object", Required: true, Position: "body"},
+			expectedBody:  "{{toJson .args.body}}",
+		},
+		{
+			name:              "Raw string",
+			missingBodySchema: "string",
+			expectedTools:     []string{"createNote", "listNotes", "updateNote"},
+			expectedArg:       &models.Arg{Name: "body", Description: "Raw request body, sent as is", Type: "string", Required: true, Position: "body"},
+			expectedBody:      "{{.args.body}}",
+		},
+		{
+			name:              "Free-form object under the body wrapper",
+			missingBodySchema: "free-form",
+			bodyWrapper:       "data",
+			expectedTools:     []string{"createNote", "listNotes", "updateNote"},
+			expectedArg:       &models.Arg{Name: "body", Description: "Request body, a JSON object of any shape", Type: "object", Required: true, Position: "body"},
+			expectedBody:      `{"data": {{toJson .args.body}}}`,
+		},
+		{
+			name:              "Skipped",
+			missingBodySchema: "skip",
+			expectedTools:     []string{"listNotes", "updateNote"},
+			expectedWarnings:  []string{"missing_body_schema: skipped 1 operation(s) whose request body has no schema: POST /notes"},
+		},
+		{
+			name:              "Unsupported handling",
+			missingBodySchema: "ignore",
+			expectedError:     `unsupported missing body schema handling "ignore", must be one of: free-form, string, skip`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/missing-body-schema.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{MissingBodySchema: tc.missingBodySchema, BodyWrapper: tc.bodyWrapper})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+
+			var names []string
+			for _, tool := range config.Tools {
+				names = append(names, tool.Name)
+				if tool.Name == "updateNote" {
+					// Bodies with a schema are converted as before
+					assert.Len(t, tool.Args, 2)
+					assert.Equal(t, "text", tool.Args[1].Name)
+				}
+				if tool.Name != "createNote" {
+					continue
+				}
+				assert.Equal(t, []models.Arg{*tc.expectedArg}, tool.Args)
+				assert.Equal(t, tc.expectedBody, tool.RequestTemplate.Body)
+			}
+			assert.Equal(t, tc.expectedTools, names)
+		})
+	}
+}
+
+func TestResponseCodesInDescription(t *testing.T) {
+	tests := []struct {
+		name                 string
+		codes                []string
+		expectedDescriptions map[string]string
+		expectedError        string
+	}{
+		{
+			name: "Left out by default",
+			expectedDescriptions: map[string]string{
+				"createOrder": "Create an order",
+				"checkHealth": "",
+			},
+		},
+		{
+			name:  "Success only",
+			codes: []string{"success-only"},
+			expectedDescriptions: map[string]string{
+				"createOrder": "Create an order\n\nResponses: 201 The created order",
+				"checkHealth": "Responses: 200 Healthy",
+			},
+		},
+		{
+			name:  "All",
+			codes: []string{"all"},
+			expectedDescriptions: map[string]string{
+				"createOrder": "Create an order\n\nResponses: 201 The created order; 400 Invalid order; 401 Missing credentials; 403 Not allowed to order; " +
+					"404 Unknown product; 409 Duplicate order; 422 Out of stock; 500 Server error; default Unexpected error",
+				"checkHealth": "Responses: 200 Healthy",
+			},
+		},
+		{
+			name:  "Explicit list with a range",
+			codes: []string{"201", "404", "5XX"},
+			expectedDescriptions: map[string]string{
+				"createOrder": "Create an order\n\nResponses: 201 The created order; 404 Unknown product; 500 Server error",
+				"checkHealth": "",
+			},
+		},
+		{
+			name:  "None",
+			codes: []string{"none"},
+			expectedDescriptions: map[string]string{
+				"createOrder": "Create an order",
+				"checkHealth": "",
+			},
+		},
+		{
+			name:          "Keyword in a list",
+			codes:         []string{"success-only", "404"},
+			expectedError: `unsupported response code "success-only" in description codes, must be a status code, a range such as 4XX, default, or one of: none, success-only, all`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/many-response-codes.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{ResponseCodesInDescription: tc.codes})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			descriptions := make(map[string]string)
+			for _, tool := range config.Tools {
+				descriptions[tool.Name] = tool.Description
+			}
+			assert.Equal(t, tc.expectedDescriptions, descriptions)
+		})
+	}
+}
+
+func TestMaxSchemaDepth(t *testing.T) {
+	customerArg := func(t *testing.T, maxSchemaDepth int) (models.Arg, []string) {
+		p := parser.NewParser()
+		assert.NoError(t, p.ParseFile("../../test/nested-schemas.json"))
+		c := NewConverter(p, models.ConvertOptions{MaxSchemaDepth: maxSchemaDepth})
+		config, err := c.Convert()
+		assert.NoError(t, err)
+		assert.Len(t, config.Tools, 1)
+		for _, arg := range config.Tools[0].Args {
+			if arg.Name == "customer" {
+				return arg, c.GetWarnings()
+			}
+		}
+		t.Fatal("createOrder has no customer argument")
+		return models.Arg{}, nil
+	}
+
+	t.Run("Depth 1 drops the argument's properties", func(t *testing.T) {
+		arg, warnings := customerArg(t, 1)
+		assert.Nil(t, arg.Properties)
+		assert.Equal(t, "Who ordered (nested properties omitted: schema depth limit reached)", arg.Description)
+		assert.Equal(t, []string{"schemas of createOrder were truncated at depth 1"}, warnings)
+	})
+
+	t.Run("Depth 3 keeps three levels", func(t *testing.T) {
+		arg, warnings := customerArg(t, 3)
+		address := arg.Properties["address"].(map[string]interface{})
+		geo := address["properties"].(map[string]interface{})["geo"].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{
+			"type":        "object",
+			"description": "Coordinates (nested properties omitted: schema depth limit reached)",
+		}, geo)
+		assert.Equal(t, "Who ordered", arg.Description)
+		assert.Equal(t, []string{"schemas of createOrder were truncated at depth 3"}, warnings)
+	})
+
+	t.Run("Every level without a limit", func(t *testing.T) {
+		arg, warnings := customerArg(t, 0)
+		address := arg.Properties["address"].(map[string]interface{})
+		geo := address["properties"].(map[string]interface{})["geo"].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{
+			"lat": map[string]interface{}{"type": "number"},
+			"lng": map[string]interface{}{"type": "number"},
+		}, geo["properties"])
+		assert.Empty(t, warnings)
+	})
+}
+
+func TestMaxEnumValues(t *testing.T) {
+	tests := []struct {
+		name                 string
+		maxEnumValues        int
+		enumOverflow         string
+		expectedEnums        map[string][]interface{}
+		expectedDescriptions map[string]string
+		expectedWarnings     []string
+		expectedError        string
+	}{
+		{
+			name: "No limit by default",
+			expectedEnums: map[string][]interface{}{
+				"getRates.country":      {"AD", "AE", "AF", "AG", "AI", "AL", "AM", "AO", "AQ", "AR", "AS", "AT", "AU", "AW", "AX", "AZ", "BA", "BB", "BD", "BE", "BF", "BG", "BH", "BI", "BJ"},
+				"getRates.speed":        {"standard", "express"},
+				"createShipment.origin": {"AD", "AE", "AF", "AG", "AI", "AL", "AM", "AO", "AQ", "AR", "AS", "AT", "AU", "AW", "AX", "AZ", "BA", "BB", "BD", "BE", "BF", "BG", "BH", "BI", "BJ"},
+			},
+			expectedDescriptions: map[string]string{
+				"getRates.country":      "Destination country",
+				"getRates.speed":        "Allowed values:\n- standard: 3-5 days\n- express: Next day",
+				"createShipment.origin": "",
+			},
+		},
+		{
+			name:          "Truncated",
+			maxEnumValues: 3,
+			expectedEnums: map[string][]interface{}{
+				"getRates.country":      {"AD", "AE", "AF"},
+				"getRates.speed":        {"standard", "express"},
+				"createShipment.origin": {"AD", "AE", "AF"},
+			},
+			expectedDescriptions: map[string]string{
+				"getRates.country":      "Destination country (enum truncated to the first 3 of 25 values)",
+				"getRates.speed":        "Allowed values:\n- standard: 3-5 days\n- express: Next day",
+				"createShipment.origin": "(enum truncated to the first 3 of 25 values)",
+			},
+			expectedWarnings: []string{"max_enum_values: 2 enum(s) truncated to 3 values: createShipment.origin (25 values), getRates.country (25 values)"},
+		},
+		{
+			name:          "Enum descriptions of the kept values",
+			maxEnumValues: 1,
+			expectedEnums: map[string][]interface{}{
+				"getRates.country":      {"AD"},
+				"getRates.speed":        {"standard"},
+				"createShipment.origin": {"AD"},
+			},
+			expectedDescriptions: map[string]string{
+				"getRates.country":      "Destination country (enum truncated to the first 1 of 25 values)",
+				"getRates.speed":        "(enum truncated to the first 1 of 2 values)\n\nAllowed values:\n- standard: 3-5 days",
+				"createShipment.origin": "(enum truncated to the first 1 of 25 values)",
+			},
+			expectedWarnings: []string{"max_enum_values: 3 enum(s) truncated to 1 values: createShipment.origin (25 values), getRates.country (25 values), getRates.speed (2 values)"},
+		},
+		{
+			name:          "Replaced with strings",
+			maxEnumValues: 3,
+			enumOverflow:  "string",
+			expectedEnums: map[string][]interface{}{
+				"getRates.country":      nil,
+				"getRates.speed":        {"standard", "express"},
+				"createShipment.origin": nil,
+			},
+			expectedDescriptions: map[string]string{
+				"getRates.country":      "Destination country (one of 25 values, e.g. AD, AE, AF)",
+				"getRates.speed":        "Allowed values:\n- standard: 3-5 days\n- express: Next day",
+				"createShipment.origin": "(one of 25 values, e.g. AD, AE, AF)",
+			},
+			expectedWarnings: []string{"max_enum_values: 2 enum(s) replaced with strings over 3 values: createShipment.origin (25 values), getRates.country (25 values)"},
+		},
+		{
+			name:          "Negative limit",
+			maxEnumValues: -1,
+			expectedError: "max enum values must not be negative, got -1",
+		},
+		{
+			name:          "Unsupported overflow",
+			maxEnumValues: 3,
+			enumOverflow:  "pattern",
+			expectedError: `unsupported enum overflow "pattern", must be one of: truncate, string`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/large-enum.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{MaxEnumValues: tc.maxEnumValues, EnumOverflow: tc.enumOverflow, IncludeEnumDescriptions: true})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			enums := make(map[string][]interface{})
+			descriptions := make(map[string]string)
+			for _, tool := range config.Tools {
+				for _, arg := range tool.Args {
+					if arg.Name == "weight" {
+						continue
+					}
+					enums[tool.Name+"."+arg.Name] = arg.Enum
+					descriptions[tool.Name+"."+arg.Name] = arg.Description
+				}
+			}
+			assert.Equal(t, tc.expectedEnums, enums)
+			assert.Equal(t, tc.expectedDescriptions, descriptions)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+		})
+	}
+}
+
+func TestExtensionPassthrough(t *testing.T) {
+	tests := []struct {
+		name             string
+		extensions       []string
+		expectedMetadata map[string]map[string]interface{}
+		expectedWarnings []string
+		expectedError    string
+	}{
+		{
+			name: "No extensions by default",
+			expectedMetadata: map[string]map[string]interface{}{
+				"deleteAccount": nil,
+				"getAccount":    nil,
+			},
+		},
+		{
+			name:       "Listed extensions copied verbatim",
+			extensions: []string{"x-llm-hint", "x-dangerous", "x-requires-confirmation"},
+			expectedMetadata: map[string]map[string]interface{}{
+				"deleteAccount": {
+					"x-dangerous": true,
+					"x-requires-confirmation": map[string]interface{}{
+						"prompt": "Delete the account?",
+						"roles":  []interface{}{"admin", "owner"},
+					},
+				},
+				"getAccount": {"x-llm-hint": "Look the account up before changing it"},
+			},
+		},
+		{
+			name:       "Extensions on no operation",
+			extensions: []string{"x-dangerous", "x-rate-limit", "x-cost"},
+			expectedMetadata: map[string]map[string]interface{}{
+				"deleteAccount": {"x-dangerous": true},
+				"getAccount":    nil,
+			},
+			expectedWarnings: []string{
+				"extension_passthrough: no operation has x-cost",
+				"extension_passthrough: no operation has x-rate-limit",
+			},
+		},
+		{
+			name:          "Not a vendor extension",
+			extensions:    []string{"summary"},
+			expectedError: `extension passthrough "summary" is not a vendor extension, it must start with x-`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/vendor-extensions.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{ExtensionPassthrough: tc.extensions})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+
+			metadata := make(map[string]map[string]interface{})
+			for _, tool := range config.Tools {
+				metadata[tool.Name] = tool.Metadata
+			}
+			assert.Equal(t, tc.expectedMetadata, metadata)
+
+			data, err := yaml.Marshal(config)
+			assert.NoError(t, err)
+			assert.Equal(t, len(tc.extensions) > 0, strings.Contains(string(data), "metadata:"))
+		})
+	}
+}
+
+func TestParameterConflictMode(t *testing.T) {
+	tests := []struct {
+		name             string
+		mode             string
+		expectedArgs     map[string][]string
+		expectedWarnings []string
+		expectedError    string
+	}{
+		{
+			name: "Operation wins by default",
+			expectedArgs: map[string][]string{
+				"createTask": {"limit (query): Page size", "projectId (path): Project ID"},
+				"listTasks":  {"limit (query): Number of tasks, at most 50", "limit (header): Rate limit bucket", "projectId (path): Project ID"},
+			},
+			expectedWarnings: []string{
+				`parameter_conflict_mode: GET /projects/{projectId}/tasks declares query parameter "limit" at both the path and the operation level, using the operation's`,
+			},
+		},
+		{
+			name: "Path wins",
+			mode: "path-wins",
+			expectedArgs: map[string][]string{
+				"createTask": {"limit (query): Page size", "projectId (path): Project ID"},
+				"listTasks":  {"limit (query): Page size", "limit (header): Rate limit bucket", "projectId (path): Project ID"},
+			},
+			expectedWarnings: []string{
+				`parameter_conflict_mode: GET /projects/{projectId}/tasks declares query parameter "limit" at both the path and the operation level, using the path's`,
+			},
+		},
+		{
+			name:          "Error",
+			mode:          "error",
+			expectedError: `failed to convert operation get /projects/{projectId}/tasks: query parameter "limit" is declared at both the path and the operation level`,
+		},
+		{
+			name:          "Unsupported mode",
+			mode:          "merge",
+			expectedError: `unsupported parameter conflict mode "merge", must be one of: operation-wins, path-wins, error`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/parameter-conflicts.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{ParameterConflictMode: tc.mode})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+
+			args := make(map[string][]string)
+			for _, tool := range config.Tools {
+				for _, arg := range tool.Args {
+					args[tool.Name] = append(args[tool.Name], fmt.Sprintf("%s (%s): %s", arg.Name, arg.Position, arg.Description))
+				}
+			}
+			assert.Equal(t, tc.expectedArgs, args)
+		})
+	}
+}
+
+func TestFilterDescriptionLanguage(t *testing.T) {
+	tests := []struct {
+		name             string
+		language         string
+		mode             string
+		expectedTools    map[string]string
+		expectedSkipped  []string
+		expectedWarnings []string
+		expectedError    string
+	}{
+		{
+			name:          "No filter",
+			expectedTools: map[string]string{"listArticles": "", "createArticle": "", "getArticle": "", "deleteArticle": "", "getHealth": ""},
+		},
+		{
+			name:            "Skip other languages",
+			language:        "en",
+			expectedTools:   map[string]string{"listArticles": "", "getHealth": ""},
+			expectedSkipped: []string{"POST /articles: description in de, not en", "DELETE /articles/{id}: description in ja, not en", "GET /articles/{id}: description in es, not en"},
+			expectedWarnings: []string{
+				"filter_description_language: skipped 3 operation(s) whose description isn't in en: DELETE /articles/{id} (ja), GET /articles/{id} (es), POST /articles (de)",
+				"filter_description_language: kept 1 operation(s) whose description is too short or ambiguous to detect its language: GET /health",
+			},
+		},
+		{
+			name:          "Tag other languages",
+			language:      "de",
+			mode:          "tag",
+			expectedTools: map[string]string{"listArticles": "en", "createArticle": "", "getArticle": "es", "deleteArticle": "ja", "getHealth": ""},
+			expectedWarnings: []string{
+				"filter_description_language: tagged 3 operation(s) whose description isn't in de: DELETE /articles/{id} (ja), GET /articles (en), GET /articles/{id} (es)",
+				"filter_description_language: kept 1 operation(s) whose description is too short or ambiguous to detect its language: GET /health",
+			},
+		},
+		{
+			name:          "Unsupported language",
+			language:      "english",
+			expectedError: `unsupported description language "english", must be one of: ar, de, el, en, es, fr, he, it, ja, ko, nl, pt, ru, zh`,
+		},
+		{
+			name:          "Unsupported mode",
+			language:      "en",
+			mode:          "drop",
+			expectedError: `unsupported description language mode "drop", must be one of: skip, tag`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.NewParser()
+			err := p.ParseFile("../../test/mixed-languages.json")
+			assert.NoError(t, err)
+
+			c := NewConverter(p, models.ConvertOptions{FilterDescriptionLanguage: tc.language, DescriptionLanguageMode: tc.mode})
+			config, err := c.Convert()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedWarnings, c.GetWarnings())
+
+			tools := make(map[string]string)
+			for _, tool := range config.Tools {
+				tools[tool.Name] = tool.Language
+			}
+			assert.Equal(t, tc.expectedTools, tools)
+
+			var skipped []string
+			for _, operation := range c.GetSkippedOperations() {
+				skipped = append(skipped, fmt.Sprintf("%s %s: %s", operation.Method, operation.Path, operation.Reason))
+			}
+			assert.Equal(t, tc.expectedSkipped, skipped)
+
+			if tc.language != "" {
+				assert.Equal(t, []models.OperationLanguage{
+					{Path: "/articles", Method: "GET", Language: "en"},
+					{Path: "/articles", Method: "POST", Language: "de"},
+					{Path: "/articles/{id}", Method: "DELETE", Language: "ja"},
+					{Path: "/articles/{id}", Method: "GET", Language: "es"},
+					{Path: "/health", Method: "GET", Language: "und"},
+				}, c.GetDescriptionLanguages())
+			} else {
+				assert.Empty(t, c.GetDescriptionLanguages())
+			}
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"Returns the list of orders for the given customer.", "en"},
+		{"Renvoie la liste des commandes pour le client.", "fr"},
+		{"Restituisce tutti gli ordini del cliente.", "it"},
+		{"Retorna todos os pedidos do cliente.", "pt"},
+		{"Geeft alle bestellingen van de klant.", "nl"},
+		{"Возвращает список заказов клиента.", "ru"},
+		{"返回客户的订单列表。", "zh"},
+		{"고객의 주문 목록을 반환합니다.", "ko"},
+		{"Get orders", "und"},
+		{"", "und"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, detectLanguage(tc.text), tc.text)
+	}
+}
@@ -0,0 +1,44 @@
+package converter
+
+import (
+	"sort"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// applyInitiallyDisabled marks the tools listed in InitiallyDisabled, by
+// operationId or tool name, as disabled. sources holds the operation of each
+// tool. Entries matching no tool are reported, including those of operations
+// that were skipped.
+func (c *Converter) applyInitiallyDisabled(tools []models.Tool, sources []toolSource) {
+	if len(c.options.InitiallyDisabled) == 0 {
+		return
+	}
+	disabled := make(map[string]bool, len(c.options.InitiallyDisabled))
+	for _, name := range c.options.InitiallyDisabled {
+		disabled[name] = false
+	}
+
+	for i := range tools {
+		source := sources[i]
+		operation := getOperations(c.parser.GetPaths()[source.path])[source.method]
+		operationID := c.parser.GetOperationID(source.path, source.method, operation)
+		for _, name := range []string{operationID, tools[i].Name} {
+			if _, ok := disabled[name]; ok {
+				tools[i].Disabled = true
+				disabled[name] = true
+			}
+		}
+	}
+
+	var unmatched []string
+	for name, matched := range disabled {
+		if !matched {
+			unmatched = append(unmatched, name)
+		}
+	}
+	sort.Strings(unmatched)
+	for _, name := range unmatched {
+		c.addWarning("initially_disabled: no tool or operationId %q", name)
+	}
+}
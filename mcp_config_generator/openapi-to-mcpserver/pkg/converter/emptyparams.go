@@ -0,0 +1,33 @@
+package converter
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// Treatments of unset optional parameters supported by
+// ConvertOptions.EmptyParamHandling
+const (
+	// EmptyParamOmit leaves unset parameters out of the request
+	EmptyParamOmit = "omit"
+	// EmptyParamSendEmpty sends unset parameters as empty strings
+	EmptyParamSendEmpty = "send-empty"
+)
+
+// EmptyParamHandlings lists the supported treatments of unset parameters
+var EmptyParamHandlings = []string{EmptyParamOmit, EmptyParamSendEmpty}
+
+// applyEmptyParamHandling gives an optional query, header or cookie
+// parameter an empty default with EmptyParamSendEmpty, so the runtime sends
+// it as an empty string when the caller leaves it unset. Only string
+// parameters have an empty value; others are reported and still omitted.
+func (c *Converter) applyEmptyParamHandling(arg *models.Arg, param *openapi3.Parameter) {
+	if c.options.EmptyParamHandling != EmptyParamSendEmpty || param.Required || param.In == openapi3.ParameterInPath || arg.Default != nil {
+		return
+	}
+	if arg.Type != "" && arg.Type != "string" {
+		c.addWarning("empty_param_handling: %s argument %q is of type %s, which has no empty value, and is omitted when unset", c.currentTool, arg.Name, arg.Type)
+		return
+	}
+	arg.Default = ""
+}
@@ -0,0 +1,49 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// enumDescriptionsExtension documents each enum value of a schema, in the
+// same order as the enum
+const enumDescriptionsExtension = "x-enum-descriptions"
+
+// appendEnumDescriptions appends the meaning of each enum value from the
+// x-enum-descriptions extension to the argument's description. Extensions
+// that don't line up with the enum are ignored with a warning rather than
+// risk pairing values with the wrong meanings.
+func (c *Converter) appendEnumDescriptions(arg *models.Arg, schema *openapi3.Schema) {
+	if !c.options.IncludeEnumDescriptions || len(arg.Enum) == 0 {
+		return
+	}
+	extension, ok := schema.Extensions[enumDescriptionsExtension]
+	if !ok {
+		return
+	}
+	descriptions, ok := extension.([]interface{})
+	if !ok {
+		c.addWarningOnce("%s of %q is not a list and was ignored", enumDescriptionsExtension, arg.Name)
+		return
+	}
+	if len(descriptions) != len(schema.Enum) {
+		c.addWarningOnce("%s of %q has %d entries for %d enum values and was ignored", enumDescriptionsExtension, arg.Name, len(descriptions), len(schema.Enum))
+		return
+	}
+
+	// Only the values MaxEnumValues kept are listed
+	lines := make([]string, 0, len(arg.Enum)+1)
+	lines = append(lines, "Allowed values:")
+	for i, value := range arg.Enum {
+		lines = append(lines, fmt.Sprintf("- %v: %v", value, descriptions[i]))
+	}
+	list := strings.Join(lines, "\n")
+	if arg.Description == "" {
+		arg.Description = list
+	} else {
+		arg.Description += "\n\n" + list
+	}
+}
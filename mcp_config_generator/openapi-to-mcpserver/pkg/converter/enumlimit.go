@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// Treatments of enums longer than ConvertOptions.MaxEnumValues
+const (
+	// EnumOverflowTruncate keeps the first MaxEnumValues values
+	EnumOverflowTruncate = "truncate"
+	// EnumOverflowString drops the enum, leaving a plain argument of its
+	// type, and names the first MaxEnumValues values as examples
+	EnumOverflowString = "string"
+)
+
+// EnumOverflows lists the supported treatments of enums over the limit
+var EnumOverflows = []string{EnumOverflowTruncate, EnumOverflowString}
+
+// limitEnum shortens the enum of an argument to MaxEnumValues values, or
+// drops it with EnumOverflowString, and notes the full number of values in
+// its description. The argument is recorded for the conversion's warning.
+func (c *Converter) limitEnum(arg *models.Arg) {
+	limit := c.options.MaxEnumValues
+	if limit <= 0 || len(arg.Enum) <= limit {
+		return
+	}
+
+	total := len(arg.Enum)
+	note := fmt.Sprintf("(enum truncated to the first %d of %d values)", limit, total)
+	if c.options.EnumOverflow == EnumOverflowString {
+		examples := make([]string, 0, limit)
+		for _, value := range arg.Enum[:limit] {
+			examples = append(examples, fmt.Sprint(value))
+		}
+		note = fmt.Sprintf("(one of %d values, e.g. %s)", total, strings.Join(examples, ", "))
+		arg.Enum = nil
+	} else {
+		arg.Enum = arg.Enum[:limit:limit]
+	}
+	arg.Description = strings.TrimSpace(arg.Description + " " + note)
+	c.truncatedEnums = append(c.truncatedEnums, fmt.Sprintf("%s.%s (%d values)", c.currentTool, arg.Name, total))
+}
+
+// reportTruncatedEnums adds a warning listing the enums limitEnum shortened
+func (c *Converter) reportTruncatedEnums() {
+	if len(c.truncatedEnums) == 0 {
+		return
+	}
+	sort.Strings(c.truncatedEnums)
+	action := "truncated to"
+	if c.options.EnumOverflow == EnumOverflowString {
+		action = "replaced with strings over"
+	}
+	c.addWarning("max_enum_values: %d enum(s) %s %d values: %s", len(c.truncatedEnums), action, c.options.MaxEnumValues, strings.Join(c.truncatedEnums, ", "))
+}
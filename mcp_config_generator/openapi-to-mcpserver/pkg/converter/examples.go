@@ -0,0 +1,164 @@
+package converter
+
+import (
+	"encoding/json"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// stripExamples removes the examples from the loaded document and records a
+// warning with the resulting size reduction
+func (c *Converter) stripExamples() {
+	doc := c.parser.GetDocument()
+	before, _ := json.Marshal(doc)
+
+	stripper := &exampleStripper{visited: make(map[*openapi3.Schema]bool)}
+	stripper.document(doc)
+
+	after, _ := json.Marshal(doc)
+	if stripper.removed > 0 && len(before) > 0 {
+		reduction := len(before) - len(after)
+		c.addWarning("stripped %d example(s), reducing the spec by %d bytes (%.1f%%)", stripper.removed, reduction, float64(reduction)*100/float64(len(before)))
+	}
+}
+
+// exampleStripper walks a document removing example and examples fields.
+// Schemas can be shared and recursive, so each is visited once.
+type exampleStripper struct {
+	visited map[*openapi3.Schema]bool
+	removed int
+}
+
+func (s *exampleStripper) document(doc *openapi3.T) {
+	if doc.Components != nil {
+		for _, schemaRef := range doc.Components.Schemas {
+			s.schemaRef(schemaRef)
+		}
+		for _, paramRef := range doc.Components.Parameters {
+			s.parameterRef(paramRef)
+		}
+		for _, bodyRef := range doc.Components.RequestBodies {
+			if bodyRef != nil && bodyRef.Value != nil {
+				s.content(bodyRef.Value.Content)
+			}
+		}
+		for _, responseRef := range doc.Components.Responses {
+			s.responseRef(responseRef)
+		}
+		for _, headerRef := range doc.Components.Headers {
+			s.headerRef(headerRef)
+		}
+	}
+
+	for _, pathItem := range doc.Paths {
+		if pathItem == nil {
+			continue
+		}
+		for _, paramRef := range pathItem.Parameters {
+			s.parameterRef(paramRef)
+		}
+		for _, operation := range getOperations(pathItem) {
+			for _, paramRef := range operation.Parameters {
+				s.parameterRef(paramRef)
+			}
+			if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+				s.content(operation.RequestBody.Value.Content)
+			}
+			for _, responseRef := range operation.Responses {
+				s.responseRef(responseRef)
+			}
+		}
+	}
+}
+
+func (s *exampleStripper) parameterRef(ref *openapi3.ParameterRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	param := ref.Value
+	s.examples(&param.Example, &param.Examples)
+	s.schemaRef(param.Schema)
+	s.content(param.Content)
+}
+
+func (s *exampleStripper) headerRef(ref *openapi3.HeaderRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	header := ref.Value
+	s.examples(&header.Example, &header.Examples)
+	s.schemaRef(header.Schema)
+	s.content(header.Content)
+}
+
+func (s *exampleStripper) responseRef(ref *openapi3.ResponseRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	for _, headerRef := range ref.Value.Headers {
+		s.headerRef(headerRef)
+	}
+	s.content(ref.Value.Content)
+}
+
+func (s *exampleStripper) content(content openapi3.Content) {
+	for _, mediaType := range content {
+		if mediaType == nil {
+			continue
+		}
+		s.examples(&mediaType.Example, &mediaType.Examples)
+		s.schemaRef(mediaType.Schema)
+	}
+}
+
+func (s *exampleStripper) examples(example *interface{}, examples *openapi3.Examples) {
+	if *example != nil {
+		*example = nil
+		s.removed++
+	}
+	if len(*examples) > 0 {
+		s.removed += len(*examples)
+		*examples = nil
+	}
+}
+
+func (s *exampleStripper) schemaRef(ref *openapi3.SchemaRef) {
+	if ref == nil || ref.Value == nil || s.visited[ref.Value] {
+		return
+	}
+	schema := ref.Value
+	s.visited[schema] = true
+
+	if schema.Example != nil && !exampleIsLoadBearing(schema) {
+		schema.Example = nil
+		s.removed++
+	}
+
+	for _, propRef := range schema.Properties {
+		s.schemaRef(propRef)
+	}
+	s.schemaRef(schema.Items)
+	s.schemaRef(schema.Not)
+	if schema.AdditionalProperties.Schema != nil {
+		s.schemaRef(schema.AdditionalProperties.Schema)
+	}
+	for _, refs := range []openapi3.SchemaRefs{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, subRef := range refs {
+			s.schemaRef(subRef)
+		}
+	}
+}
+
+// exampleIsLoadBearing reports whether a schema's example is the only hint
+// of what its values look like: it has no type, enum, format or structure.
+func exampleIsLoadBearing(schema *openapi3.Schema) bool {
+	return schema.Type == "" &&
+		len(schema.Enum) == 0 &&
+		schema.Format == "" &&
+		schema.Pattern == "" &&
+		len(schema.Properties) == 0 &&
+		schema.Items == nil &&
+		len(schema.AllOf) == 0 &&
+		len(schema.AnyOf) == 0 &&
+		len(schema.OneOf) == 0
+}
@@ -0,0 +1,64 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// validateExtensionPassthrough checks that ExtensionPassthrough only names
+// vendor extensions
+func validateExtensionPassthrough(extensions []string) error {
+	for _, extension := range extensions {
+		if !strings.HasPrefix(extension, "x-") {
+			return fmt.Errorf("extension passthrough %q is not a vendor extension, it must start with x-", extension)
+		}
+	}
+	return nil
+}
+
+// applyExtensionPassthrough copies the operation's extensions listed in
+// ExtensionPassthrough, with their values as they are, into the tool's
+// metadata
+func (c *Converter) applyExtensionPassthrough(tool *models.Tool, operation *openapi3.Operation) {
+	for _, extension := range c.options.ExtensionPassthrough {
+		value, ok := operation.Extensions[extension]
+		if !ok {
+			continue
+		}
+		if tool.Metadata == nil {
+			tool.Metadata = make(map[string]interface{})
+		}
+		tool.Metadata[extension] = copyValue(value)
+	}
+}
+
+// checkExtensionPassthrough warns about listed extensions no operation of
+// the document has. Operations left out by filters still count.
+func (c *Converter) checkExtensionPassthrough() {
+	if len(c.options.ExtensionPassthrough) == 0 {
+		return
+	}
+	found := make(map[string]bool)
+	for _, pathItem := range c.parser.GetPaths() {
+		for _, operation := range getOperations(pathItem) {
+			for extension := range operation.Extensions {
+				found[extension] = true
+			}
+		}
+	}
+
+	var missing []string
+	for _, extension := range c.options.ExtensionPassthrough {
+		if !found[extension] && !contains(missing, extension) {
+			missing = append(missing, extension)
+		}
+	}
+	sort.Strings(missing)
+	for _, extension := range missing {
+		c.addWarning("extension_passthrough: no operation has %s", extension)
+	}
+}
@@ -0,0 +1,41 @@
+package converter
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// DefaultFormatMapping maps common OpenAPI string formats to the JSON Schema
+// constraints added to tool arguments when format mapping is enabled
+var DefaultFormatMapping = map[string]models.FormatConstraint{
+	"uuid":      {Pattern: "^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$"},
+	"date-time": {Format: "date-time"},
+	"date":      {Format: "date"},
+	"time":      {Format: "time"},
+	"email":     {Format: "email"},
+	"uri":       {Format: "uri"},
+	"hostname":  {Format: "hostname"},
+	"ipv4":      {Format: "ipv4"},
+	"ipv6":      {Format: "ipv6"},
+}
+
+// typeFormats are OpenAPI formats that only refine the type and need no
+// mapping
+var typeFormats = []string{"int32", "int64", "float", "double", "byte", "binary", "password"}
+
+// applyFormatMapping adds the constraint mapped from the schema's format to
+// the argument, warning once per conversion about formats without a mapping
+func (c *Converter) applyFormatMapping(arg *models.Arg, schema *openapi3.Schema) {
+	if c.options.FormatMapping == nil || schema.Format == "" {
+		return
+	}
+	constraint, ok := c.options.FormatMapping[schema.Format]
+	if !ok {
+		if !contains(typeFormats, schema.Format) {
+			c.addWarningOnce("format %q has no mapping and is left out of the tool arguments", schema.Format)
+		}
+		return
+	}
+	arg.Format = constraint.Format
+	arg.Pattern = constraint.Pattern
+}
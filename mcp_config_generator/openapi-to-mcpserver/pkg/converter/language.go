@@ -0,0 +1,196 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// Treatments of operations whose description isn't in
+// ConvertOptions.FilterDescriptionLanguage, supported by
+// ConvertOptions.DescriptionLanguageMode
+const (
+	// DescriptionLanguageSkip leaves the operation out
+	DescriptionLanguageSkip = "skip"
+	// DescriptionLanguageTag keeps the operation, setting the tool's
+	// language to the one detected
+	DescriptionLanguageTag = "tag"
+)
+
+// DescriptionLanguageModes lists the supported treatments of operations in
+// another language
+var DescriptionLanguageModes = []string{DescriptionLanguageSkip, DescriptionLanguageTag}
+
+// LanguageUndetermined is reported for descriptions that are missing, too
+// short or too ambiguous to detect their language. Such operations are kept.
+const LanguageUndetermined = "und"
+
+// minLanguageWords is the fewest words a Latin-script description needs for
+// its language to be detected. Shorter ones, such as "Get pet", share too
+// many words across languages.
+const minLanguageWords = 3
+
+// languageStopwords are frequent words of the Latin-script languages,
+// mostly function words plus verbs common in API descriptions. A
+// description is in the language with the most of its words in the list.
+var languageStopwords = map[string][]string{
+	"en": {"the", "a", "an", "of", "to", "and", "or", "is", "are", "for", "in", "on", "with", "by", "from", "this", "that", "be", "it", "as", "if", "not", "all", "returns", "creates", "updates", "deletes", "gets", "retrieves", "lists", "given", "specified", "which", "will"},
+	"de": {"der", "die", "das", "und", "ist", "ein", "eine", "einen", "einer", "mit", "für", "von", "zu", "den", "dem", "des", "nicht", "auf", "wird", "werden", "gibt", "alle", "oder", "im", "zurück", "nach", "bei", "aus", "erstellt", "löscht", "aktualisiert"},
+	"fr": {"le", "la", "les", "un", "une", "des", "du", "de", "et", "est", "pour", "avec", "dans", "sur", "par", "ou", "pas", "qui", "au", "aux", "renvoie", "retourne", "tous", "toutes", "cette", "ce", "crée", "supprime", "met", "jour"},
+	"es": {"el", "la", "los", "las", "un", "una", "de", "del", "y", "es", "para", "con", "en", "por", "que", "o", "al", "devuelve", "obtiene", "todos", "todas", "este", "esta", "se", "crea", "elimina", "actualiza"},
+	"it": {"il", "lo", "la", "gli", "le", "un", "una", "di", "del", "della", "e", "è", "per", "con", "in", "su", "che", "o", "restituisce", "tutti", "tutte", "questo", "questa", "dei", "delle", "crea", "elimina", "aggiorna"},
+	"pt": {"o", "a", "os", "as", "um", "uma", "de", "do", "da", "dos", "das", "e", "é", "para", "com", "em", "por", "que", "ou", "retorna", "todos", "todas", "este", "esta", "no", "na", "cria", "remove", "atualiza"},
+	"nl": {"de", "het", "een", "en", "is", "van", "voor", "met", "in", "op", "door", "of", "niet", "dit", "deze", "geeft", "alle", "worden", "wordt", "naar", "te", "maakt", "verwijdert"},
+}
+
+// scriptLanguages detect the languages written in their own script from
+// its letters, in order of precedence: Japanese mixes kana with the Han
+// characters of Chinese.
+var scriptLanguages = []struct {
+	language string
+	tables   []*unicode.RangeTable
+}{
+	{"ja", []*unicode.RangeTable{unicode.Hiragana, unicode.Katakana}},
+	{"ko", []*unicode.RangeTable{unicode.Hangul}},
+	{"zh", []*unicode.RangeTable{unicode.Han}},
+	{"ru", []*unicode.RangeTable{unicode.Cyrillic}},
+	{"ar", []*unicode.RangeTable{unicode.Arabic}},
+	{"el", []*unicode.RangeTable{unicode.Greek}},
+	{"he", []*unicode.RangeTable{unicode.Hebrew}},
+}
+
+// DescriptionLanguages lists the ISO 639-1 codes FilterDescriptionLanguage
+// can detect
+var DescriptionLanguages = []string{"ar", "de", "el", "en", "es", "fr", "he", "it", "ja", "ko", "nl", "pt", "ru", "zh"}
+
+// detectLanguage guesses the language of a description. Text mostly in a
+// non-Latin script is taken to be in the script's language, so Cyrillic is
+// always "ru" and Han without kana "zh". Latin text is matched against
+// languageStopwords and is LanguageUndetermined when it is shorter than
+// minLanguageWords or two languages match equally well.
+func detectLanguage(text string) string {
+	var latin int
+	scripts := make([]int, len(scriptLanguages))
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+			continue
+		}
+		for i, script := range scriptLanguages {
+			if unicode.In(r, script.tables...) {
+				scripts[i]++
+				break
+			}
+		}
+	}
+
+	var nonLatin int
+	for _, count := range scripts {
+		nonLatin += count
+	}
+	if nonLatin > latin {
+		for i, count := range scripts {
+			if count > 0 {
+				return scriptLanguages[i].language
+			}
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) < minLanguageWords {
+		return LanguageUndetermined
+	}
+	best, bestScore, tied := LanguageUndetermined, 0, false
+	for _, language := range DescriptionLanguages {
+		stopwords, ok := languageStopwords[language]
+		if !ok {
+			continue
+		}
+		score := 0
+		for _, word := range words {
+			if contains(stopwords, word) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore, tied = language, score, false
+		} else if score > 0 && score == bestScore {
+			tied = true
+		}
+	}
+	if tied {
+		return LanguageUndetermined
+	}
+	return best
+}
+
+// operationLanguage detects the language of an operation's summary and
+// description and records it for GetDescriptionLanguages
+func (c *Converter) operationLanguage(path, method string, operation *openapi3.Operation) string {
+	language := detectLanguage(strings.TrimSpace(operation.Summary + "\n" + operation.Description))
+	c.descriptionLanguages = append(c.descriptionLanguages, models.OperationLanguage{
+		Path:     path,
+		Method:   strings.ToUpper(method),
+		Language: language,
+	})
+	return language
+}
+
+// validateDescriptionLanguage checks FilterDescriptionLanguage and
+// DescriptionLanguageMode
+func validateDescriptionLanguage(language, mode string) error {
+	if language != "" && !contains(DescriptionLanguages, language) {
+		return fmt.Errorf("unsupported description language %q, must be one of: %s", language, strings.Join(DescriptionLanguages, ", "))
+	}
+	if mode != "" && !contains(DescriptionLanguageModes, mode) {
+		return fmt.Errorf("unsupported description language mode %q, must be one of: %s", mode, strings.Join(DescriptionLanguageModes, ", "))
+	}
+	return nil
+}
+
+// reportDescriptionLanguages adds warnings listing the operations in
+// another language, which were skipped or tagged, and those whose language
+// couldn't be detected. The detected languages are sorted like the skipped
+// operations.
+func (c *Converter) reportDescriptionLanguages(otherLanguage []string) {
+	sort.Slice(c.descriptionLanguages, func(i, j int) bool {
+		if c.descriptionLanguages[i].Path != c.descriptionLanguages[j].Path {
+			return c.descriptionLanguages[i].Path < c.descriptionLanguages[j].Path
+		}
+		return c.descriptionLanguages[i].Method < c.descriptionLanguages[j].Method
+	})
+
+	if len(otherLanguage) > 0 {
+		sort.Strings(otherLanguage)
+		action := "skipped"
+		if c.options.DescriptionLanguageMode == DescriptionLanguageTag {
+			action = "tagged"
+		}
+		c.addWarning("filter_description_language: %s %d operation(s) whose description isn't in %s: %s", action, len(otherLanguage), c.options.FilterDescriptionLanguage, strings.Join(otherLanguage, ", "))
+	}
+
+	var undetermined []string
+	for _, detected := range c.descriptionLanguages {
+		if detected.Language == LanguageUndetermined {
+			undetermined = append(undetermined, fmt.Sprintf("%s %s", detected.Method, detected.Path))
+		}
+	}
+	if len(undetermined) > 0 {
+		c.addWarning("filter_description_language: kept %d operation(s) whose description is too short or ambiguous to detect its language: %s", len(undetermined), strings.Join(undetermined, ", "))
+	}
+}
+
+// GetDescriptionLanguages returns the language detected for each operation
+// checked by FilterDescriptionLanguage during the last conversion
+func (c *Converter) GetDescriptionLanguages() []models.OperationLanguage {
+	return c.descriptionLanguages
+}
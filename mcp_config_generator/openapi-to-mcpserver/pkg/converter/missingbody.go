@@ -0,0 +1,77 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// Treatments of request bodies without a schema supported by
+// ConvertOptions.MissingBodySchema
+const (
+	// MissingBodySchemaFreeForm takes the body as an open JSON object
+	MissingBodySchemaFreeForm = "free-form"
+	// MissingBodySchemaString takes the body as a raw string
+	MissingBodySchemaString = "string"
+	// MissingBodySchemaSkip leaves the operation out with a warning
+	MissingBodySchemaSkip = "skip"
+)
+
+// MissingBodySchemas lists the supported treatments of request bodies
+// without a schema
+var MissingBodySchemas = []string{MissingBodySchemaFreeForm, MissingBodySchemaString, MissingBodySchemaSkip}
+
+// missingBodyArgName is the argument that takes a request body without a
+// schema
+const missingBodyArgName = "body"
+
+// bodySchemaMissing reports whether an operation has a request body none of
+// whose content types declares a schema, e.g. `application/json: {}`
+func bodySchemaMissing(operation *openapi3.Operation) bool {
+	if operation.RequestBody == nil || operation.RequestBody.Value == nil {
+		return false
+	}
+	for _, mediaType := range operation.RequestBody.Value.Content {
+		if mediaType != nil && mediaType.Schema != nil && mediaType.Schema.Value != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// missingBodyArg is the argument that takes the whole request body of an
+// operation without a body schema: an open object, or a string with
+// MissingBodySchemaString
+func (c *Converter) missingBodyArg(operation *openapi3.Operation) models.Arg {
+	arg := models.Arg{
+		Name:        missingBodyArgName,
+		Description: "Request body, a JSON object of any shape",
+		Type:        "object",
+		Required:    operation.RequestBody.Value.Required,
+		Position:    "body",
+	}
+	if c.options.MissingBodySchema == MissingBodySchemaString {
+		arg.Description = "Raw request body, sent as is"
+		arg.Type = "string"
+	}
+	if operation.RequestBody.Value.Description != "" {
+		arg.Description = operation.RequestBody.Value.Description
+	}
+	return arg
+}
+
+// applyMissingBodyTemplate sends the body argument of an operation without
+// a body schema as the whole request body, under BodyWrapper when set,
+// instead of as a property of a JSON object.
+func (c *Converter) applyMissingBodyTemplate(tool *models.Tool) {
+	body := fmt.Sprintf("{{toJson .args.%s}}", missingBodyArgName)
+	if c.options.MissingBodySchema == MissingBodySchemaString && c.options.BodyWrapper == "" {
+		body = fmt.Sprintf("{{.args.%s}}", missingBodyArgName)
+	}
+	if c.options.BodyWrapper != "" {
+		body = fmt.Sprintf("{%s: %s}", strconv.Quote(c.options.BodyWrapper), body)
+	}
+	tool.RequestTemplate.Body = body
+}
@@ -0,0 +1,88 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// NullHandlings lists the supported ConvertOptions.NullHandling values
+var NullHandlings = []string{models.NullHandlingNullable, models.NullHandlingTypeArray}
+
+// nullHandling returns the form nullable arguments are written in,
+// following the source document when none is configured: its
+// jsonSchemaDialect when it names a known one, otherwise its OpenAPI version
+func (c *Converter) nullHandling() string {
+	if c.options.NullHandling != "" {
+		return c.options.NullHandling
+	}
+	doc := c.parser.GetDocument()
+	if dialect, ok := doc.Extensions["jsonSchemaDialect"].(string); ok {
+		switch {
+		case strings.Contains(dialect, "/oas/3.0"):
+			// The OpenAPI 3.0 Schema Object, which has nullable
+			return models.NullHandlingNullable
+		case strings.Contains(dialect, "/oas/3.1"), strings.Contains(dialect, "json-schema.org/"):
+			return models.NullHandlingTypeArray
+		}
+	}
+	if strings.HasPrefix(doc.OpenAPI, "3.1") {
+		return models.NullHandlingTypeArray
+	}
+	return models.NullHandlingNullable
+}
+
+// applyNullable marks the argument as nullable in the configured form.
+// Schemas that only express null through oneOf or anyOf are reported, since
+// their nullability can't be determined without their type.
+func (c *Converter) applyNullable(arg *models.Arg, schema *openapi3.Schema) {
+	if !schema.Nullable {
+		if schema.Type == "" && hasNullAlternative(schema) {
+			c.addWarningOnce("nullability of %q couldn't be determined from its oneOf/anyOf and was left out", arg.Name)
+		}
+		return
+	}
+	arg.Nullable = true
+	if c.nullHandling() == models.NullHandlingTypeArray {
+		if arg.Type == "" {
+			c.addWarningOnce("nullable %q has no type to add null to, marked nullable instead", arg.Name)
+			return
+		}
+		arg.NullAsType = true
+	}
+}
+
+// applyNullableProperty marks a nested property schema as nullable in the
+// configured form
+func (c *Converter) applyNullableProperty(property map[string]interface{}, schema *openapi3.Schema) {
+	if !schema.Nullable {
+		return
+	}
+	if c.nullHandling() == models.NullHandlingTypeArray && schema.Type != "" {
+		property["type"] = []interface{}{schema.Type, "null"}
+		return
+	}
+	property["nullable"] = true
+}
+
+// hasNullAlternative reports whether a oneOf or anyOf entry of the schema
+// is the null type
+func hasNullAlternative(schema *openapi3.Schema) bool {
+	for _, alternatives := range []openapi3.SchemaRefs{schema.OneOf, schema.AnyOf} {
+		for _, alternative := range alternatives {
+			if alternative.Value != nil && alternative.Value.Type == "null" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// warnUnresolvedTypes reports the 3.1 type arrays the parser had to leave
+// untyped
+func (c *Converter) warnUnresolvedTypes() {
+	for _, location := range c.parser.GetUnresolvedTypes() {
+		c.addWarning("schema at %s has several non-null types and was left untyped", location)
+	}
+}
@@ -0,0 +1,132 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// numericFormatTypes are the types StrictNumericConstraints gives untyped
+// schemas with a numeric format
+var numericFormatTypes = map[string]string{
+	"int32":  "integer",
+	"int64":  "integer",
+	"float":  "number",
+	"double": "number",
+}
+
+// applyNumericConstraints sets the type and numeric constraints of an
+// argument from its schema
+func (c *Converter) applyNumericConstraints(arg *models.Arg, schema *openapi3.Schema) {
+	location := fmt.Sprintf("%s argument %q", c.currentTool, arg.Name)
+	schemaType, constraints := c.numericConstraints(location, schema)
+	arg.Type = schemaType
+	for key, value := range constraints {
+		value := value
+		switch key {
+		case "minimum":
+			arg.Minimum = &value
+		case "maximum":
+			arg.Maximum = &value
+		case "exclusiveMinimum":
+			arg.ExclusiveMinimum = &value
+		case "exclusiveMaximum":
+			arg.ExclusiveMaximum = &value
+		case "multipleOf":
+			arg.MultipleOf = &value
+		}
+	}
+}
+
+// applyNumericProperty is applyNumericConstraints for a nested property
+// schema, where name is the dotted path of the property below the argument
+func (c *Converter) applyNumericProperty(property map[string]interface{}, schema *openapi3.Schema, name string) {
+	c.applyNumericSchema(property, schema, fmt.Sprintf("%s argument %q", c.currentTool, name))
+}
+
+// applyNumericSchema writes the type and numeric constraints of a schema to
+// its JSON Schema form
+func (c *Converter) applyNumericSchema(result map[string]interface{}, schema *openapi3.Schema, location string) {
+	schemaType, constraints := c.numericConstraints(location, schema)
+	if schemaType != schema.Type {
+		result["type"] = schemaType
+	}
+	for key, value := range constraints {
+		result[key] = value
+	}
+}
+
+// numericConstraints returns the type of a schema and its numeric
+// constraints by JSON Schema keyword. Constraints of non-numeric types are
+// dropped with a warning. With StrictNumericConstraints untyped schemas are
+// typed from their format, and integer bounds that aren't integral are
+// rounded inwards while such a multipleOf is dropped.
+func (c *Converter) numericConstraints(location string, schema *openapi3.Schema) (string, map[string]float64) {
+	schemaType := schema.Type
+	if schemaType == "" && c.options.StrictNumericConstraints {
+		schemaType = numericFormatTypes[schema.Format]
+	}
+
+	constraints := make(map[string]float64)
+	if schema.Min != nil {
+		if schema.ExclusiveMin {
+			constraints["exclusiveMinimum"] = *schema.Min
+		} else {
+			constraints["minimum"] = *schema.Min
+		}
+	}
+	if schema.Max != nil {
+		if schema.ExclusiveMax {
+			constraints["exclusiveMaximum"] = *schema.Max
+		} else {
+			constraints["maximum"] = *schema.Max
+		}
+	}
+	if schema.MultipleOf != nil {
+		constraints["multipleOf"] = *schema.MultipleOf
+	}
+	if len(constraints) == 0 {
+		return schemaType, nil
+	}
+
+	// Untyped schemas keep their constraints, which only apply to numbers
+	if schemaType != "" && schemaType != "integer" && schemaType != "number" {
+		keys := make([]string, 0, len(constraints))
+		for key := range constraints {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		c.addWarning("%s: %s only applies to numbers and was dropped from its %s type", location, strings.Join(keys, ", "), schemaType)
+		return schemaType, nil
+	}
+	if schemaType != "integer" || !c.options.StrictNumericConstraints {
+		return schemaType, constraints
+	}
+
+	if value, ok := constraints["multipleOf"]; ok && value != math.Trunc(value) {
+		c.addWarning("%s: multipleOf %v isn't an integer and was dropped from its integer type", location, value)
+		delete(constraints, "multipleOf")
+	}
+	for _, bound := range []struct {
+		exclusive, inclusive string
+		round                func(float64) float64
+	}{
+		{"exclusiveMinimum", "minimum", math.Ceil},
+		{"exclusiveMaximum", "maximum", math.Floor},
+	} {
+		for _, key := range []string{bound.inclusive, bound.exclusive} {
+			value, ok := constraints[key]
+			if !ok || value == math.Trunc(value) {
+				continue
+			}
+			delete(constraints, key)
+			constraints[bound.inclusive] = bound.round(value)
+			c.addWarning("%s: %s %v was rounded to %s %v for its integer type", location, key, value, bound.inclusive, bound.round(value))
+		}
+	}
+	return schemaType, constraints
+}
@@ -0,0 +1,108 @@
+package converter
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// Parameter name cases supported by ConvertOptions.ParamNameCase
+const (
+	CaseCamel = "camel"
+	CaseSnake = "snake"
+	CaseKebab = "kebab"
+)
+
+// ParamNameCases lists the supported parameter name cases
+var ParamNameCases = []string{CaseCamel, CaseSnake, CaseKebab}
+
+// normalizeArgNames renames the tool's top-level arguments to the configured
+// case and records each renamed argument's wire name in ParamMapping.
+// Arguments whose new names would collide keep their original names.
+func (c *Converter) normalizeArgNames(tool *models.Tool) {
+	normalized := make([]string, len(tool.Args))
+	owners := make(map[string][]string)
+	for i, arg := range tool.Args {
+		normalized[i] = convertCase(arg.Name, c.options.ParamNameCase)
+		owners[normalized[i]] = append(owners[normalized[i]], arg.Name)
+	}
+
+	var collisions []string
+	for name, originals := range owners {
+		if len(originals) > 1 {
+			collisions = append(collisions, name)
+		}
+	}
+	sort.Strings(collisions)
+	for _, name := range collisions {
+		c.addWarning("param_name_case: %s parameters %s all become %q, keeping their original names", tool.Name, strings.Join(owners[name], ", "), name)
+	}
+
+	for i := range tool.Args {
+		if len(owners[normalized[i]]) > 1 || normalized[i] == tool.Args[i].Name {
+			continue
+		}
+		if tool.ParamMapping == nil {
+			tool.ParamMapping = make(map[string]string)
+		}
+		tool.ParamMapping[normalized[i]] = tool.Args[i].Name
+		tool.Args[i].Name = normalized[i]
+	}
+}
+
+// convertCase rewrites a name in the given case. Words are split at
+// separators and at lower-to-upper case changes, keeping acronyms together,
+// so "userID", "user_id" and "User-Id" all become "userId" in camel case.
+func convertCase(name, nameCase string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+
+	switch nameCase {
+	case CaseSnake:
+		return strings.Join(words, "_")
+	case CaseKebab:
+		return strings.Join(words, "-")
+	default:
+		for i := 1; i < len(words); i++ {
+			runes := []rune(words[i])
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+		return strings.Join(words, "")
+	}
+}
+
+// splitWords splits a name into lower case words.
+func splitWords(name string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			previous := current[len(current)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// "fooBar" splits before B, "HTTPServer" splits before S
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Resolutions of a parameter declared at both the path and the operation
+// level, supported by ConvertOptions.ParameterConflictMode
+const (
+	// ParameterConflictOperationWins uses the operation's declaration, as
+	// OpenAPI specifies
+	ParameterConflictOperationWins = "operation-wins"
+	// ParameterConflictPathWins uses the path item's declaration
+	ParameterConflictPathWins = "path-wins"
+	// ParameterConflictError fails the conversion
+	ParameterConflictError = "error"
+)
+
+// ParameterConflictModes lists the supported resolutions of parameters
+// declared at both levels
+var ParameterConflictModes = []string{ParameterConflictOperationWins, ParameterConflictPathWins, ParameterConflictError}
+
+// operationParameters merges the parameters of the path item with those of
+// the operation. Parameters are identified by name and location, and each
+// one declared at both levels is resolved by ParameterConflictMode with a
+// warning. A parameter declared twice at the same level keeps its first
+// declaration, also with a warning.
+func (c *Converter) operationParameters(path, method string, operation *openapi3.Operation) (openapi3.Parameters, error) {
+	operationName := fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+	var pathParameters openapi3.Parameters
+	if pathItem := c.parser.GetPaths()[path]; pathItem != nil {
+		pathParameters = pathItem.Parameters
+	}
+
+	merged := openapi3.Parameters{}
+	positions := make(map[string]int)
+	levels := make(map[string]string)
+	add := func(paramRef *openapi3.ParameterRef, level string) error {
+		if paramRef == nil || paramRef.Value == nil {
+			return nil
+		}
+		param := paramRef.Value
+		key := param.In + " " + param.Name
+		i, ok := positions[key]
+		if !ok {
+			positions[key] = len(merged)
+			levels[key] = level
+			merged = append(merged, paramRef)
+			return nil
+		}
+		if levels[key] == level {
+			c.addWarning("%s declares %s parameter %q twice at the %s level, using the first", operationName, param.In, param.Name, level)
+			return nil
+		}
+
+		switch c.options.ParameterConflictMode {
+		case ParameterConflictError:
+			return fmt.Errorf("%s parameter %q is declared at both the path and the operation level", param.In, param.Name)
+		case ParameterConflictPathWins:
+			c.addWarning("parameter_conflict_mode: %s declares %s parameter %q at both the path and the operation level, using the path's", operationName, param.In, param.Name)
+		default:
+			merged[i] = paramRef
+			levels[key] = level
+			c.addWarning("parameter_conflict_mode: %s declares %s parameter %q at both the path and the operation level, using the operation's", operationName, param.In, param.Name)
+		}
+		return nil
+	}
+
+	for _, paramRef := range pathParameters {
+		if err := add(paramRef, "path"); err != nil {
+			return nil, err
+		}
+	}
+	for _, paramRef := range operation.Parameters {
+		if err := add(paramRef, "operation"); err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
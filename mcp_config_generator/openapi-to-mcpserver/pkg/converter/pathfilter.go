@@ -0,0 +1,77 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// globRegexp translates a path glob to an anchored regular expression: "*"
+// matches within one path segment, "**" matches across segments and "?"
+// matches one character other than "/".
+func globRegexp(glob string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**"):
+			pattern.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			pattern.WriteString("[^/]*")
+		case glob[i] == '?':
+			pattern.WriteString("[^/]")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	pattern.WriteString("$")
+	return regexp.MustCompile(pattern.String())
+}
+
+// pathFilters holds the compiled include and exclude filters of a conversion.
+// A path is selected when it matches an include filter, or there are none,
+// and matches no exclude filter.
+type pathFilters struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+func (c *Converter) compilePathFilters() pathFilters {
+	var filters pathFilters
+	if c.options.IncludePathRegex != nil {
+		filters.include = append(filters.include, c.options.IncludePathRegex)
+	}
+	for _, glob := range c.options.IncludePathGlobs {
+		filters.include = append(filters.include, globRegexp(glob))
+	}
+	if c.options.ExcludePathRegex != nil {
+		filters.exclude = append(filters.exclude, c.options.ExcludePathRegex)
+	}
+	for _, glob := range c.options.ExcludePathGlobs {
+		filters.exclude = append(filters.exclude, globRegexp(glob))
+	}
+	return filters
+}
+
+// active reports whether any path filter is set
+func (f pathFilters) active() bool {
+	return len(f.include) > 0 || len(f.exclude) > 0
+}
+
+// match reports whether the path matches an include filter and whether it
+// matches an exclude filter
+func (f pathFilters) match(path string) (included, excluded bool) {
+	for _, re := range f.include {
+		if re.MatchString(path) {
+			included = true
+			break
+		}
+	}
+	for _, re := range f.exclude {
+		if re.MatchString(path) {
+			excluded = true
+			break
+		}
+	}
+	return included, excluded
+}
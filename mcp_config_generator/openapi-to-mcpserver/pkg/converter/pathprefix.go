@@ -0,0 +1,38 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validatePathPrefix checks that a PathPrefix or StripPathPrefix value is an
+// absolute path
+func validatePathPrefix(name, prefix string) error {
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("%s %q must start with /", name, prefix)
+	}
+	return nil
+}
+
+// requestPath returns the path a tool calls: StripPathPrefix is removed
+// first, then PathPrefix is prepended. Prefixes only match whole segments,
+// so stripping /api leaves /apis/x alone.
+func (c *Converter) requestPath(path, method string) string {
+	if strip := strings.TrimSuffix(c.options.StripPathPrefix, "/"); strip != "" {
+		if path == strip {
+			path = "/"
+		} else if strings.HasPrefix(path, strip+"/") {
+			path = strings.TrimPrefix(path, strip)
+		} else {
+			c.addWarning("%s %s does not start with strip path prefix %q and was left unchanged", strings.ToUpper(method), path, c.options.StripPathPrefix)
+		}
+	}
+	if prefix := strings.TrimSuffix(c.options.PathPrefix, "/"); prefix != "" {
+		if path == "/" {
+			path = prefix
+		} else {
+			path = prefix + path
+		}
+	}
+	return path
+}
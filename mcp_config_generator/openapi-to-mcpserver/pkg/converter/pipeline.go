@@ -0,0 +1,114 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// Steps of the pipeline that transforms the configuration once every
+// operation is converted
+const (
+	// StepInitiallyDisabled marks the InitiallyDisabled tools as disabled
+	StepInitiallyDisabled = "initially_disabled"
+	// StepPropagateTags collects the tools' tags into the server's tags
+	StepPropagateTags = "propagate_tags"
+	// StepTemplate merges the template's server config, security schemes
+	// and tool settings such as headers
+	StepTemplate = "template"
+	// StepSortTools orders the tools by OperationOrder
+	StepSortTools = "sort_tools"
+	// StepSharedComponents writes the referenced schemas to the config's
+	// components and reports the resulting size
+	StepSharedComponents = "shared_components"
+)
+
+// DefaultPipelineOrder is the order the pipeline steps run in
+var DefaultPipelineOrder = []string{StepInitiallyDisabled, StepPropagateTags, StepTemplate, StepSortTools, StepSharedComponents}
+
+// pipelineDependencies lists the steps each step must run after. Shared
+// components reports the size of the finished config, so it comes last.
+var pipelineDependencies = map[string][]string{
+	StepSharedComponents: {StepInitiallyDisabled, StepPropagateTags, StepTemplate, StepSortTools},
+}
+
+// ResolvePipelineOrder returns the order the pipeline runs in for
+// ConvertOptions.PipelineOrder: the listed steps first, then the others in
+// their default order. Unknown and repeated steps and orders that run a step
+// before one it depends on are rejected.
+func ResolvePipelineOrder(order []string) ([]string, error) {
+	resolved := make([]string, 0, len(DefaultPipelineOrder))
+	for _, step := range order {
+		if !contains(DefaultPipelineOrder, step) {
+			return nil, fmt.Errorf("unsupported pipeline step %q, must be one of: %s", step, strings.Join(DefaultPipelineOrder, ", "))
+		}
+		if contains(resolved, step) {
+			return nil, fmt.Errorf("pipeline step %q is listed more than once", step)
+		}
+		resolved = append(resolved, step)
+	}
+	for _, step := range DefaultPipelineOrder {
+		if !contains(resolved, step) {
+			resolved = append(resolved, step)
+		}
+	}
+
+	position := make(map[string]int, len(resolved))
+	for i, step := range resolved {
+		position[step] = i
+	}
+	for _, step := range resolved {
+		for _, dependency := range pipelineDependencies[step] {
+			if position[dependency] > position[step] {
+				return nil, fmt.Errorf("pipeline step %q must run after %q", step, dependency)
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// GetPipeline returns the pipeline steps the last conversion applied, in
+// the order they ran. Steps whose option is off are left out.
+func (c *Converter) GetPipeline() []string {
+	return c.pipeline
+}
+
+// runPipeline applies the enabled pipeline steps to the configuration in
+// order. sources holds the operation of each tool and is kept in step with
+// the tools.
+func (c *Converter) runPipeline(order []string, config *models.MCPConfig, sources []toolSource) error {
+	for _, step := range order {
+		switch step {
+		case StepInitiallyDisabled:
+			if len(c.options.InitiallyDisabled) == 0 {
+				continue
+			}
+			c.applyInitiallyDisabled(config.Tools, sources)
+		case StepPropagateTags:
+			if !c.options.PropagateTags {
+				continue
+			}
+			config.Server.Tags = c.collectTags(config.Tools)
+		case StepTemplate:
+			if c.options.TemplatePath == "" {
+				continue
+			}
+			if err := c.applyTemplate(config); err != nil {
+				return fmt.Errorf("failed to apply template: %w", err)
+			}
+		case StepSortTools:
+			if err := c.sortTools(config.Tools, sources); err != nil {
+				return err
+			}
+		case StepSharedComponents:
+			if c.options.RefStrategy != RefStrategySharedComponents {
+				continue
+			}
+			config.Components = c.sharedComponents(config.Tools)
+			c.reportSharedComponentsSize(config)
+		}
+		c.pipeline = append(c.pipeline, step)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package converter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Schema reference strategies supported by ConvertOptions.RefStrategy
+const (
+	// RefStrategyInline copies referenced schemas into every tool
+	RefStrategyInline = "inline"
+	// RefStrategySharedComponents writes referenced schemas once under
+	// components.schemas and points to them with $ref
+	RefStrategySharedComponents = "shared-components"
+)
+
+// RefStrategies lists the supported schema reference strategies
+var RefStrategies = []string{RefStrategyInline, RefStrategySharedComponents}
+
+// componentSchemaPrefix starts the references to schemas of the document's
+// components
+const componentSchemaPrefix = "#/components/schemas/"
+
+// sharedRef returns the $ref an argument schema is written as, or false when
+// the schema is inlined: with the inline strategy, and for references that
+// are not to the document's own component schemas.
+func (c *Converter) sharedRef(schemaRef *openapi3.SchemaRef) (string, bool) {
+	if c.options.RefStrategy != RefStrategySharedComponents || schemaRef == nil {
+		return "", false
+	}
+	name := strings.TrimPrefix(schemaRef.Ref, componentSchemaPrefix)
+	if name == schemaRef.Ref || name == "" {
+		return "", false
+	}
+	if components := c.parser.GetDocument().Components; components == nil || components.Schemas[name] == nil {
+		return "", false
+	}
+	return schemaRef.Ref, true
+}
+
+// refSchema is the schema written in place of a shared schema
+func refSchema(ref string) map[string]interface{} {
+	return map[string]interface{}{"$ref": ref}
+}
+
+// sharedComponents builds the components section of a config from the
+// schemas its tools reference, including the schemas those reference in
+// turn. It returns nil when no tool references a shared schema.
+func (c *Converter) sharedComponents(tools []models.Tool) *models.Components {
+	var pending []string
+	for _, tool := range tools {
+		for _, arg := range tool.Args {
+			if arg.Ref != "" {
+				pending = append(pending, arg.Ref)
+			}
+			pending = collectRefs(arg.Items, pending)
+			pending = collectRefs(arg.Properties, pending)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	schemas := make(map[string]map[string]interface{})
+	for len(pending) > 0 {
+		ref := pending[0]
+		pending = pending[1:]
+		name := strings.TrimPrefix(ref, componentSchemaPrefix)
+		if _, ok := schemas[name]; ok {
+			continue
+		}
+		schema := c.componentSchema(ref, c.parser.GetDocument().Components.Schemas[name].Value)
+		schemas[name] = schema
+		pending = collectRefs(schema, pending)
+	}
+	return &models.Components{Schemas: schemas}
+}
+
+// componentSchema converts a component schema to the JSON Schema written
+// under components.schemas. Nested component references stay references, so
+// recursive schemas terminate. The location of the schema is used in
+// warnings.
+func (c *Converter) componentSchema(location string, schema *openapi3.Schema) map[string]interface{} {
+	result := make(map[string]interface{})
+	if schema == nil {
+		return result
+	}
+	if schema.Type != "" {
+		result["type"] = schema.Type
+	}
+	if schema.Description != "" {
+		result["description"] = schema.Description
+	}
+	if schema.Format != "" {
+		result["format"] = schema.Format
+	}
+	if len(schema.Enum) > 0 {
+		result["enum"] = schema.Enum
+	}
+	if len(schema.Required) > 0 {
+		required := make([]interface{}, len(schema.Required))
+		for i, name := range schema.Required {
+			required[i] = name
+		}
+		result["required"] = required
+	}
+	c.applyNumericSchema(result, schema, location)
+	c.applyNullableProperty(result, schema)
+
+	if schema.Items != nil && schema.Items.Value != nil {
+		result["items"] = c.nestedSchema(location+"/items", schema.Items)
+	}
+	if len(schema.Properties) > 0 {
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		properties := make(map[string]interface{}, len(names))
+		for _, name := range names {
+			if schema.Properties[name].Value != nil {
+				properties[name] = c.nestedSchema(location+"/properties/"+name, schema.Properties[name])
+			}
+		}
+		result["properties"] = properties
+	}
+	return result
+}
+
+// nestedSchema is a property or items schema of a component: a reference
+// when it is a shared schema itself, otherwise its inlined conversion
+func (c *Converter) nestedSchema(location string, schemaRef *openapi3.SchemaRef) map[string]interface{} {
+	if ref, ok := c.sharedRef(schemaRef); ok {
+		return refSchema(ref)
+	}
+	return c.componentSchema(location, schemaRef.Value)
+}
+
+// collectRefs appends the $ref values found in a schema value to refs
+func collectRefs(value interface{}, refs []string) []string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			refs = append(refs, ref)
+		}
+		for _, item := range v {
+			refs = collectRefs(item, refs)
+		}
+	case []interface{}:
+		for _, item := range v {
+			refs = collectRefs(item, refs)
+		}
+	}
+	return refs
+}
+
+// reportSharedComponentsSize warns with the size of the config compared to
+// the same conversion with inlined schemas
+func (c *Converter) reportSharedComponentsSize(config *models.MCPConfig) {
+	options := c.options
+	options.RefStrategy = RefStrategyInline
+	options.ToolCache = nil
+	inlineConfig, err := NewConverter(c.parser, options).Convert()
+	if err != nil {
+		return
+	}
+	shared, err := yaml.Marshal(config)
+	if err != nil {
+		return
+	}
+	inline, err := yaml.Marshal(inlineConfig)
+	if err != nil {
+		return
+	}
+	c.addWarning("shared components: config is %d bytes against %d bytes with inlined schemas (%+d bytes)", len(shared), len(inline), len(shared)-len(inline))
+}
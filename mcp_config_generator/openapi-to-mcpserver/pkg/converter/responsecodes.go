@@ -0,0 +1,103 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Keywords of ConvertOptions.ResponseCodesInDescription, each given as the
+// only entry
+const (
+	// ResponseCodesNone leaves response codes out of the descriptions
+	ResponseCodesNone = "none"
+	// ResponseCodesSuccessOnly summarizes the 2xx responses
+	ResponseCodesSuccessOnly = "success-only"
+	// ResponseCodesAll summarizes every declared response
+	ResponseCodesAll = "all"
+)
+
+// ResponseCodeKeywords lists the keywords of ResponseCodesInDescription
+var ResponseCodeKeywords = []string{ResponseCodesNone, ResponseCodesSuccessOnly, ResponseCodesAll}
+
+// responseCodePattern matches the codes ResponseCodesInDescription may
+// list: a status code, a range such as 4XX, or default
+var responseCodePattern = regexp.MustCompile(`^([1-5][0-9][0-9]|[1-5]XX|default)$`)
+
+// ValidateResponseCodes checks that ResponseCodesInDescription is a single
+// keyword or a list of response codes
+func ValidateResponseCodes(codes []string) error {
+	if len(codes) == 1 && contains(ResponseCodeKeywords, codes[0]) {
+		return nil
+	}
+	for _, code := range codes {
+		if !responseCodePattern.MatchString(code) {
+			return fmt.Errorf("unsupported response code %q in description codes, must be a status code, a range such as 4XX, default, or one of: %s", code, strings.Join(ResponseCodeKeywords, ", "))
+		}
+	}
+	return nil
+}
+
+// responseCodeSelected reports whether ResponseCodesInDescription selects a
+// declared response code. A listed range such as 4XX selects the codes in
+// it as well as the range itself.
+func (c *Converter) responseCodeSelected(code string) bool {
+	codes := c.options.ResponseCodesInDescription
+	if len(codes) == 1 {
+		switch codes[0] {
+		case ResponseCodesNone:
+			return false
+		case ResponseCodesSuccessOnly:
+			return strings.HasPrefix(code, "2")
+		case ResponseCodesAll:
+			return true
+		}
+	}
+	for _, selected := range codes {
+		if strings.EqualFold(selected, code) ||
+			(strings.HasSuffix(selected, "XX") && len(code) == 3 && code[0] == selected[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeResponseCodes appends a summary of the selected response codes
+// and the first line of their descriptions to a tool description, e.g.
+// "Responses: 200 The pet; 404 Pet not found".
+func (c *Converter) describeResponseCodes(description string, operation *openapi3.Operation) string {
+	if len(c.options.ResponseCodesInDescription) == 0 || operation.Responses == nil {
+		return description
+	}
+
+	codes := make([]string, 0, len(operation.Responses))
+	for code := range operation.Responses {
+		if c.responseCodeSelected(code) {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return description
+	}
+	sort.Strings(codes)
+
+	summaries := make([]string, 0, len(codes))
+	for _, code := range codes {
+		summary := code
+		if response := operation.Responses[code]; response != nil && response.Value != nil && response.Value.Description != nil {
+			if line, _, _ := strings.Cut(strings.TrimSpace(*response.Value.Description), "\n"); line != "" {
+				summary += " " + strings.TrimSpace(line)
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	responses := "Responses: " + strings.Join(summaries, "; ")
+	if description == "" {
+		return responses
+	}
+	return description + "\n\n" + responses
+}
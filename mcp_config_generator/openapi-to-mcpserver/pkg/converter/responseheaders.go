@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// commonResponseHeaders are headers APIs often return without documenting
+// them. Operations that mention one in their descriptions without declaring
+// it are reported.
+var commonResponseHeaders = []string{
+	"ETag",
+	"Link",
+	"Location",
+	"Retry-After",
+	"X-Next-Cursor",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"X-Total-Count",
+}
+
+// applyResponseHeaders lists the headers documented on the operation's
+// success response in the tool's responseHeaders and appends them to its
+// description. The lowest 2xx response with headers is used, falling back
+// to the default response.
+func (c *Converter) applyResponseHeaders(tool *models.Tool, operation *openapi3.Operation) {
+	response := headerResponse(operation)
+	var headers []models.ResponseHeader
+	if response != nil {
+		names := make([]string, 0, len(response.Headers))
+		for name := range response.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			headerRef := response.Headers[name]
+			if headerRef == nil || headerRef.Value == nil {
+				continue
+			}
+			header := models.ResponseHeader{Name: name, Description: headerRef.Value.Description}
+			if headerRef.Value.Schema != nil && headerRef.Value.Schema.Value != nil {
+				header.Type = headerRef.Value.Schema.Value.Type
+			}
+			headers = append(headers, header)
+		}
+	}
+
+	c.checkUndocumentedHeaders(tool.Name, operation, headers)
+	if len(headers) == 0 {
+		return
+	}
+
+	tool.ResponseHeaders = headers
+	lines := []string{"Response headers:"}
+	for _, header := range headers {
+		line := "- " + header.Name
+		if header.Type != "" {
+			line += fmt.Sprintf(" (%s)", header.Type)
+		}
+		if header.Description != "" {
+			line += ": " + header.Description
+		}
+		lines = append(lines, line)
+	}
+	if tool.Description == "" {
+		tool.Description = strings.Join(lines, "\n")
+	} else {
+		tool.Description += "\n\n" + strings.Join(lines, "\n")
+	}
+}
+
+// headerResponse returns the response whose headers describe the tool's
+// output: the lowest 2xx response declaring headers, otherwise the default
+// response
+func headerResponse(operation *openapi3.Operation) *openapi3.Response {
+	if operation.Responses == nil {
+		return nil
+	}
+	codes := make([]string, 0, len(operation.Responses))
+	for code := range operation.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if responseRef := operation.Responses[code]; responseRef != nil && responseRef.Value != nil && len(responseRef.Value.Headers) > 0 {
+			return responseRef.Value
+		}
+	}
+	if responseRef := operation.Responses["default"]; responseRef != nil && responseRef.Value != nil && len(responseRef.Value.Headers) > 0 {
+		return responseRef.Value
+	}
+	return nil
+}
+
+// checkUndocumentedHeaders warns about common response headers that the
+// operation's summary, description or response descriptions mention but
+// that are missing from the documented headers
+func (c *Converter) checkUndocumentedHeaders(toolName string, operation *openapi3.Operation, documented []models.ResponseHeader) {
+	texts := []string{operation.Summary, operation.Description}
+	for _, responseRef := range operation.Responses {
+		if responseRef != nil && responseRef.Value != nil && responseRef.Value.Description != nil {
+			texts = append(texts, *responseRef.Value.Description)
+		}
+	}
+	text := strings.ToLower(strings.Join(texts, "\n"))
+
+	var missing []string
+	for _, name := range commonResponseHeaders {
+		if !strings.Contains(text, strings.ToLower(name)) {
+			continue
+		}
+		found := false
+		for _, header := range documented {
+			if strings.EqualFold(header.Name, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		c.addWarning("%s mentions undocumented response header(s) %s", toolName, strings.Join(missing, ", "))
+	}
+}
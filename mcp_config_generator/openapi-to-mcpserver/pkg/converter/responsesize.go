@@ -0,0 +1,63 @@
+package converter
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// responseSizeExtension sets the default response size hint of an
+// operation: a number of bytes or "paginate"
+const responseSizeExtension = "x-response-size"
+
+// applyResponseSizeHint sets the tool's response size hint from
+// ConvertOptions.ResponseSizeHints, falling back to the operation's
+// x-response-size extension. Invalid extensions are ignored with a warning.
+func (c *Converter) applyResponseSizeHint(tool *models.Tool, operationID string, operation *openapi3.Operation) {
+	if hint, ok := c.options.ResponseSizeHints[operationID]; ok {
+		tool.ResponseSize = &hint
+		return
+	}
+
+	extension, ok := operation.Extensions[responseSizeExtension]
+	if !ok {
+		return
+	}
+	var hint models.ResponseSizeHint
+	data, err := json.Marshal(extension)
+	if err == nil {
+		err = json.Unmarshal(data, &hint)
+	}
+	if err != nil {
+		c.addWarning("%s of operation %q was ignored: %v", responseSizeExtension, operationID, err)
+		return
+	}
+	tool.ResponseSize = &hint
+}
+
+// checkResponseSizeHints warns about hints for operationIds the document
+// doesn't have. Operations left out by filters are known and not reported.
+func (c *Converter) checkResponseSizeHints() {
+	if len(c.options.ResponseSizeHints) == 0 {
+		return
+	}
+	known := make(map[string]bool)
+	for path, pathItem := range c.parser.GetPaths() {
+		for method, operation := range getOperations(pathItem) {
+			known[c.parser.GetOperationID(path, method, operation)] = true
+		}
+	}
+
+	var unknown []string
+	for operationID := range c.options.ResponseSizeHints {
+		if !known[operationID] {
+			unknown = append(unknown, operationID)
+		}
+	}
+	sort.Strings(unknown)
+	for _, operationID := range unknown {
+		c.addWarning("response_size_hint: no operation with operationId %q", operationID)
+	}
+}
@@ -0,0 +1,26 @@
+package converter
+
+import (
+	"sort"
+	"strings"
+)
+
+// safeMethods are the HTTP methods SafeMode keeps, since they don't change
+// anything on the server
+var safeMethods = []string{"get", "head", "options"}
+
+// droppedBySafeMode reports whether SafeMode leaves out an operation of the
+// method
+func (c *Converter) droppedBySafeMode(method string) bool {
+	return c.options.SafeMode && !contains(safeMethods, method)
+}
+
+// reportSafeMode adds a warning listing the mutating operations SafeMode
+// left out
+func (c *Converter) reportSafeMode(dropped []string) {
+	if len(dropped) == 0 {
+		return
+	}
+	sort.Strings(dropped)
+	c.addWarning("safe_mode: dropped %d mutating operation(s): %s", len(dropped), strings.Join(dropped, ", "))
+}
@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// defaultSchemaDepth is how many levels of nested schemas are expanded
+// without a MaxSchemaDepth
+const defaultSchemaDepth = 10
+
+// schemaDepthNote is appended to the description of a schema whose nested
+// properties were cut by MaxSchemaDepth
+const schemaDepthNote = "(nested properties omitted: schema depth limit reached)"
+
+// argSchemaDepth returns how many levels of an argument schema are expanded,
+// the argument itself being the first.
+func (c *Converter) argSchemaDepth() int {
+	if c.options.MaxSchemaDepth > 0 && c.options.MaxSchemaDepth < defaultSchemaDepth {
+		return c.options.MaxSchemaDepth
+	}
+	return defaultSchemaDepth
+}
+
+// applyArgProperties sets the properties of an object argument, expanding
+// nested object properties down to the schema depth. location names the
+// argument in warnings.
+func (c *Converter) applyArgProperties(arg *models.Arg, schema *openapi3.Schema, location string) {
+	if len(schema.Properties) == 0 {
+		return
+	}
+	if c.argSchemaDepth() <= 1 {
+		c.noteSchemaCut(&arg.Description)
+		return
+	}
+	arg.Properties = c.propertySchemas(schema, location, 2)
+}
+
+// propertySchemas converts the properties of schema, found at depth.
+func (c *Converter) propertySchemas(schema *openapi3.Schema, location string, depth int) map[string]interface{} {
+	properties := make(map[string]interface{}, len(schema.Properties))
+	for name, propRef := range schema.Properties {
+		if ref, ok := c.sharedRef(propRef); ok {
+			properties[name] = refSchema(ref)
+			continue
+		}
+		if propRef.Value == nil {
+			continue
+		}
+		property := map[string]interface{}{
+			"type": propRef.Value.Type,
+		}
+		if propRef.Value.Description != "" {
+			property["description"] = propRef.Value.Description
+		}
+		c.applyNumericProperty(property, propRef.Value, location+"."+name)
+		c.applyNullableProperty(property, propRef.Value)
+
+		if propRef.Value.Type == "object" && len(propRef.Value.Properties) > 0 {
+			if depth < c.argSchemaDepth() {
+				property["properties"] = c.propertySchemas(propRef.Value, location+"."+name, depth+1)
+			} else if c.options.MaxSchemaDepth > 0 {
+				description, _ := property["description"].(string)
+				c.noteSchemaCut(&description)
+				property["description"] = description
+			}
+		}
+		properties[name] = property
+	}
+	return properties
+}
+
+// noteSchemaCut notes in a description that nested properties were cut and
+// flags the schemas of the current operation as truncated.
+func (c *Converter) noteSchemaCut(description *string) {
+	*description = strings.TrimSpace(*description + " " + schemaDepthNote)
+	c.schemaTruncated = true
+}
@@ -0,0 +1,52 @@
+package converter
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// DefaultSecretPlaceholder is the placeholder written as each security
+// scheme's defaultCredential when no SecretPlaceholderTemplate is given,
+// e.g. ${BEARER_AUTH} for the BearerAuth scheme
+const DefaultSecretPlaceholder = `{{printf "${%s}" .EnvName}}`
+
+var defaultSecretPlaceholderTemplate = template.Must(template.New("secret_placeholder").Parse(DefaultSecretPlaceholder))
+
+// SecretPlaceholderData holds the security scheme fields available to
+// secret placeholder templates
+type SecretPlaceholderData struct {
+	// SchemeName is the scheme's name in components.securitySchemes
+	SchemeName string
+	// EnvName is SchemeName in upper snake case, e.g. API_KEY_AUTH for
+	// ApiKeyAuth
+	EnvName string
+	Type    string
+	Scheme  string
+	In      string
+	Name    string
+}
+
+// secretPlaceholder renders the defaultCredential placeholder of a security
+// scheme
+func (c *Converter) secretPlaceholder(scheme models.SecurityScheme) (string, error) {
+	tmpl := c.options.SecretPlaceholderTemplate
+	if tmpl == nil {
+		tmpl = defaultSecretPlaceholderTemplate
+	}
+	data := SecretPlaceholderData{
+		SchemeName: scheme.ID,
+		EnvName:    strings.ToUpper(convertCase(scheme.ID, CaseSnake)),
+		Type:       scheme.Type,
+		Scheme:     scheme.Scheme,
+		In:         scheme.In,
+		Name:       scheme.Name,
+	}
+
+	var placeholder strings.Builder
+	if err := tmpl.Execute(&placeholder, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(placeholder.String()), nil
+}
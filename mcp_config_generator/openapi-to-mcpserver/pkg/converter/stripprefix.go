@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// stripOperationIDPrefixes works out the tool names left once
+// OperationIDStripPrefix is removed from the start of each operationId.
+// Operations whose stripped names would clash with another tool keep their
+// operationIds with a warning, and an operationId the prefix would empty is
+// kept as it is. With StrictOperationIDs the operationIds that don't start
+// with a match are reported as a warning.
+func (c *Converter) stripOperationIDPrefixes(duplicates map[*openapi3.Operation]bool) {
+	c.strippedNames = nil
+	pattern := c.options.OperationIDStripPrefix
+	if pattern == nil {
+		return
+	}
+
+	paths := make([]string, 0, len(c.parser.GetPaths()))
+	for path := range c.parser.GetPaths() {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	stripped := make(map[*openapi3.Operation]string)
+	originals := make(map[*openapi3.Operation]string)
+	owners := make(map[string][]*openapi3.Operation)
+	var unmatched []string
+	for _, path := range paths {
+		operations := getOperations(c.parser.GetPaths()[path])
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			operation := operations[method]
+			if duplicates[operation] {
+				continue
+			}
+			operationID := c.parser.GetOperationID(path, method, operation)
+			originals[operation] = operationID
+			name := operationID
+			if match := pattern.FindStringIndex(operationID); match != nil && match[0] == 0 && match[1] < len(operationID) {
+				name = operationID[match[1]:]
+				stripped[operation] = name
+			} else if match == nil || match[0] != 0 {
+				unmatched = append(unmatched, operationID)
+			}
+			owners[name] = append(owners[name], operation)
+		}
+	}
+
+	names := make([]string, 0, len(owners))
+	for name := range owners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if len(owners[name]) < 2 {
+			continue
+		}
+		var clashing []string
+		for _, operation := range owners[name] {
+			if _, ok := stripped[operation]; ok {
+				delete(stripped, operation)
+				clashing = append(clashing, originals[operation])
+			}
+		}
+		if len(clashing) > 0 {
+			c.addWarning("operation_id_strip_prefix: %s would become %q like another tool, keeping the operationId", strings.Join(clashing, ", "), name)
+		}
+	}
+
+	if c.options.StrictOperationIDs && len(unmatched) > 0 {
+		sort.Strings(unmatched)
+		c.addWarning("operation_id_strip_prefix: %d operationId(s) don't start with %q: %s", len(unmatched), pattern.String(), strings.Join(unmatched, ", "))
+	}
+	c.strippedNames = stripped
+}
+
+// baseToolName is the tool name of an operation before ToolNamePrefix is
+// added: its operationId, without the stripped prefix
+func (c *Converter) baseToolName(operationID string, operation *openapi3.Operation) string {
+	if name, ok := c.strippedNames[operation]; ok {
+		return name
+	}
+	return operationID
+}
@@ -0,0 +1,244 @@
+package converter
+
+import (
+	"math"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// maxExampleDepth limits how deep synthesized object and array examples go
+const maxExampleDepth = 3
+
+// formatExamples are the synthesized values of string formats
+var formatExamples = map[string]string{
+	"date":          "2024-01-15",
+	"date-time":     "2024-01-15T09:30:00Z",
+	"time":          "09:30:00",
+	"duration":      "PT1H",
+	"email":         "user@example.com",
+	"idn-email":     "user@example.com",
+	"hostname":      "example.com",
+	"idn-hostname":  "example.com",
+	"ipv4":          "192.0.2.1",
+	"ipv6":          "2001:db8::1",
+	"uri":           "https://example.com",
+	"url":           "https://example.com",
+	"iri":           "https://example.com",
+	"uri-reference": "/example",
+	"uuid":          "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"byte":          "ZXhhbXBsZQ==",
+}
+
+// sensitiveNameParts mark argument names whose values are credentials or
+// personal data, which get no synthesized example
+var sensitiveNameParts = []string{
+	"password", "passwd", "secret", "token", "apikey", "auth", "credential",
+	"session", "cookie", "privatekey", "signature", "ssn", "cvv", "cardnumber",
+}
+
+// applySynthesizedExample gives an argument without an example in the spec
+// one generated from its schema. The value only depends on the schema, so
+// conversions are stable. Sensitive-looking arguments and schemas no value
+// can be generated for, such as strings with a pattern, are left as they are.
+func (c *Converter) applySynthesizedExample(arg *models.Arg, schema *openapi3.Schema, hasExample bool) {
+	if !c.options.SynthesizeExamples || hasExample || schema.Example != nil || isSensitiveArg(arg.Name, schema) {
+		return
+	}
+	if example, ok := synthesizeExample(schema, 0); ok {
+		arg.Examples = []interface{}{example}
+	}
+}
+
+// reportSynthesizedExamples adds a warning with the number of arguments that
+// were given a synthesized example
+func (c *Converter) reportSynthesizedExamples(tools []models.Tool) {
+	count := 0
+	for _, tool := range tools {
+		for _, arg := range tool.Args {
+			if len(arg.Examples) > 0 {
+				count++
+			}
+		}
+	}
+	if count > 0 {
+		c.addWarning("synthesized %d argument example(s)", count)
+	}
+}
+
+// isSensitiveArg reports whether an argument looks like it holds a secret or
+// personal data, by its name or a password format
+func isSensitiveArg(name string, schema *openapi3.Schema) bool {
+	if schema.Format == "password" {
+		return true
+	}
+	normalized := strings.NewReplacer("-", "", "_", "", ".", "", " ", "").Replace(strings.ToLower(name))
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(normalized, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// synthesizeExample returns a value valid against schema: its first enum
+// value, its default, or one derived from its type, format and bounds
+func synthesizeExample(schema *openapi3.Schema, depth int) (interface{}, bool) {
+	for _, value := range schema.Enum {
+		if value != nil {
+			return value, true
+		}
+	}
+	if schema.Default != nil {
+		return schema.Default, true
+	}
+
+	schemaType := schema.Type
+	if schemaType == "" {
+		schemaType = numericFormatTypes[schema.Format]
+	}
+	switch schemaType {
+	case "string":
+		return stringExample(schema)
+	case "integer":
+		return numberExample(schema, true)
+	case "number":
+		return numberExample(schema, false)
+	case "boolean":
+		return true, true
+	case "array":
+		return arrayExample(schema, depth)
+	case "object":
+		return objectExample(schema, depth)
+	}
+	return nil, false
+}
+
+// stringExample returns the example of the schema's format, or "example"
+// padded or cut to the allowed length. Patterns can't be satisfied in
+// general, so patterned strings without a known format get none.
+func stringExample(schema *openapi3.Schema) (interface{}, bool) {
+	value, ok := formatExamples[schema.Format]
+	if !ok {
+		if schema.Pattern != "" || schema.Format == "binary" {
+			return nil, false
+		}
+		value = "example"
+		if length := uint64(len(value)); length < schema.MinLength {
+			value += strings.Repeat("x", int(schema.MinLength-length))
+		}
+		if schema.MaxLength != nil && uint64(len(value)) > *schema.MaxLength {
+			value = value[:*schema.MaxLength]
+		}
+	}
+	length := uint64(len(value))
+	if length < schema.MinLength || (schema.MaxLength != nil && length > *schema.MaxLength) {
+		return nil, false
+	}
+	return value, true
+}
+
+// numberExample returns the lowest value the bounds allow, 1 for integers
+// and 1.5 for numbers without bounds, rounded up to multipleOf
+func numberExample(schema *openapi3.Schema, integer bool) (interface{}, bool) {
+	step := 1.0
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		step = *schema.MultipleOf
+	}
+
+	var value float64
+	switch {
+	case schema.Min != nil && schema.Max != nil && !integer:
+		value = (*schema.Min + *schema.Max) / 2
+	case schema.Min != nil:
+		value = *schema.Min
+		if schema.ExclusiveMin {
+			value += step
+		}
+	case schema.Max != nil:
+		value = math.Min(*schema.Max, 1)
+		if schema.ExclusiveMax && value == *schema.Max {
+			value -= step
+		}
+	case integer:
+		value = 1
+	default:
+		value = 1.5
+	}
+	if integer {
+		value = math.Ceil(value)
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		value = math.Ceil(value/step) * step
+	}
+
+	if schema.Min != nil && (value < *schema.Min || (schema.ExclusiveMin && value == *schema.Min)) {
+		return nil, false
+	}
+	if schema.Max != nil && (value > *schema.Max || (schema.ExclusiveMax && value == *schema.Max)) {
+		return nil, false
+	}
+	if integer && value != math.Trunc(value) {
+		return nil, false
+	}
+	if integer {
+		return int64(value), true
+	}
+	return value, true
+}
+
+// arrayExample repeats the example of the items as often as minItems asks,
+// which unique items can't do beyond one
+func arrayExample(schema *openapi3.Schema, depth int) (interface{}, bool) {
+	if depth >= maxExampleDepth || schema.Items == nil || schema.Items.Value == nil {
+		return nil, false
+	}
+	item, ok := synthesizeExample(schema.Items.Value, depth+1)
+	if !ok {
+		return nil, false
+	}
+	count := schema.MinItems
+	if count == 0 {
+		count = 1
+	}
+	if (schema.UniqueItems && count > 1) || (schema.MaxItems != nil && count > *schema.MaxItems) {
+		return nil, false
+	}
+	items := make([]interface{}, count)
+	for i := range items {
+		items[i] = item
+	}
+	return items, true
+}
+
+// objectExample builds an object of its properties' examples, leaving out
+// sensitive ones. An object whose required properties can't all be filled
+// gets none.
+func objectExample(schema *openapi3.Schema, depth int) (interface{}, bool) {
+	if depth >= maxExampleDepth || len(schema.Properties) == 0 {
+		return nil, false
+	}
+	object := make(map[string]interface{})
+	for name, ref := range schema.Properties {
+		property := ref.Value
+		var value interface{}
+		ok := false
+		if property != nil && !isSensitiveArg(name, property) {
+			if property.Example != nil {
+				value, ok = property.Example, true
+			} else {
+				value, ok = synthesizeExample(property, depth+1)
+			}
+		}
+		if ok {
+			object[name] = value
+		} else if contains(schema.Required, name) {
+			return nil, false
+		}
+	}
+	if len(object) == 0 {
+		return nil, false
+	}
+	return object, true
+}
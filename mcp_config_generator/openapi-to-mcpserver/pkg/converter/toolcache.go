@@ -0,0 +1,315 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// toolCacheKey hashes everything the tool of an operation depends on: the
+// options that affect single tools, the document-level servers, security and
+// components, and the operation with its path-level servers and parameters.
+// Referenced components are marshalled as $ref, which is why all components
+// are part of the document hash.
+func (c *Converter) toolCacheKey(path, method string, operation *openapi3.Operation) (string, error) {
+	if c.documentHash == "" {
+		doc := c.parser.GetDocument()
+		data, err := json.Marshal(struct {
+			Servers    openapi3.Servers
+			Security   openapi3.SecurityRequirements
+			Components *openapi3.Components
+		}{doc.Servers, doc.Security, doc.Components})
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		c.documentHash = hex.EncodeToString(sum[:])
+	}
+
+	var descriptionTemplate string
+	if c.options.DescriptionTemplate != nil && c.options.DescriptionTemplate.Tree != nil {
+		descriptionTemplate = c.options.DescriptionTemplate.Tree.Root.String()
+	}
+	var responseSizeHint *models.ResponseSizeHint
+	if hint, ok := c.options.ResponseSizeHints[c.parser.GetOperationID(path, method, operation)]; ok {
+		responseSizeHint = &hint
+	}
+	var pathServers openapi3.Servers
+	var pathParameters openapi3.Parameters
+	if pathItem := c.parser.GetPaths()[path]; pathItem != nil {
+		pathServers = pathItem.Servers
+		pathParameters = pathItem.Parameters
+	}
+
+	data, err := json.Marshal(struct {
+		Document              string
+		Path                  string
+		Method                string
+		Operation             *openapi3.Operation
+		PathServers           openapi3.Servers
+		PathParameters        openapi3.Parameters
+		ToolNamePrefix        string
+		DescriptionTemplate   string
+		PreferredRequestMedia []string
+		MaxSchemaDepth        int
+		PropagateTags         bool
+		AnnotateAuth          bool
+		PromoteExamples       bool
+		ForceRequired         []string
+		ParamNameCase         string
+		FormatMapping         map[string]models.FormatConstraint
+		EnumDescriptions      bool
+		ResponseHeaders       bool
+		AnnotateSource        bool
+		DefaultContentType    string
+		StripPathPrefix       string
+		PathPrefix            string
+		NullHandling          string
+		DefaultArrayStyle     string
+		RefStrategy           string
+		ResponseSizeHint      *models.ResponseSizeHint
+		DescriptionSource     string
+		StrictNumeric         bool
+		BodyWrapper           string
+		EmptyParamHandling    string
+		SynthesizeExamples    bool
+		CoerceTypes           map[string]string
+		BaseToolName          string
+		MissingBodySchema     string
+		ResponseCodes         []string
+		MaxEnumValues         int
+		EnumOverflow          string
+		ExtensionPassthrough  []string
+		ParameterConflictMode string
+	}{
+		Document:              c.documentHash,
+		Path:                  path,
+		Method:                strings.ToLower(method),
+		Operation:             operation,
+		PathServers:           pathServers,
+		PathParameters:        pathParameters,
+		ToolNamePrefix:        c.options.ToolNamePrefix,
+		DescriptionTemplate:   descriptionTemplate,
+		PreferredRequestMedia: c.options.PreferredRequestMedia,
+		MaxSchemaDepth:        c.options.MaxSchemaDepth,
+		PropagateTags:         c.options.PropagateTags,
+		AnnotateAuth:          c.options.AnnotateAuth,
+		PromoteExamples:       c.options.PromoteExamplesToDefaults,
+		ForceRequired:         c.options.ForceRequired[c.parser.GetOperationID(path, method, operation)],
+		ParamNameCase:         c.options.ParamNameCase,
+		FormatMapping:         c.options.FormatMapping,
+		EnumDescriptions:      c.options.IncludeEnumDescriptions,
+		ResponseHeaders:       c.options.IncludeResponseHeaders,
+		AnnotateSource:        c.options.AnnotateSource,
+		DefaultContentType:    c.options.DefaultRequestContentType,
+		StripPathPrefix:       c.options.StripPathPrefix,
+		PathPrefix:            c.options.PathPrefix,
+		NullHandling:          c.nullHandling(),
+		DefaultArrayStyle:     c.options.DefaultArrayStyle,
+		RefStrategy:           c.options.RefStrategy,
+		ResponseSizeHint:      responseSizeHint,
+		DescriptionSource:     c.options.DescriptionSource,
+		StrictNumeric:         c.options.StrictNumericConstraints,
+		BodyWrapper:           c.options.BodyWrapper,
+		EmptyParamHandling:    c.options.EmptyParamHandling,
+		SynthesizeExamples:    c.options.SynthesizeExamples,
+		CoerceTypes:           c.options.CoerceTypes,
+		BaseToolName:          c.baseToolName(c.parser.GetOperationID(path, method, operation), operation),
+		MissingBodySchema:     c.options.MissingBodySchema,
+		ResponseCodes:         c.options.ResponseCodesInDescription,
+		MaxEnumValues:         c.options.MaxEnumValues,
+		EnumOverflow:          c.options.EnumOverflow,
+		ExtensionPassthrough:  c.options.ExtensionPassthrough,
+		ParameterConflictMode: c.options.ParameterConflictMode,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// convertOperationCached converts an operation through the ToolCache. A hit
+// replays the warnings and counters the original conversion produced; a miss
+// records them along with the tool.
+func (c *Converter) convertOperationCached(path, method string, operation *openapi3.Operation) (*models.Tool, error) {
+	key, err := c.toolCacheKey(path, method, operation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute tool cache key: %w", err)
+	}
+
+	if cached, ok := c.options.ToolCache.Get(key); ok {
+		c.cacheHits++
+		c.replayCachedTool(cached)
+		tool := copyTool(cached.Tool)
+		return &tool, nil
+	}
+	c.cacheMisses++
+
+	warningsBefore := len(c.warnings)
+	promotedBefore := c.promotedDefaults
+	truncatedBefore := len(c.truncatedEnums)
+	reportedBefore := make(map[string]bool, len(c.reportedSchemes))
+	for name := range c.reportedSchemes {
+		reportedBefore[name] = true
+	}
+
+	tool, err := c.convertOperation(path, method, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := models.CachedTool{
+		Warnings:         append([]string(nil), c.warnings[warningsBefore:]...),
+		PromotedDefaults: c.promotedDefaults - promotedBefore,
+		TruncatedEnums:   append([]string(nil), c.truncatedEnums[truncatedBefore:]...),
+	}
+	for name := range c.reportedSchemes {
+		if !reportedBefore[name] {
+			entry.ReportedSchemes = append(entry.ReportedSchemes, name)
+		}
+	}
+	if operationID := c.parser.GetOperationID(path, method, operation); c.forcedOperations[operationID] {
+		entry.ForcedOperation = operationID
+	}
+	entry.Tool = copyTool(*tool)
+	c.options.ToolCache.Add(key, entry)
+
+	return tool, nil
+}
+
+// replayCachedTool applies the side effects of a cached conversion. Warnings
+// already reported during this conversion, such as a security scheme warning
+// raised by another operation first, are not repeated.
+func (c *Converter) replayCachedTool(cached models.CachedTool) {
+	for _, warning := range cached.Warnings {
+		if !contains(c.warnings, warning) {
+			c.warnings = append(c.warnings, warning)
+		}
+	}
+	for _, name := range cached.ReportedSchemes {
+		c.reportedSchemes[name] = true
+	}
+	c.promotedDefaults += cached.PromotedDefaults
+	c.truncatedEnums = append(c.truncatedEnums, cached.TruncatedEnums...)
+	if cached.ForcedOperation != "" {
+		c.forcedOperations[cached.ForcedOperation] = true
+	}
+}
+
+// copyTool deep-copies a tool so cached entries are never shared with a
+// config that is later modified. Nil and empty slices are kept apart, since
+// they marshal differently to JSON.
+func copyTool(tool models.Tool) models.Tool {
+	copied := tool
+	if tool.Args != nil {
+		copied.Args = make([]models.Arg, len(tool.Args))
+		for i, arg := range tool.Args {
+			arg.Default = copyValue(arg.Default)
+			if arg.Enum != nil {
+				arg.Enum = copyValue(arg.Enum).([]interface{})
+			}
+			if arg.Items != nil {
+				arg.Items = copyValue(arg.Items).(map[string]interface{})
+			}
+			if arg.Properties != nil {
+				arg.Properties = copyValue(arg.Properties).(map[string]interface{})
+			}
+			arg.Minimum = copyFloat(arg.Minimum)
+			arg.Maximum = copyFloat(arg.Maximum)
+			arg.ExclusiveMinimum = copyFloat(arg.ExclusiveMinimum)
+			arg.ExclusiveMaximum = copyFloat(arg.ExclusiveMaximum)
+			arg.MultipleOf = copyFloat(arg.MultipleOf)
+			if arg.Examples != nil {
+				arg.Examples = copyValue(arg.Examples).([]interface{})
+			}
+			copied.Args[i] = arg
+		}
+	}
+	if tool.RequestTemplate.Headers != nil {
+		copied.RequestTemplate.Headers = append([]models.Header{}, tool.RequestTemplate.Headers...)
+	}
+	if tool.RequestTemplate.Security != nil {
+		security := *tool.RequestTemplate.Security
+		copied.RequestTemplate.Security = &security
+	}
+	if tool.Security != nil {
+		security := *tool.Security
+		copied.Security = &security
+	}
+	if tool.Tags != nil {
+		copied.Tags = append([]string{}, tool.Tags...)
+	}
+	if tool.Auth != nil {
+		auth := *tool.Auth
+		if auth.Requirements != nil {
+			auth.Requirements = make([][]string, len(tool.Auth.Requirements))
+			for i, requirement := range tool.Auth.Requirements {
+				auth.Requirements[i] = append([]string{}, requirement...)
+			}
+		}
+		copied.Auth = &auth
+	}
+	if tool.Source != nil {
+		source := *tool.Source
+		copied.Source = &source
+	}
+	if tool.ResponseSize != nil {
+		hint := *tool.ResponseSize
+		copied.ResponseSize = &hint
+	}
+	if tool.ResponseHeaders != nil {
+		copied.ResponseHeaders = append([]models.ResponseHeader{}, tool.ResponseHeaders...)
+	}
+	if tool.ParamMapping != nil {
+		copied.ParamMapping = make(map[string]string, len(tool.ParamMapping))
+		for name, wireName := range tool.ParamMapping {
+			copied.ParamMapping[name] = wireName
+		}
+	}
+	if tool.Metadata != nil {
+		copied.Metadata = copyValue(tool.Metadata).(map[string]interface{})
+	}
+	return copied
+}
+
+// copyValue deep-copies the maps and slices of a schema value
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	case []string:
+		return append([]string{}, v...)
+	default:
+		return value
+	}
+}
+
+// copyFloat copies an optional number
+func copyFloat(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+// GetCacheStats returns the ToolCache hits and misses of the last conversion
+func (c *Converter) GetCacheStats() (hits, misses int) {
+	return c.cacheHits, c.cacheMisses
+}
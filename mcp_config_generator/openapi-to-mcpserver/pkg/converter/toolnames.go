@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// maxToolNameLength is the longest tool name MCP runtimes accept
+const maxToolNameLength = 64
+
+// validToolName is the MCP tool identifier grammar
+var validToolName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// invalidToolNameChars matches the runs of characters sanitizing replaces
+var invalidToolNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// checkToolNames makes every tool name a valid MCP identifier. Invalid names
+// are sanitized with a warning giving the new name, or with StrictNames the
+// conversion fails listing them.
+func (c *Converter) checkToolNames(tools []models.Tool) error {
+	taken := make(map[string]bool, len(tools))
+	var invalid []int
+	for i, tool := range tools {
+		if validToolName.MatchString(tool.Name) {
+			taken[tool.Name] = true
+		} else {
+			invalid = append(invalid, i)
+		}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	if c.options.StrictNames {
+		names := make([]string, 0, len(invalid))
+		for _, i := range invalid {
+			names = append(names, fmt.Sprintf("%q", tools[i].Name))
+		}
+		sort.Strings(names)
+		return fmt.Errorf("tool names are not valid MCP identifiers (letters, digits, _ and -, at most %d characters): %s", maxToolNameLength, strings.Join(names, ", "))
+	}
+
+	// Sanitize in name order so clashes are resolved the same way every time
+	sort.Slice(invalid, func(a, b int) bool { return tools[invalid[a]].Name < tools[invalid[b]].Name })
+	for _, i := range invalid {
+		name := sanitizeToolName(tools[i].Name, taken)
+		c.addWarning("tool name %q is not a valid MCP identifier, renamed to %q", tools[i].Name, name)
+		tools[i].Name = name
+		taken[name] = true
+	}
+	return nil
+}
+
+// sanitizeToolName replaces each run of invalid characters with an
+// underscore and truncates the name, adding a numeric suffix when the result
+// is already taken
+func sanitizeToolName(name string, taken map[string]bool) string {
+	base := strings.Trim(invalidToolNameChars.ReplaceAllString(name, "_"), "_")
+	if base == "" {
+		base = "tool"
+	}
+	if len(base) > maxToolNameLength {
+		base = base[:maxToolNameLength]
+	}
+
+	sanitized := base
+	for i := 2; taken[sanitized]; i++ {
+		suffix := fmt.Sprintf("_%d", i)
+		trimmed := base
+		if len(trimmed)+len(suffix) > maxToolNameLength {
+			trimmed = trimmed[:maxToolNameLength-len(suffix)]
+		}
+		sanitized = trimmed + suffix
+	}
+	return sanitized
+}
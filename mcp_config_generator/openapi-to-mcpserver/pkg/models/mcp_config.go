@@ -1,9 +1,32 @@
 package models
 
+import (
+	"regexp"
+	"text/template"
+)
+
 // MCPConfig represents the top-level MCP server configuration
 type MCPConfig struct {
+	ID     string       `yaml:"$id,omitempty" json:"$id,omitempty"`
+	Info   *SpecInfo    `yaml:"info,omitempty" json:"info,omitempty"`
 	Server ServerConfig `yaml:"server"`
 	Tools  []Tool       `yaml:"tools,omitempty"`
+	// Components holds the schemas shared between tools, see
+	// ConvertOptions.RefStrategy
+	Components *Components `yaml:"components,omitempty"`
+}
+
+// Components holds schemas that tool arguments reference with $ref
+type Components struct {
+	Schemas map[string]map[string]interface{} `yaml:"schemas"`
+}
+
+// SpecInfo identifies the OpenAPI document a config was generated from
+type SpecInfo struct {
+	Title          string `yaml:"title" json:"title"`
+	Version        string `yaml:"version" json:"version"`
+	OpenAPIVersion string `yaml:"openapiVersion" json:"openapiVersion"`
+	SourceSHA256   string `yaml:"sourceSha256" json:"sourceSha256"`
 }
 
 // ServerConfig represents the MCP server configuration
@@ -12,6 +35,16 @@ type ServerConfig struct {
 	Config          map[string]interface{} `yaml:"config,omitempty"`
 	AllowTools      []string               `yaml:"allowTools,omitempty"`
 	SecuritySchemes []SecurityScheme       `yaml:"securitySchemes,omitempty"`
+	Tags            []Tag                  `yaml:"tags,omitempty"`
+	// ReadOnly marks a config that only holds non-mutating tools, see
+	// ConvertOptions.SafeMode
+	ReadOnly bool `yaml:"readOnly,omitempty"`
+}
+
+// Tag describes a category that tools can belong to
+type Tag struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
 }
 
 // SecurityScheme defines a security scheme that can be used by the tools.
@@ -32,19 +65,92 @@ type Tool struct {
 	RequestTemplate  RequestTemplate          `yaml:"requestTemplate"`
 	ResponseTemplate ResponseTemplate         `yaml:"responseTemplate"`
 	Security         *ToolSecurityRequirement `yaml:"security,omitempty"`
+	Tags             []string                 `yaml:"tags,omitempty"`
+	Auth             *ToolAuth                `yaml:"auth,omitempty"`
+	// Source is the operation the tool was generated from, see
+	// ConvertOptions.AnnotateSource
+	Source *SourceLocation `yaml:"source,omitempty"`
+	// ResponseHeaders lists the headers documented on the success response,
+	// see ConvertOptions.IncludeResponseHeaders
+	ResponseHeaders []ResponseHeader `yaml:"responseHeaders,omitempty"`
+	// ResponseSize tells the runtime how to handle large responses, see
+	// ConvertOptions.ResponseSizeHints
+	ResponseSize *ResponseSizeHint `yaml:"responseSize,omitempty"`
+	// ParamMapping maps renamed argument names to the parameter names sent
+	// to the backend, see ConvertOptions.ParamNameCase
+	ParamMapping map[string]string `yaml:"paramMapping,omitempty"`
+	// Disabled emits the tool switched off until the runtime enables it,
+	// see ConvertOptions.InitiallyDisabled
+	Disabled bool `yaml:"disabled,omitempty"`
+	// Metadata holds the operation's vendor extensions, see
+	// ConvertOptions.ExtensionPassthrough
+	Metadata map[string]interface{} `yaml:"metadata,omitempty"`
+	// Language is the language detected in the operation's description when
+	// it isn't the expected one, see ConvertOptions.DescriptionLanguageMode
+	Language string `yaml:"language,omitempty"`
+}
+
+// SourceLocation identifies an operation of the source OpenAPI document
+type SourceLocation struct {
+	Method string `yaml:"method"`
+	Path   string `yaml:"path"`
+}
+
+// ResponseHeader describes a header returned with a tool's response
+type ResponseHeader struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// ToolAuth describes the authentication a tool needs
+type ToolAuth struct {
+	// Required is false for public tools and for tools where authentication is optional
+	Required bool `yaml:"required"`
+	// Requirements lists the alternative sets of security scheme IDs; every
+	// scheme in one set must be satisfied
+	Requirements [][]string `yaml:"requirements,omitempty"`
 }
 
 // Arg represents an MCP tool argument
 type Arg struct {
-	Name        string                 `yaml:"name"`
-	Description string                 `yaml:"description"`
-	Type        string                 `yaml:"type,omitempty"`
-	Required    bool                   `yaml:"required,omitempty"`
-	Default     interface{}            `yaml:"default,omitempty"`
-	Enum        []interface{}          `yaml:"enum,omitempty"`
-	Items       map[string]interface{} `yaml:"items,omitempty"`
-	Properties  map[string]interface{} `yaml:"properties,omitempty"`
-	Position    string                 `yaml:"position,omitempty"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Type        string `yaml:"type,omitempty"`
+	Nullable    bool   `yaml:"nullable,omitempty"`
+	// NullAsType writes a nullable argument's type as [type, "null"]
+	// instead of setting nullable
+	NullAsType bool                   `yaml:"-" json:"-"`
+	Required   bool                   `yaml:"required,omitempty"`
+	Default    interface{}            `yaml:"default,omitempty"`
+	Enum       []interface{}          `yaml:"enum,omitempty"`
+	Format     string                 `yaml:"format,omitempty"`
+	Pattern    string                 `yaml:"pattern,omitempty"`
+	Items      map[string]interface{} `yaml:"items,omitempty"`
+	Properties map[string]interface{} `yaml:"properties,omitempty"`
+	Position   string                 `yaml:"position,omitempty"`
+	// Minimum, Maximum, their exclusive variants and MultipleOf are the
+	// numeric constraints of the argument. Exclusive bounds are written as
+	// numbers, the JSON Schema form.
+	Minimum          *float64 `yaml:"minimum,omitempty"`
+	Maximum          *float64 `yaml:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `yaml:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `yaml:"exclusiveMaximum,omitempty"`
+	MultipleOf       *float64 `yaml:"multipleOf,omitempty"`
+	// Ref points to a schema under components.schemas that replaces the
+	// argument's properties
+	Ref string `yaml:"$ref,omitempty"`
+	// ArrayStyle is how an array parameter is serialized: csv, multi, pipe
+	// or space. Empty leaves it to the MCP server.
+	ArrayStyle string `yaml:"arrayStyle,omitempty"`
+	// Examples holds the example synthesized for an argument without one
+	Examples []interface{} `yaml:"examples,omitempty"`
+	// Coerce asks the runtime to convert string-encoded values to the
+	// argument's type before calling the backend
+	Coerce bool `yaml:"coerce,omitempty"`
+	// AcceptString adds string to the argument's type, written as a type
+	// array, so string-encoded values pass validation and can be coerced
+	AcceptString bool `yaml:"-" json:"-"`
 }
 
 // RequestTemplate represents the MCP request template
@@ -84,8 +190,231 @@ type ConvertOptions struct {
 	ServerConfig   map[string]interface{}
 	ToolNamePrefix string
 	TemplatePath   string
+	// RequireSuccessResponse skips operations that declare no 2xx response
+	RequireSuccessResponse bool
+	// DescriptionTemplate renders each tool description from the operation,
+	// see converter.DescriptionData for the available fields
+	DescriptionTemplate *template.Template
+	// DescriptionSource picks the operation fields tool descriptions are
+	// made of: "description", "summary", "summary_then_description" or
+	// "both" (the default), which joins the summary and description
+	DescriptionSource string
+	// PreferredRequestMedia lists request body content types in order of preference
+	PreferredRequestMedia []string
+	// MaxSchemaDepth limits how many levels of nested schemas are expanded
+	// in arguments and response descriptions, an argument or top-level
+	// response field being the first; 0 means the default of 10 levels
+	MaxSchemaDepth int
+	// PropagateTags copies operation tags to the tools and lists the used
+	// tags with their descriptions in the server config
+	PropagateTags bool
+	// StrictOperationIDs fails the conversion when operations share an
+	// operationId instead of keeping the first one with a warning
+	StrictOperationIDs bool
+	// SafeMode keeps only the GET, HEAD and OPTIONS operations, leaving out
+	// the mutating ones with a warning, and marks the config read-only
+	SafeMode bool
+	// OperationIDStripPrefix is removed from the start of each operationId
+	// before it becomes a tool name and ToolNamePrefix is added
+	OperationIDStripPrefix *regexp.Regexp
+	// MissingBodySchema is how operations whose request body declares no
+	// schema are converted: "free-form" (the default) takes the body as an
+	// open object, "string" as a raw string, "skip" leaves them out
+	MissingBodySchema string
+	// ResponseCodesInDescription summarizes an operation's response codes
+	// at the end of its tool description: "success-only" for the 2xx codes,
+	// "all", or a list of codes such as 200, 404 and 5XX. Nil or "none"
+	// leaves them out.
+	ResponseCodesInDescription []string
+	// MaxEnumValues limits the enum of each argument to its first values,
+	// noting the cut in the argument description; 0 means no limit
+	MaxEnumValues int
+	// EnumOverflow is how enums over MaxEnumValues are shortened:
+	// "truncate" (the default) or "string" to drop the enum, leaving a
+	// plain argument that names the first values as examples
+	EnumOverflow string
+	// ExtensionPassthrough lists the operation-level vendor extensions, such
+	// as x-dangerous, copied verbatim into each tool's metadata
+	ExtensionPassthrough []string
+	// ParameterConflictMode resolves a parameter declared with the same name
+	// and location at both the path and the operation level:
+	// "operation-wins" (the default), "path-wins" or "error"
+	ParameterConflictMode string
+	// FilterDescriptionLanguage is the ISO 639-1 code, such as "en", of the
+	// language operation descriptions should be in. Operations detected in
+	// another language are skipped or tagged, see DescriptionLanguageMode.
+	// Detection is a heuristic and leaves short descriptions undetermined.
+	FilterDescriptionLanguage string
+	// DescriptionLanguageMode is what happens to operations in another
+	// language than FilterDescriptionLanguage: "skip" (the default) leaves
+	// them out, "tag" sets their tool's language
+	DescriptionLanguageMode string
+	// StrictNames fails the conversion when a tool name is not a valid MCP
+	// identifier instead of sanitizing it with a warning
+	StrictNames bool
+	// IncludePathRegex limits the conversion to paths matching it
+	IncludePathRegex *regexp.Regexp
+	// ExcludePathRegex leaves out paths matching it, even when they match
+	// IncludePathRegex
+	ExcludePathRegex *regexp.Regexp
+	// IncludePathGlobs and ExcludePathGlobs filter paths with globs where "*"
+	// matches within a segment and "**" across segments. Operations are
+	// selected when their path matches an include filter (regex or glob), or
+	// there are none, and matches no exclude filter.
+	IncludePathGlobs []string
+	ExcludePathGlobs []string
+	// AnnotateAuth adds the security requirements of each operation, or of
+	// the document when the operation has none, to the tool's auth field
+	AnnotateAuth bool
+	// StripExamples removes example and examples fields from the document
+	// before conversion, keeping schema examples that are the only
+	// description of their values
+	StripExamples bool
+	// PromoteExamplesToDefaults uses the example of an optional, non-path
+	// parameter as its default when it has none
+	PromoteExamplesToDefaults bool
+	// SynthesizeExamples gives arguments without an example one generated
+	// from their type, format, enum and bounds, except for sensitive ones
+	SynthesizeExamples bool
+	// OperationOrder sorts the tools by "operationId" (the default), "path",
+	// "method", "tag" (first tag) or "spec" (document order)
+	OperationOrder string
+	// ForceRequired maps operationIds to argument names that are marked as
+	// required whatever the spec says
+	ForceRequired map[string][]string
+	// InitiallyDisabled lists operationIds or tool names whose tools are
+	// emitted disabled
+	InitiallyDisabled []string
+	// ResponseSizeHints maps operationIds to the response size hint of their
+	// tools, overriding the operations' x-response-size extensions
+	ResponseSizeHints map[string]ResponseSizeHint
+	// FormatMapping maps OpenAPI formats to the constraints added to tool
+	// arguments, nil disables format mapping. See
+	// converter.DefaultFormatMapping.
+	FormatMapping map[string]FormatConstraint
+	// RefStrategy is "inline" (the default) to copy referenced schemas into
+	// every tool, or "shared-components" to write component schemas once in
+	// the config's components section and reference them with $ref
+	RefStrategy string
+	// DefaultArrayStyle is the serialization of array parameters that set
+	// neither style nor explode: csv, multi, pipe or space. Empty leaves it
+	// to the MCP server.
+	DefaultArrayStyle string
+	// NullHandling is how nullable arguments are written: "nullable" or
+	// "type-array". When empty it follows the source document, type arrays
+	// for OpenAPI 3.1 and nullable otherwise.
+	NullHandling string
+	// StripPathPrefix is removed from the start of every operation path and
+	// PathPrefix is then prepended when building request template URLs
+	StripPathPrefix string
+	PathPrefix      string
+	// DefaultRequestContentType is the Content-Type of tools whose request
+	// body declares no content type, application/json when empty
+	DefaultRequestContentType string
+	// AnnotateSource records the method and path of each tool's source
+	// operation in its source field
+	AnnotateSource bool
+	// IncludeResponseHeaders lists the headers of each operation's success
+	// response in the tool's responseHeaders and description
+	IncludeResponseHeaders bool
+	// IncludeEnumDescriptions appends the x-enum-descriptions of enum
+	// arguments to their descriptions
+	IncludeEnumDescriptions bool
+	// EmptyParamHandling is how unset optional query, header and cookie
+	// parameters are sent: "omit" (the default) leaves them out,
+	// "send-empty" sends string parameters as empty strings
+	EmptyParamHandling string
+	// CoerceTypes maps argument types (integer, number, boolean) to how
+	// string-encoded values of them are handled: "none", "hint" to mark the
+	// arguments for coercion, or "relax" to also accept strings in their
+	// schema. See converter.DefaultCoercion.
+	CoerceTypes map[string]string
+	// SecretPlaceholderTemplate renders the defaultCredential placeholder of
+	// each security scheme, see converter.SecretPlaceholderData for the
+	// available fields. Nil uses converter.DefaultSecretPlaceholder.
+	SecretPlaceholderTemplate *template.Template
+	// PipelineOrder reorders the steps that transform the config once every
+	// operation is converted. Unlisted steps follow in their default order.
+	// See converter.DefaultPipelineOrder.
+	PipelineOrder []string
+	// BodyWrapper, when set, sends each tool's JSON request body under this
+	// key, e.g. "data" for {"data": {...}}
+	BodyWrapper string
+	// StrictNumericConstraints types arguments without a type from their
+	// int32, int64, float or double format and keeps the bounds of integer
+	// arguments integral
+	StrictNumericConstraints bool
+	// ParamNameCase renames tool arguments to camel, snake or kebab case,
+	// recording the original names in each tool's ParamMapping
+	ParamNameCase string
+	// EmitSpecInfo adds a $id and an info block identifying the source spec
+	EmitSpecInfo bool
+	// ToolCache, when set, reuses the tools of operations converted before
+	// with the same definition and options
+	ToolCache ToolCache
+	// Transport holds HTTP client settings requested for the tools' backend calls
+	Transport TransportOptions
 }
 
+// FormatConstraint is the JSON Schema validation an OpenAPI format maps to
+type FormatConstraint struct {
+	Format  string `json:"format,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// ToolCache stores converted tools across conversions. Keys are hashes of
+// everything a tool depends on, so an entry never needs invalidating.
+// Implementations must be safe for concurrent use.
+type ToolCache interface {
+	Get(key string) (CachedTool, bool)
+	Add(key string, tool CachedTool)
+}
+
+// CachedTool is a converted tool together with the side effects its
+// conversion had, so they can be replayed on a cache hit
+type CachedTool struct {
+	Tool             Tool
+	Warnings         []string
+	PromotedDefaults int
+	ReportedSchemes  []string
+	ForcedOperation  string
+	TruncatedEnums   []string
+}
+
+// TransportOptions are HTTP client settings for the requests tools make. Nil
+// fields are left to the MCP server's defaults.
+type TransportOptions struct {
+	HTTP2        *bool
+	KeepAlive    *bool
+	MaxRedirects *int
+}
+
+// SkippedOperation records an operation that was not converted into a tool
+type SkippedOperation struct {
+	Path     string `yaml:"path"`
+	Method   string `yaml:"method"`
+	Category string `yaml:"category"`
+	Reason   string `yaml:"reason"`
+}
+
+// OperationLanguage records the language detected in an operation's
+// description, see ConvertOptions.FilterDescriptionLanguage
+type OperationLanguage struct {
+	Path     string `yaml:"path"`
+	Method   string `yaml:"method"`
+	Language string `yaml:"language"`
+}
+
+// Categories of SkippedOperation
+const (
+	// SkipFiltered is used for operations left out by a conversion option
+	SkipFiltered = "filtered"
+	// SkipUnsupported is used for operations the converter can't represent
+	SkipUnsupported = "unsupported"
+	// SkipError is used for operations that are invalid in the spec
+	SkipError = "error"
+)
+
 // ToolTemplate represents a template for applying to all tools
 type ToolTemplate struct {
 	RequestTemplate  *RequestTemplate         `yaml:"requestTemplate,omitempty"`
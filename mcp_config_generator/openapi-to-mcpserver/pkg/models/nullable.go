@@ -0,0 +1,192 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Null handling forms of nullable tool arguments, see
+// ConvertOptions.NullHandling
+const (
+	// NullHandlingNullable marks nullable arguments with nullable: true
+	NullHandlingNullable = "nullable"
+	// NullHandlingTypeArray writes nullable arguments as type: [T, "null"]
+	NullHandlingTypeArray = "type-array"
+)
+
+// MarshalYAML writes the type of a nullable argument as a type array when
+// NullAsType is set, and adds string to it when AcceptString is set
+func (a Arg) MarshalYAML() (interface{}, error) {
+	type plain Arg
+	types := a.typeArray()
+	if types == nil {
+		return plain(a), nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(plain(a)); err != nil {
+		return nil, err
+	}
+	content := make([]*yaml.Node, 0, len(node.Content))
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "nullable":
+			if a.NullAsType {
+				continue
+			}
+		case "type":
+			value = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+			for _, name := range types {
+				item := &yaml.Node{Kind: yaml.ScalarNode, Value: name}
+				if name == "null" {
+					item.Style = yaml.DoubleQuotedStyle
+				}
+				value.Content = append(value.Content, item)
+			}
+		}
+		content = append(content, key, value)
+	}
+	node.Content = content
+	return &node, nil
+}
+
+// UnmarshalYAML reads arguments written by MarshalYAML, including type
+// arrays
+func (a *Arg) UnmarshalYAML(node *yaml.Node) error {
+	type plain Arg
+	var typeArray *yaml.Node
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "type" && node.Content[i+1].Kind == yaml.SequenceNode {
+				typeArray = node.Content[i+1]
+				copied := *node
+				copied.Content = append(append([]*yaml.Node{}, node.Content[:i]...), node.Content[i+2:]...)
+				node = &copied
+				break
+			}
+		}
+	}
+
+	if err := node.Decode((*plain)(a)); err != nil {
+		return err
+	}
+	if typeArray != nil {
+		for _, item := range typeArray.Content {
+			if item.Value == "null" {
+				a.Nullable = true
+				a.NullAsType = true
+			} else if a.Type == "" {
+				a.Type = item.Value
+			} else if item.Value == "string" {
+				a.AcceptString = true
+			}
+		}
+	}
+	return nil
+}
+
+// MarshalJSON writes the type of an argument like MarshalYAML, as a type
+// array when NullAsType or AcceptString is set. The other fields keep their
+// order.
+func (a Arg) MarshalJSON() ([]byte, error) {
+	type plain Arg
+	data, err := json.Marshal(plain(a))
+	types := a.typeArray()
+	if err != nil || types == nil {
+		return data, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		switch key {
+		case "Nullable":
+			if a.NullAsType {
+				continue
+			}
+		case "Type":
+			if value, err = json.Marshal(types); err != nil {
+				return nil, err
+			}
+		}
+		if buffer.Len() > 1 {
+			buffer.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON reads arguments written by MarshalJSON, including type
+// arrays
+func (a *Arg) UnmarshalJSON(data []byte) error {
+	type plain Arg
+	aux := struct {
+		*plain
+		Type json.RawMessage
+	}{plain: (*plain)(a)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.Type) == 0 || string(aux.Type) == "null" {
+		return nil
+	}
+	if aux.Type[0] != '[' {
+		return json.Unmarshal(aux.Type, &a.Type)
+	}
+
+	var types []string
+	if err := json.Unmarshal(aux.Type, &types); err != nil {
+		return err
+	}
+	for _, name := range types {
+		if name == "null" {
+			a.Nullable = true
+			a.NullAsType = true
+		} else if a.Type == "" {
+			a.Type = name
+		} else if name == "string" {
+			a.AcceptString = true
+		}
+	}
+	return nil
+}
+
+// typeArray returns the types an argument is written with as a type array,
+// nil when its type is written as a single name
+func (a Arg) typeArray() []string {
+	if a.Type == "" {
+		return nil
+	}
+	types := []string{a.Type}
+	if a.AcceptString && a.Type != "string" {
+		types = append(types, "string")
+	}
+	if a.Nullable && a.NullAsType {
+		types = append(types, "null")
+	}
+	if len(types) == 1 {
+		return nil
+	}
+	return types
+}
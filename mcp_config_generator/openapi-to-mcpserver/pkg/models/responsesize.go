@@ -0,0 +1,56 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ResponseSizePaginate is the ResponseSizeHint value asking the runtime to
+// paginate large responses
+const ResponseSizePaginate = "paginate"
+
+// errInvalidResponseSizeHint is returned for hints that are neither a byte
+// limit nor "paginate", or that set both
+var errInvalidResponseSizeHint = errors.New(`response size hint must be a positive number of bytes or "paginate"`)
+
+// ResponseSizeHint tells the runtime how to keep a tool's response within
+// the model's context. It is written as a maxBytes or paginate map in both
+// YAML and JSON. When read from JSON, the byte limit or "paginate" alone is
+// accepted too.
+type ResponseSizeHint struct {
+	// MaxBytes asks the runtime to truncate or summarize larger responses
+	MaxBytes int `yaml:"maxBytes,omitempty" json:"maxBytes,omitempty"`
+	// Paginate asks the runtime to page through large responses
+	Paginate bool `yaml:"paginate,omitempty" json:"paginate,omitempty"`
+}
+
+func (h *ResponseSizeHint) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		if text != ResponseSizePaginate {
+			return errInvalidResponseSizeHint
+		}
+		*h = ResponseSizeHint{Paginate: true}
+		return nil
+	}
+
+	type plain ResponseSizeHint
+	var hint plain
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&hint); err != nil || hint.MaxBytes < 0 || (hint.MaxBytes > 0) == hint.Paginate {
+			return errInvalidResponseSizeHint
+		}
+		*h = ResponseSizeHint(hint)
+		return nil
+	}
+
+	var maxBytes int
+	if err := json.Unmarshal(data, &maxBytes); err != nil || maxBytes <= 0 {
+		return errInvalidResponseSizeHint
+	}
+	*h = ResponseSizeHint{MaxBytes: maxBytes}
+	return nil
+}
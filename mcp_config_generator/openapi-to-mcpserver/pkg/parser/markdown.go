@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoMarkdownSpec is returned by ExtractMarkdownSpec for Markdown without
+// an OpenAPI code block
+var ErrNoMarkdownSpec = errors.New("no fenced yaml or json code block with an openapi or swagger key found in the Markdown")
+
+// markdownSpecLanguages are the code block info strings that may hold a
+// spec. Blocks without an info string are tried as well.
+var markdownSpecLanguages = []string{"", "yaml", "yml", "json"}
+
+// IsMarkdownSpec reports whether data is a Markdown document rather than a
+// spec: it has no top-level openapi or swagger key but does have a fenced
+// code block
+func IsMarkdownSpec(data []byte) bool {
+	if isSpec(data) {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if _, _, ok := openingFence(line); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractMarkdownSpec returns the first fenced yaml or json code block of a
+// Markdown document that has a top-level openapi or swagger key
+func ExtractMarkdownSpec(data []byte) ([]byte, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		fence, language, ok := openingFence(lines[i])
+		if !ok {
+			continue
+		}
+
+		// The block runs to the closing fence or the end of the document
+		end := len(lines)
+		for j := i + 1; j < len(lines); j++ {
+			if isClosingFence(lines[j], fence) {
+				end = j
+				break
+			}
+		}
+		block := []byte(strings.Join(lines[i+1:end], "\n") + "\n")
+		if contains(markdownSpecLanguages, language) && isSpec(block) {
+			return block, nil
+		}
+		i = end
+	}
+	return nil, ErrNoMarkdownSpec
+}
+
+// openingFence parses a code fence opening line: three or more backticks or
+// tildes, indented by at most three spaces, followed by an info string whose
+// first word is the block's language
+func openingFence(line string) (fence, language string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return "", "", false
+	}
+	for _, char := range []string{"`", "~"} {
+		count := len(trimmed) - len(strings.TrimLeft(trimmed, char))
+		if count < 3 {
+			continue
+		}
+		info := strings.TrimSpace(trimmed[count:])
+		if char == "`" && strings.Contains(info, "`") {
+			return "", "", false
+		}
+		if fields := strings.Fields(info); len(fields) > 0 {
+			language = strings.ToLower(fields[0])
+		}
+		return trimmed[:count], language, true
+	}
+	return "", "", false
+}
+
+// isClosingFence reports whether line closes a block opened with fence
+func isClosingFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	return len(line)-len(strings.TrimLeft(line, " ")) <= 3 &&
+		len(trimmed) >= len(fence) &&
+		strings.Trim(trimmed, fence[:1]) == ""
+}
+
+// isSpec reports whether data is a YAML or JSON mapping with a top-level
+// openapi or swagger key
+func isSpec(data []byte) bool {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return false
+	}
+	_, openapi := document["openapi"]
+	_, swagger := document["swagger"]
+	return openapi || swagger
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
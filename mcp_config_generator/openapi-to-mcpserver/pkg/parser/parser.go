@@ -2,18 +2,31 @@ package parser
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
 )
 
 // Parser represents an OpenAPI parser
 type Parser struct {
 	doc              *openapi3.T
 	ValidateDocument bool
+
+	// operationOrder maps "method path" to the position of the operation in the document
+	operationOrder map[string]int
+
+	// sourceHash is the hex SHA-256 of the parsed document bytes
+	sourceHash string
+
+	// unresolvedTypes lists the 3.1 type arrays that have no 3.0 form
+	unresolvedTypes []string
 }
 
 // NewParser creates a new OpenAPI parser
@@ -28,13 +41,21 @@ func (p *Parser) SetValidation(validate bool) {
 	p.ValidateDocument = validate
 }
 
-// ParseFile parses an OpenAPI document from a file
+// ParseFile parses an OpenAPI document from a file. The spec of a Markdown
+// file (.md or .markdown) is read from its first OpenAPI code block.
 func (p *Parser) ParseFile(filePath string) error {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read OpenAPI file: %w", err)
 	}
 
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".md", ".markdown":
+		if data, err = ExtractMarkdownSpec(data); err != nil {
+			return err
+		}
+	}
+
 	return p.Parse(data)
 }
 
@@ -42,12 +63,14 @@ func (p *Parser) ParseFile(filePath string) error {
 func (p *Parser) Parse(data []byte) error {
 	loader := openapi3.NewLoader()
 
-	// Try to parse as JSON first
-	var doc *openapi3.T
-	var err error
+	// Rewrite 3.1 type arrays the loader can't read
+	normalized, unresolvedTypes, err := normalizeTypeArrays(data)
+	if err != nil {
+		return err
+	}
 
 	// Parse the document (loader can handle both JSON and YAML)
-	doc, err = loader.LoadFromData(data)
+	doc, err := loader.LoadFromData(normalized)
 
 	if err != nil {
 		return fmt.Errorf("failed to parse OpenAPI document: %w", err)
@@ -62,6 +85,51 @@ func (p *Parser) Parse(data []byte) error {
 	}
 
 	p.doc = doc
+	p.unresolvedTypes = unresolvedTypes
+	p.operationOrder = readOperationOrder(data)
+	sum := sha256.Sum256(data)
+	p.sourceHash = hex.EncodeToString(sum[:])
+	return nil
+}
+
+// readOperationOrder records the order operations appear in the document,
+// which is lost once the paths are loaded into maps. JSON documents are valid
+// YAML, so both are read with the YAML parser.
+func readOperationOrder(data []byte) map[string]int {
+	order := make(map[string]int)
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return order
+	}
+	paths := mappingValue(root.Content[0], "paths")
+	if paths == nil {
+		return order
+	}
+
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		path, item := paths.Content[i].Value, paths.Content[i+1]
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			method := strings.ToLower(item.Content[j].Value)
+			order[method+" "+path] = len(order)
+		}
+	}
+	return order
+}
+
+// mappingValue returns the value of key in a YAML mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
 	return nil
 }
 
@@ -78,6 +146,15 @@ func (p *Parser) GetPaths() map[string]*openapi3.PathItem {
 	return p.doc.Paths
 }
 
+// GetOperationIndex returns the position of an operation in the document, or
+// -1 when it is not known
+func (p *Parser) GetOperationIndex(path, method string) int {
+	if index, ok := p.operationOrder[strings.ToLower(method)+" "+path]; ok {
+		return index
+	}
+	return -1
+}
+
 // GetServers returns all servers in the OpenAPI document
 func (p *Parser) GetServers() []*openapi3.Server {
 	if p.doc == nil {
@@ -94,6 +171,17 @@ func (p *Parser) GetInfo() *openapi3.Info {
 	return p.doc.Info
 }
 
+// GetSourceHash returns the hex SHA-256 of the parsed document bytes
+func (p *Parser) GetSourceHash() string {
+	return p.sourceHash
+}
+
+// GetUnresolvedTypes returns the locations of OpenAPI 3.1 type arrays with
+// several non-null types, which were left untyped
+func (p *Parser) GetUnresolvedTypes() []string {
+	return p.unresolvedTypes
+}
+
 // isJSON checks if the data is in JSON format
 func isJSON(data []byte) bool {
 	var js json.RawMessage
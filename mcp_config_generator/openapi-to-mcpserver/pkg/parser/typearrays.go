@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// normalizeTypeArrays rewrites OpenAPI 3.1 type arrays, which the loader
+// can't read, into their 3.0 form: ["string", "null"] becomes type: string
+// with nullable: true. Arrays with several non-null types have no 3.0 form;
+// their type is dropped and their location returned. The document is only
+// re-encoded when something changed.
+func normalizeTypeArrays(data []byte) ([]byte, []string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		// Leave reporting the syntax error to the loader
+		return data, nil, nil
+	}
+
+	var unresolved []string
+	if !rewriteTypeArrays(root.Content[0], "#", &unresolved) {
+		return data, nil, nil
+	}
+	normalized, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to normalize type arrays: %w", err)
+	}
+	return normalized, unresolved, nil
+}
+
+// dataKeywords hold instance values, which are left as they are
+var dataKeywords = map[string]bool{"example": true, "examples": true, "default": true, "enum": true, "const": true}
+
+// rewriteTypeArrays rewrites the type arrays under node and reports whether
+// any was found. location is the JSON pointer of node.
+func rewriteTypeArrays(node *yaml.Node, location string, unresolved *[]string) bool {
+	changed := false
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if rewriteTypeArrays(item, fmt.Sprintf("%s/%d", location, i), unresolved) {
+				changed = true
+			}
+		}
+	case yaml.MappingNode:
+		var content []*yaml.Node
+		nullable := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "type" && value.Kind == yaml.SequenceNode {
+				changed = true
+				var types []string
+				for _, item := range value.Content {
+					if item.Value == "null" {
+						nullable = true
+					} else {
+						types = append(types, item.Value)
+					}
+				}
+				if len(types) != 1 {
+					*unresolved = append(*unresolved, fmt.Sprintf("%s (type [%s])", location, strings.Join(typeNames(value), ", ")))
+					continue
+				}
+				value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: types[0]}
+			} else if dataKeywords[key.Value] {
+				// Example and default values are data, not schemas
+			} else if rewriteTypeArrays(value, location+"/"+escapePointer(key.Value), unresolved) {
+				changed = true
+			}
+			content = append(content, key, value)
+		}
+		if nullable && mappingValueNode(content, "nullable") == nil {
+			content = append(content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "nullable"},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+		}
+		node.Content = content
+	}
+	return changed
+}
+
+// typeNames returns the entries of a type array
+func typeNames(node *yaml.Node) []string {
+	names := make([]string, 0, len(node.Content))
+	for _, item := range node.Content {
+		names = append(names, item.Value)
+	}
+	return names
+}
+
+// mappingValueNode returns the value of key in the key/value pairs of a
+// mapping node
+func mappingValueNode(content []*yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(content); i += 2 {
+		if content[i].Value == key {
+			return content[i+1]
+		}
+	}
+	return nil
+}
+
+// escapePointer escapes a JSON pointer reference token
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxExtensionLength bounds output extensions so object names stay short.
+const maxExtensionLength = 32
+
+// normalizeExtension strips a leading dot and checks that ext is made of
+// dot-separated alphanumeric parts, such as "yml" or "mcp.json", so it can't
+// change the object's folder or contain unsafe characters.
+func normalizeExtension(ext string) (string, error) {
+	ext = strings.TrimPrefix(ext, ".")
+	if ext == "" || len(ext) > maxExtensionLength {
+		return "", fmt.Errorf("extension must be 1 to %d characters", maxExtensionLength)
+	}
+	for _, part := range strings.Split(ext, ".") {
+		if part == "" {
+			return "", fmt.Errorf("invalid extension %q", ext)
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return "", fmt.Errorf("invalid extension %q, only letters, digits and dots are allowed", ext)
+			}
+		}
+	}
+	return ext, nil
+}
+
+// parseOutputExtensions parses OUTPUT_EXTENSIONS, a comma-separated list of
+// format=extension entries such as "yaml=yml,json=mcp.json".
+func parseOutputExtensions(value string) (map[string]string, error) {
+	extensions := make(map[string]string)
+	for _, entry := range splitList(value) {
+		format, ext, found := strings.Cut(entry, "=")
+		if !found || !containsString(supportedFormats, format) {
+			return nil, fmt.Errorf("invalid OUTPUT_EXTENSIONS entry %q, expected format=extension with format one of: %s", entry, strings.Join(supportedFormats, ", "))
+		}
+		ext, err := normalizeExtension(ext)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OUTPUT_EXTENSIONS entry %q: %w", entry, err)
+		}
+		extensions[format] = ext
+	}
+	return extensions, nil
+}
+
+// configExtension returns the extension of the stored MCP config: the
+// request's output_extension, else the OUTPUT_EXTENSIONS default for the
+// format, else the format itself. req.OutputExtension must be normalized.
+func (s *ConversionService) configExtension(req ConversionRequest) string {
+	if req.OutputExtension != "" {
+		return req.OutputExtension
+	}
+	if ext, ok := s.outputExtensions[req.Format]; ok {
+		return ext
+	}
+	return req.Format
+}
+
+// configExtensions lists the extensions stored MCP configs are looked for
+// with: the formats, the OUTPUT_EXTENSIONS defaults and the request's own.
+func (s *ConversionService) configExtensions(req ConversionRequest) []string {
+	extensions := append([]string{}, supportedFormats...)
+	for _, format := range supportedFormats {
+		if ext, ok := s.outputExtensions[format]; ok && !containsString(extensions, ext) {
+			extensions = append(extensions, ext)
+		}
+	}
+	if ext := s.configExtension(req); !containsString(extensions, ext) {
+		extensions = append(extensions, ext)
+	}
+	return extensions
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/converter"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+	"github.com/higress-group/openapi-to-mcpserver/pkg/parser"
+)
+
+// ToolPreviewRequest describes a single operation to preview as an MCP tool.
+// Parameters, request body schema and responses use OpenAPI 3 JSON syntax.
+type ToolPreviewRequest struct {
+	Path              string          `json:"path"`
+	Method            string          `json:"method"`
+	OperationID       string          `json:"operation_id,omitempty"`
+	Summary           string          `json:"summary,omitempty"`
+	Description       string          `json:"description,omitempty"`
+	Parameters        json.RawMessage `json:"parameters,omitempty"`
+	RequestBodySchema json.RawMessage `json:"request_body_schema,omitempty"`
+	RequestBodyType   string          `json:"request_body_content_type,omitempty"`
+	Responses         json.RawMessage `json:"responses,omitempty"`
+	ServerURL         string          `json:"server_url,omitempty"`
+	ToolPrefix        string          `json:"tool_prefix,omitempty"`
+}
+
+type ToolPreviewResponse struct {
+	Success  bool         `json:"success"`
+	Error    string       `json:"error,omitempty"`
+	Tool     *models.Tool `json:"tool,omitempty"`
+	Warnings []string     `json:"warnings,omitempty"`
+}
+
+// previewMethods are the HTTP methods an OpenAPI path item can hold.
+var previewMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+func handleToolPreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse JSON request
+	var req ToolPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithPreviewError(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	tool, warnings, err := previewTool(req)
+	if err != nil {
+		respondWithPreviewError(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ToolPreviewResponse{
+		Success:  true,
+		Tool:     tool,
+		Warnings: warnings,
+	})
+}
+
+// previewTool wraps the operation fragment in a minimal OpenAPI document and
+// converts it, returning the single resulting tool.
+func previewTool(req ToolPreviewRequest) (*models.Tool, []string, error) {
+	if !strings.HasPrefix(req.Path, "/") {
+		return nil, nil, newAPIError(http.StatusBadRequest, "path must start with /")
+	}
+	method := strings.ToLower(req.Method)
+	if !containsString(previewMethods, method) {
+		return nil, nil, newAPIError(http.StatusBadRequest, "method must be one of: %s", strings.Join(previewMethods, ", "))
+	}
+
+	operation := map[string]interface{}{
+		"responses": json.RawMessage(`{"200": {"description": "Successful response"}}`),
+	}
+	if req.OperationID != "" {
+		operation["operationId"] = req.OperationID
+	}
+	if req.Summary != "" {
+		operation["summary"] = req.Summary
+	}
+	if req.Description != "" {
+		operation["description"] = req.Description
+	}
+	if len(req.Parameters) > 0 {
+		operation["parameters"] = req.Parameters
+	}
+	if len(req.Responses) > 0 {
+		operation["responses"] = req.Responses
+	}
+	if len(req.RequestBodySchema) > 0 {
+		contentType := req.RequestBodyType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		operation["requestBody"] = map[string]interface{}{
+			"content": map[string]interface{}{
+				contentType: map[string]interface{}{"schema": req.RequestBodySchema},
+			},
+		}
+	}
+
+	document := map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]interface{}{
+			"title":   "Tool preview",
+			"version": "0.0.0",
+		},
+		"paths": map[string]interface{}{
+			req.Path: map[string]interface{}{method: operation},
+		},
+	}
+	if req.ServerURL != "" {
+		document["servers"] = []map[string]string{{"url": req.ServerURL}}
+	}
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, nil, newAPIError(http.StatusBadRequest, "invalid operation fragment: %v", err)
+	}
+
+	p := parser.NewParser()
+	if err := p.Parse(data); err != nil {
+		return nil, nil, newAPIError(http.StatusBadRequest, "invalid operation fragment: %v", err)
+	}
+
+	c := converter.NewConverter(p, models.ConvertOptions{
+		ToolNamePrefix: req.ToolPrefix,
+	})
+	config, err := c.Convert()
+	if err != nil {
+		return nil, nil, newAPIError(http.StatusBadRequest, "Conversion failed: %v", err)
+	}
+	if len(config.Tools) != 1 {
+		return nil, c.GetWarnings(), newAPIError(http.StatusUnprocessableEntity, "operation did not produce a tool")
+	}
+
+	return &config.Tools[0], c.GetWarnings(), nil
+}
+
+func respondWithPreviewError(w http.ResponseWriter, message string, statusCode int) {
+	response := ToolPreviewResponse{
+		Success: false,
+		Error:   message,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// publishTimeout bounds the upload of a package to its registry.
+const publishTimeout = 30 * time.Second
+
+// packageModTime is the modification time of every file in a package
+// tarball, the one npm uses, so the same config always packs to the same
+// bytes.
+var packageModTime = time.Date(1985, time.October, 26, 8, 15, 0, 0, time.UTC)
+
+var (
+	// semverPattern matches a semantic version such as 1.2.0 or 2.0.0-beta.1.
+	semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+	// packageScopePattern matches an npm scope such as @acme.
+	packageScopePattern = regexp.MustCompile(`^@[a-z0-9][a-z0-9._-]*$`)
+	// packageNameInvalid matches the characters npm doesn't allow in names.
+	packageNameInvalid = regexp.MustCompile(`[^a-z0-9._-]+`)
+)
+
+// PublishPackageOptions publishes the converted config as a package to an
+// npm-compatible registry.
+type PublishPackageOptions struct {
+	RegistryURL string `json:"registry_url"`
+	// Token is sent as a bearer token and never recorded
+	Token string `json:"token,omitempty"`
+	// Scope, e.g. "@acme", prefixes the package name
+	Scope string `json:"scope,omitempty"`
+	// Version replaces the spec's info.version
+	Version string `json:"version,omitempty"`
+}
+
+// PublishedPackage are the coordinates of a published config package.
+type PublishedPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Registry  string `json:"registry"`
+	Tarball   string `json:"tarball"`
+	Integrity string `json:"integrity"`
+}
+
+// publishRegistryHostsFromEnv parses PUBLISH_REGISTRY_HOSTS, the registry
+// hosts publish_package may upload to. publish_package is refused when it
+// is empty, since the upload would go wherever the caller points it.
+func publishRegistryHostsFromEnv() []string {
+	var hosts []string
+	for _, host := range strings.Split(os.Getenv("PUBLISH_REGISTRY_HOSTS"), ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// validatePublishPackage checks the publish_package options that don't
+// depend on the spec.
+func (s *ConversionService) validatePublishPackage(opts *PublishPackageOptions) error {
+	registry, err := url.Parse(opts.RegistryURL)
+	if err != nil || (registry.Scheme != "http" && registry.Scheme != "https") || registry.Host == "" {
+		return newAPIError(http.StatusBadRequest, "publish_package.registry_url must be an absolute http or https URL")
+	}
+	if len(s.publishRegistryHosts) == 0 {
+		return newAPIError(http.StatusBadRequest, "publish_package is disabled, set PUBLISH_REGISTRY_HOSTS to enable it")
+	}
+	if !containsString(s.publishRegistryHosts, strings.ToLower(registry.Hostname())) {
+		return newAPIError(http.StatusBadRequest, "publish_package.registry_url host %q is not allowed, must be one of: %s", registry.Hostname(), strings.Join(s.publishRegistryHosts, ", "))
+	}
+	if opts.Scope != "" && !packageScopePattern.MatchString(opts.Scope) {
+		return newAPIError(http.StatusBadRequest, "publish_package.scope must look like @name")
+	}
+	if opts.Version != "" && !semverPattern.MatchString(opts.Version) {
+		return newAPIError(http.StatusBadRequest, "publish_package.version %q is not a semantic version", opts.Version)
+	}
+	return nil
+}
+
+// packageCoordinates returns the name and version a config is published
+// under: the server name made npm-safe, and publish_package.version or the
+// spec's info.version.
+func packageCoordinates(opts *PublishPackageOptions, serverName, specVersion string) (string, string, error) {
+	name := strings.Trim(packageNameInvalid.ReplaceAllString(strings.ToLower(serverName), "-"), "-._")
+	if name == "" {
+		return "", "", newAPIError(http.StatusBadRequest, "server_name %q has no characters usable in a package name", serverName)
+	}
+	if opts.Scope != "" {
+		name = opts.Scope + "/" + name
+	}
+
+	version := opts.Version
+	if version == "" {
+		version = specVersion
+		if !semverPattern.MatchString(version) {
+			return "", "", newAPIError(http.StatusBadRequest, "info.version %q is not a semantic version, set publish_package.version", specVersion)
+		}
+	}
+	return name, version, nil
+}
+
+// buildPackageTarball packs the config and its package.json under package/,
+// the layout npm expects.
+func buildPackageTarball(name, version, description, configFile string, config []byte) ([]byte, error) {
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"name":        name,
+		"version":     version,
+		"description": description,
+		"main":        configFile,
+		"files":       []string{configFile},
+		"keywords":    []string{"mcp", "mcp-config"},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{"package/package.json", manifest},
+		{"package/" + configFile, config},
+	} {
+		header := &tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.data)), ModTime: packageModTime, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// publishPackage uploads the config as name@version with the npm publish
+// protocol: a PUT of the package document with the tarball attached. A
+// version the registry already has is reported as 409 Conflict.
+func publishPackage(ctx context.Context, opts *PublishPackageOptions, name, version, description, configFile string, config []byte) (*PublishedPackage, error) {
+	tarball, err := buildPackageTarball(name, version, description, configFile, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build package: %w", err)
+	}
+
+	registry := strings.TrimSuffix(opts.RegistryURL, "/")
+	baseName := name[strings.LastIndex(name, "/")+1:]
+	tarballName := fmt.Sprintf("%s-%s.tgz", baseName, version)
+	sha512Sum := sha512.Sum512(tarball)
+	sha1Sum := sha1.Sum(tarball)
+	published := &PublishedPackage{
+		Name:      name,
+		Version:   version,
+		Registry:  registry,
+		Tarball:   fmt.Sprintf("%s/%s/-/%s", registry, name, tarballName),
+		Integrity: "sha512-" + base64.StdEncoding.EncodeToString(sha512Sum[:]),
+	}
+
+	document, err := json.Marshal(map[string]interface{}{
+		"_id":         name,
+		"name":        name,
+		"description": description,
+		"dist-tags":   map[string]string{"latest": version},
+		"versions": map[string]interface{}{
+			version: map[string]interface{}{
+				"_id":         name + "@" + version,
+				"name":        name,
+				"version":     version,
+				"description": description,
+				"main":        configFile,
+				"dist": map[string]string{
+					"integrity": published.Integrity,
+					"shasum":    hex.EncodeToString(sha1Sum[:]),
+					"tarball":   published.Tarball,
+				},
+			},
+		},
+		"_attachments": map[string]interface{}{
+			tarballName: map[string]interface{}{
+				"content_type": "application/octet-stream",
+				"data":         base64.StdEncoding.EncodeToString(tarball),
+				"length":       len(tarball),
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build package document: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, registry+"/"+url.PathEscape(name), bytes.NewReader(document))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, newAPIError(http.StatusBadGateway, "failed to publish %s@%s: %v", name, version, errors.Unwrap(err))
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return published, nil
+	}
+
+	// npm answers 403 for versions that already exist, other registries 409
+	message := strings.TrimSpace(string(body))
+	if resp.StatusCode == http.StatusConflict ||
+		(resp.StatusCode == http.StatusForbidden && strings.Contains(message, "previously published")) {
+		return nil, newAPIError(http.StatusConflict, "package %s@%s already exists in %s", name, version, registry)
+	}
+	return nil, newAPIError(http.StatusBadGateway, "registry rejected %s@%s with %s: %s", name, version, resp.Status, message)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPackageCoordinates(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            PublishPackageOptions
+		serverName      string
+		specVersion     string
+		expectedName    string
+		expectedVersion string
+		expectedError   string
+	}{
+		{
+			name:            "Server name made npm-safe with the spec version",
+			serverName:      "Pet Store_API!",
+			specVersion:     "1.2.0",
+			expectedName:    "pet-store_api",
+			expectedVersion: "1.2.0",
+		},
+		{
+			name:            "Scope and version from the options",
+			opts:            PublishPackageOptions{Scope: "@acme", Version: "2.0.0-beta.1"},
+			serverName:      "petstore",
+			specVersion:     "v1",
+			expectedName:    "@acme/petstore",
+			expectedVersion: "2.0.0-beta.1",
+		},
+		{
+			name:          "Spec version that isn't semantic",
+			serverName:    "petstore",
+			specVersion:   "2024-05",
+			expectedError: `info.version "2024-05" is not a semantic version, set publish_package.version`,
+		},
+		{
+			name:          "Server name without usable characters",
+			serverName:    "!!!",
+			specVersion:   "1.0.0",
+			expectedError: `server_name "!!!" has no characters usable in a package name`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			name, version, err := packageCoordinates(&tc.opts, tc.serverName, tc.specVersion)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("error = %v, want %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("packageCoordinates: %v", err)
+			}
+			if name != tc.expectedName || version != tc.expectedVersion {
+				t.Errorf("coordinates = %s@%s, want %s@%s", name, version, tc.expectedName, tc.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestValidatePublishPackageRequiresAllowedHosts(t *testing.T) {
+	opts := &PublishPackageOptions{RegistryURL: "http://10.0.0.5:4873"}
+
+	err := (&ConversionService{}).validatePublishPackage(opts)
+	if err == nil || err.Error() != "publish_package is disabled, set PUBLISH_REGISTRY_HOSTS to enable it" {
+		t.Errorf("error without PUBLISH_REGISTRY_HOSTS = %v", err)
+	}
+
+	service := &ConversionService{publishRegistryHosts: []string{"npm.example.com"}}
+	if err := service.validatePublishPackage(opts); err == nil || !strings.Contains(err.Error(), `host "10.0.0.5" is not allowed`) {
+		t.Errorf("error for another host = %v", err)
+	}
+	opts.RegistryURL = "https://NPM.example.com/"
+	if err := service.validatePublishPackage(opts); err != nil {
+		t.Errorf("validatePublishPackage of an allowed host: %v", err)
+	}
+}
+
+func TestPublishPackage(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:   "Published",
+			status: http.StatusCreated,
+			body:   `{"ok": true}`,
+		},
+		{
+			name:           "npm reports an existing version as 403",
+			status:         http.StatusForbidden,
+			body:           `{"error": "You cannot publish over the previously published versions: 1.2.0."}`,
+			expectedStatus: http.StatusConflict,
+			expectedError:  "already exists in",
+		},
+		{
+			name:           "Other registries report it as 409",
+			status:         http.StatusConflict,
+			body:           `{"error": "conflict"}`,
+			expectedStatus: http.StatusConflict,
+			expectedError:  "already exists in",
+		},
+		{
+			name:           "Other 403s are registry errors",
+			status:         http.StatusForbidden,
+			body:           `{"error": "forbidden"}`,
+			expectedStatus: http.StatusBadGateway,
+			expectedError:  "registry rejected @acme/petstore@1.2.0 with 403 Forbidden",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var document map[string]interface{}
+			var authorization, path string
+			registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+				path = r.URL.EscapedPath()
+				json.NewDecoder(r.Body).Decode(&document)
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer registry.Close()
+
+			opts := &PublishPackageOptions{RegistryURL: registry.URL + "/", Token: "npm_token"}
+			published, err := publishPackage(context.Background(), opts, "@acme/petstore", "1.2.0", "Petstore", "mcp-config.yaml", []byte("server:\n  name: petstore\n"))
+			if authorization != "Bearer npm_token" || path != "/@acme%2Fpetstore" {
+				t.Errorf("request to %s with %q, want /@acme%%2Fpetstore with the token", path, authorization)
+			}
+
+			if tc.expectedStatus != 0 {
+				var apiErr *apiError
+				if !errors.As(err, &apiErr) || apiErr.status != tc.expectedStatus {
+					t.Fatalf("error = %v, want a %d", err, tc.expectedStatus)
+				}
+				if !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("error = %q, want it to contain %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("publishPackage: %v", err)
+			}
+			if expected := registry.URL + "/@acme/petstore/-/petstore-1.2.0.tgz"; published.Tarball != expected {
+				t.Errorf("tarball = %q, want %q", published.Tarball, expected)
+			}
+			if tags, _ := document["dist-tags"].(map[string]interface{}); tags["latest"] != "1.2.0" {
+				t.Errorf("dist-tags = %v, want latest 1.2.0", document["dist-tags"])
+			}
+		})
+	}
+}
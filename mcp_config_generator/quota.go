@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// errQuotaExceeded is returned by saveToStorage when a write would exceed the
+// storage quota of the object's prefix.
+var errQuotaExceeded = errors.New("storage quota exceeded")
+
+// storageQuota limits the objects stored under a prefix. Zero means unlimited.
+type storageQuota struct {
+	MaxObjects int64
+	MaxBytes   int64
+}
+
+// quotaUsage is a cached listing of a prefix.
+type quotaUsage struct {
+	objects   int64
+	bytes     int64
+	checkedAt time.Time
+}
+
+// quotaEnforcer rejects writes that would take a prefix over its quota. Usage
+// is computed by listing the prefix and cached for ttl to avoid listing on
+// every request.
+type quotaEnforcer struct {
+	mu           sync.Mutex
+	defaultQuota storageQuota
+	prefixQuotas map[string]storageQuota
+	ttl          time.Duration
+	usage        map[string]*quotaUsage
+}
+
+// newQuotaEnforcerFromEnv builds the enforcer from STORAGE_MAX_OBJECTS,
+// STORAGE_MAX_BYTES and STORAGE_PREFIX_QUOTAS. It returns nil when no limit
+// is configured.
+//
+// STORAGE_PREFIX_QUOTAS overrides the limits for individual prefixes as a
+// comma-separated list of prefix=maxObjects:maxBytes entries, for example
+// "mcp-configs/=5000:1073741824,openapi/=2000:0".
+func newQuotaEnforcerFromEnv() (*quotaEnforcer, error) {
+	enforcer := &quotaEnforcer{
+		prefixQuotas: make(map[string]storageQuota),
+		ttl:          30 * time.Second,
+		usage:        make(map[string]*quotaUsage),
+	}
+
+	var err error
+	if enforcer.defaultQuota.MaxObjects, err = parseInt64Env("STORAGE_MAX_OBJECTS"); err != nil {
+		return nil, err
+	}
+	if enforcer.defaultQuota.MaxBytes, err = parseInt64Env("STORAGE_MAX_BYTES"); err != nil {
+		return nil, err
+	}
+	if ttl := os.Getenv("STORAGE_QUOTA_CACHE_TTL"); ttl != "" {
+		if enforcer.ttl, err = time.ParseDuration(ttl); err != nil {
+			return nil, fmt.Errorf("invalid STORAGE_QUOTA_CACHE_TTL: %w", err)
+		}
+	}
+
+	for _, entry := range splitList(os.Getenv("STORAGE_PREFIX_QUOTAS")) {
+		prefix, limits, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid STORAGE_PREFIX_QUOTAS entry %q", entry)
+		}
+		objects, bytes, _ := strings.Cut(limits, ":")
+		var quota storageQuota
+		if quota.MaxObjects, err = parseQuotaLimit(objects); err != nil {
+			return nil, fmt.Errorf("invalid STORAGE_PREFIX_QUOTAS entry %q: %w", entry, err)
+		}
+		if quota.MaxBytes, err = parseQuotaLimit(bytes); err != nil {
+			return nil, fmt.Errorf("invalid STORAGE_PREFIX_QUOTAS entry %q: %w", entry, err)
+		}
+		enforcer.prefixQuotas[prefix] = quota
+	}
+
+	if enforcer.defaultQuota == (storageQuota{}) && len(enforcer.prefixQuotas) == 0 {
+		return nil, nil
+	}
+	return enforcer, nil
+}
+
+func parseInt64Env(key string) (int64, error) {
+	value, err := parseQuotaLimit(os.Getenv(key))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func parseQuotaLimit(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("%q is not a non-negative integer", value)
+	}
+	return limit, nil
+}
+
+// objectPrefix returns the top-level folder of an object name, e.g.
+// "mcp-configs/" for "mcp-configs/petstore-20240101-120000.yaml".
+func objectPrefix(objectName string) string {
+	if i := strings.Index(objectName, "/"); i >= 0 {
+		return objectName[:i+1]
+	}
+	return ""
+}
+
+func (q *quotaEnforcer) quotaFor(prefix string) storageQuota {
+	if quota, ok := q.prefixQuotas[prefix]; ok {
+		return quota
+	}
+	return q.defaultQuota
+}
+
+// quotaReservation is a write counted against the cached usage of its
+// prefix before it happens, so concurrent writes can't overrun the quota
+// together. It is released when the write fails.
+type quotaReservation struct {
+	enforcer *quotaEnforcer
+	prefix   string
+	usage    *quotaUsage
+	objects  int64
+	bytes    int64
+}
+
+// reserve checks that writing size bytes to objectName stays within the
+// quota of its prefix and, if so, counts the write against the cached
+// usage. Overwriting an object adds no object and only the difference in
+// size. It returns nil when the prefix has no quota.
+func (q *quotaEnforcer) reserve(ctx context.Context, bucket *storage.BucketHandle, objectName string, size int64) (*quotaReservation, error) {
+	if q == nil {
+		return nil, nil
+	}
+
+	prefix := objectPrefix(objectName)
+	quota := q.quotaFor(prefix)
+	if quota == (storageQuota{}) {
+		return nil, nil
+	}
+
+	objects, bytes := int64(1), size
+	attrs, err := bucket.Object(objectName).Attrs(ctx)
+	if err == nil {
+		objects, bytes = 0, size-attrs.Size
+	} else if !errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("failed to check storage usage of %q: %w", objectName, err)
+	}
+	return q.reserveUsage(ctx, bucket, prefix, quota, objects, bytes)
+}
+
+// reserveUsage counts objects and bytes more against the usage of prefix
+// unless that takes it over quota.
+func (q *quotaEnforcer) reserveUsage(ctx context.Context, bucket *storage.BucketHandle, prefix string, quota storageQuota, objects, bytes int64) (*quotaReservation, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage, err := q.currentUsage(ctx, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if quota.MaxObjects > 0 && objects > 0 && usage.objects+objects > quota.MaxObjects {
+		return nil, fmt.Errorf("%w: %q already holds %d objects (limit %d)", errQuotaExceeded, prefix, usage.objects, quota.MaxObjects)
+	}
+	if quota.MaxBytes > 0 && bytes > 0 && usage.bytes+bytes > quota.MaxBytes {
+		return nil, fmt.Errorf("%w: %q would hold %d bytes (limit %d)", errQuotaExceeded, prefix, usage.bytes+bytes, quota.MaxBytes)
+	}
+
+	usage.objects += objects
+	usage.bytes += bytes
+	return &quotaReservation{enforcer: q, prefix: prefix, usage: usage, objects: objects, bytes: bytes}, nil
+}
+
+// release takes a failed write back out of the cached usage. Usage listed
+// since the reservation was made doesn't include the write and is left as
+// it is.
+func (r *quotaReservation) release() {
+	if r == nil {
+		return
+	}
+	r.enforcer.mu.Lock()
+	defer r.enforcer.mu.Unlock()
+	if r.enforcer.usage[r.prefix] != r.usage {
+		return
+	}
+	r.usage.objects -= r.objects
+	r.usage.bytes -= r.bytes
+}
+
+// currentUsage returns the cached usage of a prefix, listing the bucket when
+// the cache is missing or older than the TTL. Callers must hold q.mu.
+func (q *quotaEnforcer) currentUsage(ctx context.Context, bucket *storage.BucketHandle, prefix string) (*quotaUsage, error) {
+	if usage, ok := q.usage[prefix]; ok && time.Since(usage.checkedAt) < q.ttl {
+		return usage, nil
+	}
+
+	usage := &quotaUsage{checkedAt: time.Now()}
+	it := bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list storage usage for %q: %w", prefix, err)
+		}
+		usage.objects++
+		usage.bytes += attrs.Size
+	}
+
+	q.usage[prefix] = usage
+	return usage, nil
+}
+
+// storageErrorStatus maps a saveToStorage error to its HTTP status.
+func storageErrorStatus(err error) int {
+	if errors.Is(err, errQuotaExceeded) {
+		return http.StatusInsufficientStorage
+	}
+	if errors.Is(err, errStorageWriteRate) {
+		return http.StatusServiceUnavailable
+	}
+	var exists *objectExistsError
+	if errors.As(err, &exists) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuotaReservationRelease(t *testing.T) {
+	quota := storageQuota{MaxObjects: 2, MaxBytes: 100}
+	enforcer := &quotaEnforcer{
+		defaultQuota: quota,
+		ttl:          time.Minute,
+		usage:        map[string]*quotaUsage{"openapi/": {objects: 1, bytes: 40, checkedAt: time.Now()}},
+	}
+	usage := enforcer.usage["openapi/"]
+
+	// A failed write gives its object and bytes back
+	reservation, err := enforcer.reserveUsage(context.Background(), nil, "openapi/", quota, 1, 50)
+	if err != nil {
+		t.Fatalf("reserveUsage: %v", err)
+	}
+	if _, err := enforcer.reserveUsage(context.Background(), nil, "openapi/", quota, 1, 1); !errors.Is(err, errQuotaExceeded) {
+		t.Fatalf("reserveUsage over the object limit = %v, want errQuotaExceeded", err)
+	}
+	reservation.release()
+	if usage.objects != 1 || usage.bytes != 40 {
+		t.Fatalf("usage after release = %d objects, %d bytes, want 1, 40", usage.objects, usage.bytes)
+	}
+
+	// Overwriting adds no object, and shrinking an object fits a full prefix
+	if _, err := enforcer.reserveUsage(context.Background(), nil, "openapi/", quota, 0, 60); err != nil {
+		t.Fatalf("reserveUsage of an overwrite: %v", err)
+	}
+	if _, err := enforcer.reserveUsage(context.Background(), nil, "openapi/", quota, 0, -10); err != nil {
+		t.Fatalf("reserveUsage of a smaller overwrite: %v", err)
+	}
+	if usage.objects != 1 || usage.bytes != 90 {
+		t.Errorf("usage after overwrites = %d objects, %d bytes, want 1, 90", usage.objects, usage.bytes)
+	}
+
+	// Usage listed again since the reservation doesn't include the write
+	reservation, err = enforcer.reserveUsage(context.Background(), nil, "openapi/", quota, 1, 5)
+	if err != nil {
+		t.Fatalf("reserveUsage: %v", err)
+	}
+	listed := &quotaUsage{objects: 1, bytes: 90, checkedAt: time.Now()}
+	enforcer.usage["openapi/"] = listed
+	reservation.release()
+	if listed.objects != 1 || listed.bytes != 90 {
+		t.Errorf("relisted usage after release = %d objects, %d bytes, want 1, 90", listed.objects, listed.bytes)
+	}
+
+	// Prefixes without a quota reserve nothing, which releases safely
+	reservation, err = (*quotaEnforcer)(nil).reserve(context.Background(), nil, "openapi/spec.yaml", 10)
+	if err != nil || reservation != nil {
+		t.Fatalf("reserve without an enforcer = %v, %v, want nothing", reservation, err)
+	}
+	reservation.release()
+}
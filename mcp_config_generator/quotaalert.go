@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/iterator"
+)
+
+// QuotaAlert is the JSON body POSTed to QUOTA_ALERT_WEBHOOK when a prefix
+// crosses a usage threshold.
+type QuotaAlert struct {
+	Bucket     string  `json:"bucket"`
+	Prefix     string  `json:"prefix"`
+	Threshold  int     `json:"threshold"`
+	Percent    float64 `json:"percent"`
+	Objects    int64   `json:"objects"`
+	MaxObjects int64   `json:"max_objects"`
+	Bytes      int64   `json:"bytes"`
+	MaxBytes   int64   `json:"max_bytes"`
+	CheckedAt  string  `json:"checked_at"`
+}
+
+// quotaAlerter periodically compares the storage usage of every prefix with
+// its quota and notifies a webhook the first time a threshold is crossed.
+type quotaAlerter struct {
+	webhookURL string
+	secret     string
+	thresholds []int
+	interval   time.Duration
+
+	// crossed holds the highest threshold already alerted for each prefix.
+	// It is lowered when usage drops, so a later crossing alerts again.
+	crossed map[string]int
+}
+
+// newQuotaAlerterFromEnv builds the alerter from QUOTA_ALERT_WEBHOOK,
+// QUOTA_ALERT_THRESHOLDS (default "80,90") and QUOTA_ALERT_INTERVAL (default
+// 5m). It returns nil when no webhook is configured.
+func newQuotaAlerterFromEnv(secret string) (*quotaAlerter, error) {
+	webhookURL := os.Getenv("QUOTA_ALERT_WEBHOOK")
+	if webhookURL == "" {
+		return nil, nil
+	}
+
+	alerter := &quotaAlerter{
+		webhookURL: webhookURL,
+		secret:     secret,
+		thresholds: []int{80, 90},
+		interval:   5 * time.Minute,
+		crossed:    make(map[string]int),
+	}
+	if thresholds := os.Getenv("QUOTA_ALERT_THRESHOLDS"); thresholds != "" {
+		alerter.thresholds = nil
+		for _, value := range splitList(thresholds) {
+			threshold, err := strconv.Atoi(value)
+			if err != nil || threshold <= 0 || threshold > 100 {
+				return nil, fmt.Errorf("invalid QUOTA_ALERT_THRESHOLDS entry %q, must be a percentage from 1 to 100", value)
+			}
+			alerter.thresholds = append(alerter.thresholds, threshold)
+		}
+		sort.Ints(alerter.thresholds)
+	}
+	if interval := os.Getenv("QUOTA_ALERT_INTERVAL"); interval != "" {
+		var err error
+		if alerter.interval, err = time.ParseDuration(interval); err != nil || alerter.interval <= 0 {
+			return nil, fmt.Errorf("invalid QUOTA_ALERT_INTERVAL %q", interval)
+		}
+	}
+	return alerter, nil
+}
+
+// run checks the usage every interval until ctx is done.
+func (a *quotaAlerter) run(ctx context.Context, s *ConversionService) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		if err := a.check(ctx, s); err != nil {
+			log.Printf("Quota alert check failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check lists the bucket once, refreshes the enforcer's cached usage and
+// alerts on every prefix that crossed a new threshold.
+func (a *quotaAlerter) check(ctx context.Context, s *ConversionService) error {
+	checkedAt := time.Now()
+	usage := make(map[string]*quotaUsage)
+	it := s.storageClient.Bucket(s.bucketName).Objects(ctx, nil)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list storage usage: %w", err)
+		}
+		prefix := objectPrefix(attrs.Name)
+		if usage[prefix] == nil {
+			usage[prefix] = &quotaUsage{checkedAt: checkedAt}
+		}
+		usage[prefix].objects++
+		usage[prefix].bytes += attrs.Size
+	}
+
+	s.quota.mu.Lock()
+	for prefix, prefixUsage := range usage {
+		s.quota.usage[prefix] = prefixUsage
+	}
+	s.quota.mu.Unlock()
+
+	// Prefixes that were emptied start over
+	for prefix := range a.crossed {
+		if usage[prefix] == nil {
+			delete(a.crossed, prefix)
+		}
+	}
+
+	prefixes := make([]string, 0, len(usage))
+	for prefix := range usage {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	for _, prefix := range prefixes {
+		quota := s.quota.quotaFor(prefix)
+		if quota == (storageQuota{}) {
+			continue
+		}
+		percent := usagePercent(usage[prefix], quota)
+		threshold := a.highestThreshold(percent)
+		if threshold <= a.crossed[prefix] {
+			a.crossed[prefix] = threshold
+			continue
+		}
+
+		alert := QuotaAlert{
+			Bucket:     s.bucketName,
+			Prefix:     prefix,
+			Threshold:  threshold,
+			Percent:    percent,
+			Objects:    usage[prefix].objects,
+			MaxObjects: quota.MaxObjects,
+			Bytes:      usage[prefix].bytes,
+			MaxBytes:   quota.MaxBytes,
+			CheckedAt:  checkedAt.UTC().Format(time.RFC3339),
+		}
+		// A failed alert is retried on the next check
+		if err := a.send(ctx, alert); err != nil {
+			log.Printf("Failed to send quota alert for %q: %v", prefix, err)
+			continue
+		}
+		a.crossed[prefix] = threshold
+	}
+	return nil
+}
+
+// highestThreshold returns the highest threshold percent has reached, or 0.
+func (a *quotaAlerter) highestThreshold(percent float64) int {
+	highest := 0
+	for _, threshold := range a.thresholds {
+		if percent >= float64(threshold) {
+			highest = threshold
+		}
+	}
+	return highest
+}
+
+// usagePercent returns the usage of the most constrained limit of a quota,
+// rounded to one decimal.
+func usagePercent(usage *quotaUsage, quota storageQuota) float64 {
+	var percent float64
+	if quota.MaxObjects > 0 {
+		percent = float64(usage.objects) * 100 / float64(quota.MaxObjects)
+	}
+	if quota.MaxBytes > 0 {
+		if bytesPercent := float64(usage.bytes) * 100 / float64(quota.MaxBytes); bytesPercent > percent {
+			percent = bytesPercent
+		}
+	}
+	return float64(int(percent*10+0.5)) / 10
+}
+
+// send POSTs an alert to the webhook. When WEBHOOK_SECRET is set the body is
+// signed with HMAC-SHA256 in the X-Signature-SHA256 header.
+func (a *quotaAlerter) send(ctx context.Context, alert QuotaAlert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.secret != "" {
+		mac := hmac.New(sha256.New, []byte(a.secret))
+		mac.Write(data)
+		req.Header.Set("X-Signature-SHA256", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces the parts of spec values matching a redact pattern.
+const redactedValue = "***REDACTED***"
+
+// compileRedactPatterns compiles the redact_patterns of a request.
+func compileRedactPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "invalid redact_patterns entry %q: %v", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// redactSpec replaces every match of the patterns in the scalar values of a
+// JSON or YAML spec and returns the redacted spec as YAML along with the
+// number of values changed. Keys are left alone so the document keeps its
+// structure. The spec is returned unchanged when nothing matched.
+func redactSpec(spec string, patterns []*regexp.Regexp) (string, int, error) {
+	if len(patterns) == 0 {
+		return spec, 0, nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(spec), &document); err != nil {
+		return "", 0, fmt.Errorf("failed to parse spec for redaction: %w", err)
+	}
+
+	count := redactNode(&document, patterns)
+	if count == 0 {
+		return spec, 0, nil
+	}
+
+	data, err := yaml.Marshal(&document)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to write redacted spec: %w", err)
+	}
+	return string(data), count, nil
+}
+
+func redactNode(node *yaml.Node, patterns []*regexp.Regexp) int {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		value := node.Value
+		for _, re := range patterns {
+			value = re.ReplaceAllLiteralString(value, redactedValue)
+		}
+		if value == node.Value {
+			return 0
+		}
+		node.Value = value
+		node.Tag = "!!str"
+		node.Style = 0
+		return 1
+	case yaml.MappingNode:
+		count := 0
+		// Content alternates keys and values; only values are redacted
+		for i := 1; i < len(node.Content); i += 2 {
+			count += redactNode(node.Content[i], patterns)
+		}
+		return count
+	default:
+		count := 0
+		for _, child := range node.Content {
+			count += redactNode(child, patterns)
+		}
+		return count
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redisMaxIdle is how many idle connections a redisClient keeps.
+const redisMaxIdle = 8
+
+// redisClient speaks just enough of the Redis protocol (RESP) for the
+// conversion cache: GET and SET with an expiry, over a small pool of
+// connections. Every command has a deadline of timeout.
+type redisClient struct {
+	addr   string
+	useTLS bool
+	// host is addr's host name, without the brackets of IPv6 addresses,
+	// which TLS certificates are checked against
+	host     string
+	username string
+	password string
+	db       int
+	timeout  time.Duration
+
+	idle chan *redisConn
+}
+
+// redisConn is a pooled connection with its buffered reader.
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// errRedisNil is the reply to a GET of a missing key.
+var errRedisNil = errors.New("redis: nil")
+
+// newRedisClient parses a redis:// or rediss:// (TLS) URL such as
+// redis://:password@host:6379/0. Nothing is dialled until the first command.
+func newRedisClient(rawURL string, timeout time.Duration) (*redisClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("REDIS_URL must start with redis:// or rediss://, got %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("REDIS_URL has no host")
+	}
+
+	client := &redisClient{
+		addr:    u.Host,
+		useTLS:  u.Scheme == "rediss",
+		host:    u.Hostname(),
+		timeout: timeout,
+		idle:    make(chan *redisConn, redisMaxIdle),
+	}
+	if u.Port() == "" {
+		client.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		client.username = u.User.Username()
+		client.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		client.db, err = strconv.Atoi(db)
+		if err != nil || client.db < 0 {
+			return nil, fmt.Errorf("REDIS_URL database must be a non-negative number, got %q", db)
+		}
+	}
+	return client, nil
+}
+
+// String describes the server without its credentials.
+func (c *redisClient) String() string {
+	return c.addr
+}
+
+// Get returns the value of key, or errRedisNil when it isn't set.
+func (c *redisClient) Get(key string) ([]byte, error) {
+	reply, err := c.do("GET", key)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected GET reply %v", reply)
+	}
+	return value, nil
+}
+
+// Set stores value under key for ttl.
+func (c *redisClient) Set(key string, value []byte, ttl time.Duration) error {
+	_, err := c.do("SET", key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+// do sends one command and reads its reply. Connections that fail are
+// closed rather than returned to the pool; error replies leave them usable.
+func (c *redisClient) do(args ...string) (interface{}, error) {
+	conn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.command(c.timeout, args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) && err != errRedisNil {
+		conn.conn.Close()
+		return nil, err
+	}
+	c.put(conn)
+	return reply, err
+}
+
+// get takes an idle connection or dials a new one, authenticating and
+// selecting the database.
+func (c *redisClient) get() (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: c.timeout}
+	var conn net.Conn
+	var err error
+	if c.useTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.addr, &tls.Config{ServerName: c.host})
+	} else {
+		conn, err = dialer.Dial("tcp", c.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+	if c.password != "" {
+		args := []string{"AUTH", c.password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.password}
+		}
+		if _, err := rc.command(c.timeout, args...); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis AUTH failed: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := rc.command(c.timeout, "SELECT", strconv.Itoa(c.db)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis SELECT failed: %w", err)
+		}
+	}
+	return rc, nil
+}
+
+// put returns a connection to the pool, closing it when the pool is full.
+func (c *redisClient) put(conn *redisConn) {
+	select {
+	case c.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+// redisError is an error reply of the server, such as a wrong password.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// command writes args as a RESP array of bulk strings and reads the reply.
+func (rc *redisConn) command(timeout time.Duration, args ...string) (interface{}, error) {
+	if err := rc.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	var request strings.Builder
+	fmt.Fprintf(&request, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&request, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(rc.conn, request.String()); err != nil {
+		return nil, err
+	}
+	return rc.readReply()
+}
+
+// readReply reads a simple string, error, integer or bulk string reply.
+// Commands returning arrays aren't used.
+func (rc *redisConn) readReply() (interface{}, error) {
+	line, err := rc.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line[1:])
+		}
+		if length < 0 {
+			return nil, errRedisNil
+		}
+		value := make([]byte, length+2)
+		if _, err := io.ReadFull(rc.reader, value); err != nil {
+			return nil, err
+		}
+		return value[:length], nil
+	default:
+		return nil, fmt.Errorf("redis: unsupported reply type %q", line[0])
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// defaultRequestIDHeader carries the request ID unless REQUEST_ID_HEADER
+// names another header.
+const defaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds incoming request IDs that are reused.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// requestIDHeaderFromEnv returns the header named by REQUEST_ID_HEADER, or
+// X-Request-ID.
+func requestIDHeaderFromEnv() string {
+	if header := os.Getenv("REQUEST_ID_HEADER"); header != "" {
+		return http.CanonicalHeaderKey(header)
+	}
+	return defaultRequestIDHeader
+}
+
+// withRequestID reuses the request ID sent in header, or generates one, then
+// echoes it in the same response header, stores it in the request context
+// and logs each request under request_id.
+func withRequestID(header string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(header)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(header, requestID)
+
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, requestID)))
+		log.Printf("request_id=%s method=%s path=%s status=%d duration=%s", requestID, r.Method, r.URL.Path, recorder.status, time.Since(started).Round(time.Millisecond))
+	})
+}
+
+// requestIDFromContext returns the ID of the request ctx belongs to.
+func requestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// validRequestID reports whether an incoming ID is safe to reuse in headers
+// and logs: non-empty, bounded and printable ASCII without spaces.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns 16 random bytes as hex.
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(id)
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
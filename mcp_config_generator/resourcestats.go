@@ -0,0 +1,79 @@
+package main
+
+import (
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// conversionsInFlight counts the conversions being measured, so stats taken
+// while others ran can be told apart.
+var conversionsInFlight int64
+
+// ResourceStats is what a conversion cost, returned with
+// include_resource_stats. Only the wall time is exact. The Go runtime and
+// the kernel only count memory and CPU per process, so allocations and CPU
+// time are the deltas of the whole process while the conversion ran: they
+// include the work of concurrent requests and the garbage collector, and
+// are only reliable when concurrent_conversions is 0.
+type ResourceStats struct {
+	WallMs int64 `json:"wall_ms"`
+	// CPUMs is the user and system CPU time the process used
+	CPUMs int64 `json:"cpu_ms"`
+	// AllocatedBytes and Allocations are the heap allocations made, including
+	// memory already freed again
+	AllocatedBytes uint64 `json:"allocated_bytes"`
+	Allocations    uint64 `json:"allocations"`
+	// ConcurrentConversions is the largest number of other measured
+	// conversions running at the start or the end
+	ConcurrentConversions int64 `json:"concurrent_conversions"`
+}
+
+// resourceMeter measures a conversion from newResourceMeter to stop.
+type resourceMeter struct {
+	started    time.Time
+	cpu        time.Duration
+	memory     runtime.MemStats
+	concurrent int64
+}
+
+// newResourceMeter starts measuring. Reading the memory stats briefly stops
+// the world, which is why the stats are opt-in.
+func newResourceMeter() *resourceMeter {
+	m := &resourceMeter{concurrent: atomic.AddInt64(&conversionsInFlight, 1) - 1}
+	runtime.ReadMemStats(&m.memory)
+	m.cpu = processCPUTime()
+	m.started = time.Now()
+	return m
+}
+
+// stop returns the resources used since the meter started.
+func (m *resourceMeter) stop() *ResourceStats {
+	wall := time.Since(m.started)
+	cpu := processCPUTime()
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	concurrent := atomic.AddInt64(&conversionsInFlight, -1)
+	if concurrent < m.concurrent {
+		concurrent = m.concurrent
+	}
+
+	return &ResourceStats{
+		WallMs:                wall.Milliseconds(),
+		CPUMs:                 (cpu - m.cpu).Milliseconds(),
+		AllocatedBytes:        memory.TotalAlloc - m.memory.TotalAlloc,
+		Allocations:           memory.Mallocs - m.memory.Mallocs,
+		ConcurrentConversions: concurrent,
+	}
+}
+
+// processCPUTime returns the user and system CPU time the process has used,
+// or 0 when it can't be read.
+func processCPUTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// wantsCamelCase reports whether the client asked for camelCase JSON keys
+// with the X-Response-Case header or the response_case query parameter.
+func wantsCamelCase(r *http.Request) bool {
+	responseCase := r.Header.Get("X-Response-Case")
+	if responseCase == "" {
+		responseCase = r.URL.Query().Get("response_case")
+	}
+	return strings.EqualFold(responseCase, "camel")
+}
+
+// withResponseCase re-encodes JSON response bodies with camelCase keys for
+// clients that ask for it. The response structs keep their snake_case tags,
+// so both conventions are served from the same handlers. Only field names
+// are renamed: map keys such as operationIds or tag names are data.
+func withResponseCase(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !wantsCamelCase(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &bufferedResponseWriter{header: w.Header(), status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		body := recorder.body.Bytes()
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			converted, err := camelCaseJSONKeys(body)
+			if err != nil {
+				log.Printf("Warning: Failed to convert response keys to camelCase: %v", err)
+			} else {
+				body = converted
+			}
+		}
+
+		w.Header().Del("Content-Length")
+		w.WriteHeader(recorder.status)
+		w.Write(body)
+	})
+}
+
+// bufferedResponseWriter holds a response so it can be rewritten before it is
+// sent. Headers are shared with the underlying writer.
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponseWriter) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+// caseNode describes the JSON shape of a response type, so only the names
+// of struct fields are renamed. A nil node is a value of unknown shape,
+// copied as it is.
+type caseNode struct {
+	// fields are the JSON names of a struct's fields
+	fields map[string]*caseNode
+	// elem is the element of a slice or the value of a map
+	elem *caseNode
+	// isMap marks maps, whose keys are kept
+	isMap bool
+}
+
+// responseCaseTypes are the responses whose fields are renamed.
+// Responses built from maps, such as errors and health checks, only have
+// their top-level keys renamed.
+var responseCaseTypes = []interface{}{
+	ConversionResponse{},
+	BatchConversionResponse{},
+	UploadResponse{},
+	ToolPreviewResponse{},
+	FieldUsageSummary{},
+}
+
+// responseCaseRoot merges the fields of every responseCaseTypes entry.
+var responseCaseRoot = buildResponseCaseRoot()
+
+func buildResponseCaseRoot() *caseNode {
+	root := &caseNode{fields: make(map[string]*caseNode)}
+	seen := make(map[reflect.Type]*caseNode)
+	for _, response := range responseCaseTypes {
+		for name, field := range newCaseNode(reflect.TypeOf(response), seen).fields {
+			if _, ok := root.fields[name]; !ok {
+				root.fields[name] = field
+			}
+		}
+	}
+	return root
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// newCaseNode describes t. Types with their own MarshalJSON and interfaces
+// have no known shape.
+func newCaseNode(t reflect.Type, seen map[reflect.Type]*caseNode) *caseNode {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node, ok := seen[t]; ok {
+		return node
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		node := &caseNode{fields: make(map[string]*caseNode)}
+		seen[t] = node
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if field.Anonymous && name == "" {
+				if embedded := newCaseNode(field.Type, seen); embedded != nil {
+					for embeddedName, embeddedField := range embedded.fields {
+						node.fields[embeddedName] = embeddedField
+					}
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			node.fields[name] = newCaseNode(field.Type, seen)
+		}
+		return node
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		return &caseNode{elem: newCaseNode(t.Elem(), seen)}
+	case reflect.Map:
+		return &caseNode{isMap: true, elem: newCaseNode(t.Elem(), seen)}
+	default:
+		return nil
+	}
+}
+
+// camelCaseJSONKeys rewrites the field names of a JSON response from
+// snake_case to camelCase, keeping the key order and values untouched.
+func camelCaseJSONKeys(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var out bytes.Buffer
+	for {
+		if err := rewriteJSONValue(decoder, &out, responseCaseRoot); err != nil {
+			if err == io.EOF {
+				return out.Bytes(), nil
+			}
+			return nil, err
+		}
+		// json.Encoder terminates each value with a newline
+		out.WriteByte('\n')
+	}
+}
+
+// rewriteJSONValue copies the next JSON value from decoder to out, renaming
+// the fields node describes. Keys a struct node doesn't know, the top-level
+// keys of map-built responses, are renamed with their values copied as
+// they are.
+func rewriteJSONValue(decoder *json.Decoder, out *bytes.Buffer, node *caseNode) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		out.WriteByte('{')
+		for first := true; decoder.More(); first = false {
+			if !first {
+				out.WriteByte(',')
+			}
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := keyToken.(string)
+			var child *caseNode
+			switch {
+			case node == nil:
+			case node.isMap:
+				child = node.elem
+			case node.fields != nil:
+				child = node.fields[key]
+				key = snakeToCamel(key)
+			}
+			encodedKey, _ := json.Marshal(key)
+			out.Write(encodedKey)
+			out.WriteByte(':')
+			if err := rewriteJSONValue(decoder, out, child); err != nil {
+				return unexpectedEOF(err)
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return unexpectedEOF(err)
+		}
+		out.WriteByte('}')
+	case json.Delim('['):
+		var elem *caseNode
+		if node != nil {
+			elem = node.elem
+		}
+		out.WriteByte('[')
+		for first := true; decoder.More(); first = false {
+			if !first {
+				out.WriteByte(',')
+			}
+			if err := rewriteJSONValue(decoder, out, elem); err != nil {
+				return unexpectedEOF(err)
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return unexpectedEOF(err)
+		}
+		out.WriteByte(']')
+	default:
+		encoded, err := json.Marshal(token)
+		if err != nil {
+			return fmt.Errorf("failed to encode %v: %w", token, err)
+		}
+		out.Write(encoded)
+	}
+	return nil
+}
+
+// unexpectedEOF keeps a truncated document from being reported as a clean end
+// of input.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// snakeToCamel converts snake_case to camelCase, e.g. "mcp_config_file_url"
+// to "mcpConfigFileUrl". Keys without underscores are returned unchanged.
+func snakeToCamel(key string) string {
+	if !strings.Contains(key, "_") {
+		return key
+	}
+
+	parts := strings.Split(key, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
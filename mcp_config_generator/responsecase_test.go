@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCamelCaseJSONKeysKeepsMapKeys(t *testing.T) {
+	response := ConversionResponse{
+		Success:          true,
+		MCPConfigFileURL: "https://storage.googleapis.com/bucket/mcp-configs/orders.yaml",
+		EffectiveOptions: &EffectiveOptions{
+			ForceRequired: map[string][]string{"list_orders": {"page_size"}},
+			CoerceTypes:   map[string]string{"integer": "relax"},
+			ServerConfig:  map[string]interface{}{"api_key": map[string]interface{}{"header_name": "X-Key"}},
+		},
+		Coverage: &CoverageReport{
+			ByTag: map[string]*CoverageStat{"order_items": {Operations: 2, Tools: 1}},
+		},
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("failed to marshal the response: %v", err)
+	}
+	converted, err := camelCaseJSONKeys(data)
+	if err != nil {
+		t.Fatalf("camelCaseJSONKeys: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(converted, &got); err != nil {
+		t.Fatalf("failed to decode %s: %v", converted, err)
+	}
+	if got["mcpConfigFileUrl"] != response.MCPConfigFileURL {
+		t.Errorf("mcpConfigFileUrl = %v in %s", got["mcpConfigFileUrl"], converted)
+	}
+	options, _ := got["effectiveOptions"].(map[string]interface{})
+	forceRequired, _ := options["forceRequired"].(map[string]interface{})
+	if fields, _ := forceRequired["list_orders"].([]interface{}); len(fields) != 1 || fields[0] != "page_size" {
+		t.Errorf("forceRequired = %v, want the list_orders operationId kept", options["forceRequired"])
+	}
+	serverConfig, _ := options["serverConfig"].(map[string]interface{})
+	if apiKey, _ := serverConfig["api_key"].(map[string]interface{}); apiKey["header_name"] != "X-Key" {
+		t.Errorf("serverConfig = %v, want its keys kept", options["serverConfig"])
+	}
+	coverage, _ := got["coverage"].(map[string]interface{})
+	byTag, _ := coverage["byTag"].(map[string]interface{})
+	if _, ok := byTag["order_items"]; !ok {
+		t.Errorf("coverage.byTag = %v, want the order_items tag kept", coverage["byTag"])
+	}
+}
+
+func TestWithResponseCase(t *testing.T) {
+	handler := withResponseCase(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"key_id": "abc",
+			"checks": map[string]string{"storage_write": "ok"},
+		})
+	}))
+
+	for header, expected := range map[string]string{
+		"":      `{"checks":{"storage_write":"ok"},"key_id":"abc"}` + "\n",
+		"camel": `{"checks":{"storage_write":"ok"},"keyId":"abc"}` + "\n",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/pubkey", nil)
+		req.Header.Set("X-Response-Case", header)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		if recorder.Body.String() != expected {
+			t.Errorf("body with X-Response-Case %q = %s, want %s", header, recorder.Body, expected)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ResponseCodeSelection is the response_codes_in_description of a request:
+// a keyword such as "success-only", or an array of response codes.
+type ResponseCodeSelection []string
+
+func (s *ResponseCodeSelection) UnmarshalJSON(data []byte) error {
+	var keyword string
+	if err := json.Unmarshal(data, &keyword); err == nil {
+		*s = ResponseCodeSelection{keyword}
+		return nil
+	}
+
+	var codes []string
+	if err := json.Unmarshal(data, &codes); err != nil {
+		return fmt.Errorf("response_codes_in_description must be a string or an array of response codes")
+	}
+	*s = codes
+	return nil
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Defaults of the conversion result cache
+const (
+	defaultConversionCacheTTL = time.Hour
+	// memoryConversionCacheSize bounds the in-memory cache used without
+	// REDIS_URL
+	memoryConversionCacheSize = 256
+	// redisBackoff is how long Redis is skipped after it failed, so an
+	// outage doesn't add a timeout to every conversion
+	redisBackoff = 30 * time.Second
+)
+
+// cachedConversion is a successful conversion stored in the result cache:
+// the response, with the config and the URLs of the stored files.
+type cachedConversion struct {
+	Response        *ConversionResponse `json:"response"`
+	MCPConfigObject string              `json:"mcp_config_object"`
+	OpenAPIObject   string              `json:"openapi_object,omitempty"`
+}
+
+// conversionStore holds serialized conversions for a TTL. Stores are best
+// effort: failures are misses.
+type conversionStore interface {
+	get(key string) ([]byte, bool)
+	set(key string, value []byte, ttl time.Duration)
+}
+
+// resultCache returns the result of an earlier identical conversion
+// instead of converting and storing the spec again. Conversions are
+// identical when their requests, spec included, are, after defaults were
+// applied. It is shared by the instances through Redis when REDIS_URL is
+// set, otherwise each instance keeps its own in memory.
+type resultCache struct {
+	store conversionStore
+	ttl   time.Duration
+	stats *statsdClient
+}
+
+// newResultCacheFromEnv enables the cache when CONVERSION_CACHE_TTL or
+// REDIS_URL is set. It returns nil, meaning no cache, otherwise. An
+// unreachable Redis doesn't fail startup, only the URL is checked.
+func newResultCacheFromEnv(stats *statsdClient) (*resultCache, error) {
+	ttlValue := os.Getenv("CONVERSION_CACHE_TTL")
+	redisURL := os.Getenv("REDIS_URL")
+	if ttlValue == "" && redisURL == "" {
+		return nil, nil
+	}
+
+	cache := &resultCache{ttl: defaultConversionCacheTTL, stats: stats}
+	if ttlValue != "" {
+		ttl, err := time.ParseDuration(ttlValue)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("CONVERSION_CACHE_TTL must be a positive duration such as 1h, got %q", ttlValue)
+		}
+		cache.ttl = ttl
+	}
+
+	if redisURL == "" {
+		cache.store = newMemoryConversionStore(memoryConversionCacheSize)
+		return cache, nil
+	}
+	timeout := 500 * time.Millisecond
+	if value := os.Getenv("REDIS_TIMEOUT"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("REDIS_TIMEOUT must be a positive duration, got %q", value)
+		}
+		timeout = parsed
+	}
+	client, err := newRedisClient(redisURL, timeout)
+	if err != nil {
+		return nil, err
+	}
+	cache.store = &redisConversionStore{client: client}
+	return cache, nil
+}
+
+// String describes where conversions are cached.
+func (c *resultCache) String() string {
+	if store, ok := c.store.(*redisConversionStore); ok {
+		return fmt.Sprintf("Redis at %s for %s", store.client, c.ttl)
+	}
+	return fmt.Sprintf("memory for %s", c.ttl)
+}
+
+// key hashes a request with its defaults applied. Requests that publish a
+// package or diff against the previous conversion have effects beyond their
+// result, so they get no key and are never cached.
+func (c *resultCache) key(req ConversionRequest) string {
+	if c == nil || req.PublishPackage != nil || req.GenerateChangelog {
+		return ""
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "mcp-conversion:" + hex.EncodeToString(sum[:])
+}
+
+// get returns a copy of the conversion cached under key. A conversion whose
+// stored spec or config no longer exists, deleted by RETENTION_DAYS or by
+// hand, is a miss, since its URLs would be broken.
+func (c *resultCache) get(ctx context.Context, key string, objectExists func(ctx context.Context, name string) (bool, error)) (*cachedConversion, bool) {
+	if key == "" {
+		return nil, false
+	}
+	data, ok := c.store.get(key)
+	if !ok {
+		c.stats.Incr("conversion_cache.miss")
+		return nil, false
+	}
+	var cached cachedConversion
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Response == nil {
+		debugf(ctx, "ignoring unreadable cached conversion %s: %v", key, err)
+		c.stats.Incr("conversion_cache.miss")
+		return nil, false
+	}
+	for _, name := range []string{cached.OpenAPIObject, cached.MCPConfigObject} {
+		if name == "" {
+			continue
+		}
+		if exists, err := objectExists(ctx, name); err != nil || !exists {
+			debugf(ctx, "ignoring cached conversion %s, %s is gone: %v", key, logObjectName(name), err)
+			c.stats.Incr("conversion_cache.miss")
+			return nil, false
+		}
+	}
+	c.stats.Incr("conversion_cache.hit")
+	return &cached, true
+}
+
+// set caches a successful conversion under key.
+func (c *resultCache) set(key string, response *ConversionResponse) {
+	if key == "" {
+		return
+	}
+	data, err := json.Marshal(cachedConversion{Response: response, MCPConfigObject: response.mcpConfigObject, OpenAPIObject: response.openAPIObject})
+	if err != nil {
+		return
+	}
+	c.store.set(key, data, c.ttl)
+}
+
+// memoryConversionStore keeps conversions in memory until they expire. When
+// full, the entry closest to expiry makes room.
+type memoryConversionStore struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]memoryConversion
+}
+
+type memoryConversion struct {
+	value   []byte
+	expires time.Time
+}
+
+func newMemoryConversionStore(size int) *memoryConversionStore {
+	return &memoryConversionStore{size: size, entries: make(map[string]memoryConversion)}
+}
+
+func (m *memoryConversionStore) get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (m *memoryConversionStore) set(key string, value []byte, ttl time.Duration) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for existing, entry := range m.entries {
+		if now.After(entry.expires) {
+			delete(m.entries, existing)
+		}
+	}
+	if _, ok := m.entries[key]; !ok && len(m.entries) >= m.size {
+		var soonest string
+		for existing, entry := range m.entries {
+			if soonest == "" || entry.expires.Before(m.entries[soonest].expires) {
+				soonest = existing
+			}
+		}
+		delete(m.entries, soonest)
+	}
+	m.entries[key] = memoryConversion{value: value, expires: now.Add(ttl)}
+}
+
+// redisConversionStore keeps conversions in Redis, shared by every
+// instance. While Redis fails, conversions run uncached: each failure
+// skips Redis for redisBackoff and is logged once per outage.
+type redisConversionStore struct {
+	client *redisClient
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+func (r *redisConversionStore) get(key string) ([]byte, bool) {
+	if r.down() {
+		return nil, false
+	}
+	value, err := r.client.Get(key)
+	if err == errRedisNil {
+		r.recovered()
+		return nil, false
+	}
+	if err != nil {
+		r.failed(err)
+		return nil, false
+	}
+	r.recovered()
+	return value, true
+}
+
+func (r *redisConversionStore) set(key string, value []byte, ttl time.Duration) {
+	if r.down() {
+		return
+	}
+	if err := r.client.Set(key, value, ttl); err != nil {
+		r.failed(err)
+		return
+	}
+	r.recovered()
+}
+
+// down reports whether Redis is being skipped after a failure.
+func (r *redisConversionStore) down() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Now().Before(r.downUntil)
+}
+
+func (r *redisConversionStore) failed(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.downUntil.IsZero() {
+		log.Printf("Warning: Conversion cache unavailable, converting without it: %v", err)
+	}
+	r.downUntil = time.Now().Add(redisBackoff)
+}
+
+func (r *redisConversionStore) recovered() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.downUntil.IsZero() {
+		log.Printf("Conversion cache available again")
+		r.downUntil = time.Time{}
+	}
+}
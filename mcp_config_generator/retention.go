@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+)
+
+// retentionPrefixes are the prefixes whose objects expire.
+var retentionPrefixes = []string{"openapi/", "mcp-configs/", signaturePrefix}
+
+// retentionPinMetadata is the object metadata key that exempts an object
+// from expiry, set e.g. with storage_headers.
+const retentionPinMetadata = "do-not-delete"
+
+// retentionJanitor periodically deletes the specs and configs that are older
+// than the retention period.
+type retentionJanitor struct {
+	maxAge   time.Duration
+	interval time.Duration
+}
+
+// newRetentionJanitorFromEnv builds the janitor from RETENTION_DAYS and
+// RETENTION_SWEEP_INTERVAL (default 1h). It returns nil when no retention
+// period is configured.
+func newRetentionJanitorFromEnv() (*retentionJanitor, error) {
+	value := os.Getenv("RETENTION_DAYS")
+	if value == "" {
+		return nil, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		return nil, fmt.Errorf("invalid RETENTION_DAYS %q, must be a positive number of days", value)
+	}
+
+	janitor := &retentionJanitor{
+		maxAge:   time.Duration(days) * 24 * time.Hour,
+		interval: time.Hour,
+	}
+	if interval := os.Getenv("RETENTION_SWEEP_INTERVAL"); interval != "" {
+		if janitor.interval, err = time.ParseDuration(interval); err != nil || janitor.interval <= 0 {
+			return nil, fmt.Errorf("invalid RETENTION_SWEEP_INTERVAL %q", interval)
+		}
+	}
+	return janitor, nil
+}
+
+// run sweeps every interval until ctx is done.
+func (j *retentionJanitor) run(ctx context.Context, s *ConversionService) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		deleted, err := j.sweep(ctx, s)
+		if err != nil {
+			log.Printf("Retention sweep failed: %v", err)
+		}
+		if deleted > 0 {
+			log.Printf("Retention sweep deleted %d object(s)", deleted)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep deletes the expired objects under retentionPrefixes and returns how
+// many were deleted. Pinned objects are kept. In dry-run mode the deletions
+// are only logged.
+func (j *retentionJanitor) sweep(ctx context.Context, s *ConversionService) (int, error) {
+	cutoff := time.Now().Add(-j.maxAge)
+	bucket := s.storageClient.Bucket(s.bucketName)
+	deleted := 0
+	for _, prefix := range retentionPrefixes {
+		it := bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+		for {
+			attrs, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return deleted, fmt.Errorf("failed to list %s: %w", prefix, err)
+			}
+			if !attrs.Created.Before(cutoff) || retentionPinned(attrs.Metadata) {
+				continue
+			}
+
+			if s.storageDryRun {
+				log.Printf("Dry run: retention would delete %s, created %s", logObjectName(attrs.Name), attrs.Created.UTC().Format(time.RFC3339))
+				continue
+			}
+			// The generation condition keeps an object rewritten since it was
+			// listed
+			err = bucket.Object(attrs.Name).If(storage.Conditions{GenerationMatch: attrs.Generation}).Delete(ctx)
+			var apiErr *googleapi.Error
+			if errors.Is(err, storage.ErrObjectNotExist) || (errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed) {
+				continue
+			}
+			if err != nil {
+				s.stats.Incr("retention.error")
+				log.Printf("Retention failed to delete %s: %v", logObjectName(attrs.Name), err)
+				continue
+			}
+			s.stats.Incr("retention.deleted")
+			log.Printf("Retention deleted %s, created %s", logObjectName(attrs.Name), attrs.Created.UTC().Format(time.RFC3339))
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// retentionPinned reports whether an object's metadata exempts it from
+// expiry: a do-not-delete entry with any value but "false".
+func retentionPinned(metadata map[string]string) bool {
+	value, ok := metadata[retentionPinMetadata]
+	return ok && !strings.EqualFold(strings.TrimSpace(value), "false")
+}
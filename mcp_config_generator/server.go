@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// shutdownTimeout is how long in-flight requests get to finish after a
+// termination signal.
+const shutdownTimeout = 30 * time.Second
+
+// serverTimeouts are the http.Server timeouts and the environment variables
+// that override them. Writes get the most time since a conversion stores
+// several objects before it responds.
+var serverTimeouts = []struct {
+	env          string
+	defaultValue time.Duration
+	set          func(*http.Server, time.Duration)
+}{
+	{"SERVER_READ_HEADER_TIMEOUT", 10 * time.Second, func(s *http.Server, d time.Duration) { s.ReadHeaderTimeout = d }},
+	{"SERVER_READ_TIMEOUT", 60 * time.Second, func(s *http.Server, d time.Duration) { s.ReadTimeout = d }},
+	{"SERVER_WRITE_TIMEOUT", 120 * time.Second, func(s *http.Server, d time.Duration) { s.WriteTimeout = d }},
+	{"SERVER_IDLE_TIMEOUT", 120 * time.Second, func(s *http.Server, d time.Duration) { s.IdleTimeout = d }},
+}
+
+// configureTimeouts sets the server's timeouts from the environment, falling
+// back to the defaults above. A value of 0 disables that timeout.
+func configureTimeouts(server *http.Server) error {
+	for _, timeout := range serverTimeouts {
+		value := timeout.defaultValue
+		if raw := os.Getenv(timeout.env); raw != "" {
+			var err error
+			if value, err = time.ParseDuration(raw); err != nil || value < 0 {
+				return fmt.Errorf("invalid %s %q, must be a non-negative duration such as 30s", timeout.env, raw)
+			}
+		}
+		timeout.set(server, value)
+	}
+	return nil
+}
+
+// h2cEnabledFromEnv parses ENABLE_H2C.
+func h2cEnabledFromEnv() (bool, error) {
+	value := os.Getenv("ENABLE_H2C")
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("ENABLE_H2C must be true or false")
+	}
+	return enabled, nil
+}
+
+// enableH2C lets the server speak HTTP/2 without TLS to clients that connect
+// with prior knowledge or send an h2c upgrade, while HTTP/1.1 clients are
+// served as before. It must run after configureTimeouts: the HTTP/2 server
+// only takes the idle timeout from its own config, and clears the deadlines
+// the HTTP/1.1 server set on the connection it takes over.
+func enableH2C(server *http.Server) {
+	server.Handler = h2c.NewHandler(server.Handler, &http2.Server{IdleTimeout: server.IdleTimeout})
+}
+
+// tlsConfig is used when the service terminates TLS itself. It requires TLS
+// 1.2 or newer and limits TLS 1.2 to AEAD cipher suites with forward secrecy;
+// TLS 1.3 suites are not configurable and are all considered secure.
+func tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+	}
+}
+
+// runServer serves HTTPS when TLS_CERT_FILE and TLS_KEY_FILE are both set and
+// plain HTTP otherwise. On SIGINT or SIGTERM it stops accepting connections
+// and waits for in-flight requests before returning.
+func runServer(server *http.Server) error {
+	certFile := os.Getenv("TLS_CERT_FILE")
+	keyFile := os.Getenv("TLS_KEY_FILE")
+	if (certFile == "") != (keyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if certFile != "" {
+			server.TLSConfig = tlsConfig()
+			log.Printf("Serving HTTPS on %s", server.Addr)
+			serveErr <- server.ListenAndServeTLS(certFile, keyFile)
+		} else {
+			log.Printf("Serving HTTP on %s", server.Addr)
+			serveErr <- server.ListenAndServe()
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case sig := <-signals:
+		log.Printf("Received %s, shutting down", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	log.Printf("Server stopped")
+	return nil
+}
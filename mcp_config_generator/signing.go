@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// signatureAlgorithm is the algorithm of the config signatures.
+const signatureAlgorithm = "ed25519"
+
+// outputSigner signs the generated MCP configs so consumers can verify they
+// were produced by this service.
+type outputSigner struct {
+	key ed25519.PrivateKey
+	// keyID identifies the public key, so verifiers notice a key rotation
+	keyID string
+}
+
+// ConfigSignature is the detached signature of a stored MCP config.
+type ConfigSignature struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	// Value is the base64-encoded signature of the config bytes
+	Value string `json:"value"`
+	// URL is where the raw signature is stored, next to the config
+	URL string `json:"url,omitempty"`
+}
+
+// newOutputSignerFromEnv loads the Ed25519 private key of SIGNING_KEY_FILE,
+// a PKCS #8 PEM file, when SIGN_OUTPUT is "true". It returns nil when
+// signing is off.
+func newOutputSignerFromEnv() (*outputSigner, error) {
+	if os.Getenv("SIGN_OUTPUT") != "true" {
+		return nil, nil
+	}
+	keyFile := os.Getenv("SIGNING_KEY_FILE")
+	if keyFile == "" {
+		return nil, fmt.Errorf("SIGN_OUTPUT requires SIGNING_KEY_FILE")
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SIGNING_KEY_FILE: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("SIGNING_KEY_FILE must hold a PEM \"PRIVATE KEY\" block")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SIGNING_KEY_FILE: %w", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("SIGNING_KEY_FILE must hold an Ed25519 key, got %T", parsed)
+	}
+
+	sum := sha256.Sum256(key.Public().(ed25519.PublicKey))
+	return &outputSigner{key: key, keyID: hex.EncodeToString(sum[:8])}, nil
+}
+
+// sign returns the signature of a config.
+func (s *outputSigner) sign(config []byte) []byte {
+	return ed25519.Sign(s.key, config)
+}
+
+// signaturePrefix holds the signatures of the configs under mcp-configs/,
+// kept apart so listings of configs never see them.
+const signaturePrefix = "signatures/"
+
+// signatureObject returns the object name of a config's signature,
+// signatures/<config name>.sig.
+func signatureObject(configObject string) string {
+	return signaturePrefix + strings.TrimPrefix(configObject, "mcp-configs/") + ".sig"
+}
+
+// storeSignature signs a stored config and stores the raw signature under
+// signaturePrefix.
+func (s *ConversionService) storeSignature(ctx context.Context, configObject string, config []byte, opts storageOptions) (*ConfigSignature, error) {
+	signature := s.signer.sign(config)
+	url, err := s.saveToStorage(ctx, signatureObject(configObject), signature, "application/octet-stream", opts)
+	return &ConfigSignature{
+		Algorithm: signatureAlgorithm,
+		KeyID:     s.signer.keyID,
+		Value:     base64.StdEncoding.EncodeToString(signature),
+		URL:       url,
+	}, err
+}
+
+// handlePublicKey serves GET /pubkey, the public key verifiers check config
+// signatures with.
+func (s *ConversionService) handlePublicKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.signer == nil {
+		respondWithAdminError(w, "output signing is disabled", http.StatusNotFound)
+		return
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(s.signer.key.Public())
+	if err != nil {
+		respondWithAdminError(w, fmt.Sprintf("failed to encode public key: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"algorithm":  signatureAlgorithm,
+		"key_id":     s.signer.keyID,
+		"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	})
+}
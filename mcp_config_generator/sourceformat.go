@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Values of ConversionRequest.SourceFormat
+const (
+	sourceFormatAuto     = "auto"
+	sourceFormatOpenAPI  = "openapi"
+	sourceFormatMarkdown = "markdown"
+)
+
+var sourceFormats = []string{sourceFormatAuto, sourceFormatOpenAPI, sourceFormatMarkdown}
+
+// extractSpec returns the spec to convert and the format it was read as.
+// Markdown input, detected unless sourceFormat says otherwise, is replaced by
+// its first OpenAPI code block.
+func extractSpec(spec, sourceFormat string) (string, string, error) {
+	if sourceFormat != "" && !containsString(sourceFormats, sourceFormat) {
+		return "", "", newAPIError(http.StatusBadRequest, "source_format must be one of: %s", strings.Join(sourceFormats, ", "))
+	}
+	if sourceFormat == sourceFormatOpenAPI ||
+		(sourceFormat != sourceFormatMarkdown && !parser.IsMarkdownSpec([]byte(spec))) {
+		return spec, sourceFormatOpenAPI, nil
+	}
+
+	extracted, err := parser.ExtractMarkdownSpec([]byte(spec))
+	if errors.Is(err, parser.ErrNoMarkdownSpec) {
+		return "", "", newAPIError(http.StatusBadRequest, "%v", err)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return string(extracted), sourceFormatMarkdown, nil
+}
+
+// Values of ConversionRequest.SourceType and UploadRequest.SourceType, which
+// force how the input is read instead of detecting it
+const (
+	sourceTypeOpenAPI   = "openapi"
+	sourceTypeSwagger   = "swagger"
+	sourceTypeMarkdown  = "markdown"
+	sourceTypeMCPConfig = "mcp_config"
+)
+
+// conversionSourceTypes are the source types /convert can read. Swagger 2.0
+// documents and MCP configs can be uploaded but not converted.
+var conversionSourceTypes = []string{sourceTypeOpenAPI, sourceTypeMarkdown}
+
+// uploadSourceTypes are the source types /upload stores. Swagger documents
+// are stored with the OpenAPI specs.
+var uploadSourceTypes = []string{sourceTypeOpenAPI, sourceTypeSwagger, sourceTypeMCPConfig}
+
+// conversionSourceFormat returns the source_format a conversion reads its
+// input with: the forced source_type when given, else source_format.
+func conversionSourceFormat(req ConversionRequest) (string, error) {
+	if req.SourceType == "" {
+		return req.SourceFormat, nil
+	}
+	if !containsString(conversionSourceTypes, req.SourceType) {
+		return "", newAPIError(http.StatusBadRequest, "source_type %q can't be converted, must be one of: %s", req.SourceType, strings.Join(conversionSourceTypes, ", "))
+	}
+	if req.SourceFormat != "" && req.SourceFormat != sourceFormatAuto && req.SourceFormat != req.SourceType {
+		return "", newAPIError(http.StatusBadRequest, "source_type %q conflicts with source_format %q", req.SourceType, req.SourceFormat)
+	}
+	return req.SourceType, nil
+}
+
+// uploadFileType returns whether an upload is stored as an "openapi" spec
+// or an "mcp_config". A forced source type only needs the content to be a
+// JSON or YAML object; otherwise the type is detected from its keys.
+func uploadFileType(content, sourceType string) (string, error) {
+	if sourceType == "" {
+		return detectFileType(content)
+	}
+	if !containsString(uploadSourceTypes, sourceType) {
+		return "", newAPIError(http.StatusBadRequest, "source_type must be one of: %s", strings.Join(uploadSourceTypes, ", "))
+	}
+
+	var document map[string]interface{}
+	if json.Unmarshal([]byte(content), &document) != nil && yaml.Unmarshal([]byte(content), &document) != nil {
+		return "", fmt.Errorf("file is neither a valid JSON nor YAML object")
+	}
+	if sourceType == sourceTypeMCPConfig {
+		return sourceTypeMCPConfig, nil
+	}
+	return sourceTypeOpenAPI, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// headerMarkdown is a Markdown note with YAML-style header lines, one of
+// them openapi, and the spec in a code block. It is also a valid YAML
+// mapping with an openapi key, so it is detected as a spec itself.
+const headerMarkdown = "openapi: 3.0.0\n" +
+	"notes: |\n" +
+	"  The Orders API spec, kept with our notes.\n" +
+	"  ```yaml\n" +
+	"  openapi: 3.0.0\n" +
+	"  info:\n" +
+	"    title: Orders API\n" +
+	"    version: '1.0'\n" +
+	"  servers:\n" +
+	"    - url: https://api.example.com\n" +
+	"  paths:\n" +
+	"    /orders:\n" +
+	"      get:\n" +
+	"        operationId: listOrders\n" +
+	"        responses:\n" +
+	"          '200':\n" +
+	"            description: The orders\n" +
+	"  ```\n"
+
+func TestSourceTypeCorrectsConversionDetection(t *testing.T) {
+	// Detected as a spec, the note converts to no tools
+	spec, format, err := extractSpec(headerMarkdown, "")
+	if err != nil || format != sourceFormatOpenAPI || spec != headerMarkdown {
+		t.Fatalf("detected as %q (%v), want the whole document as openapi", format, err)
+	}
+	if output, err := convertOpenAPIToMCP(ConversionRequest{OpenAPISpec: spec, ServerName: "orders", Format: "yaml"}, nil); err == nil && output.ToolCount != 0 {
+		t.Fatalf("converting the detected document produced %d tool(s), want none", output.ToolCount)
+	}
+
+	// source_type markdown reads the code block instead
+	sourceFormat, err := conversionSourceFormat(ConversionRequest{SourceType: sourceTypeMarkdown})
+	if err != nil {
+		t.Fatalf("conversionSourceFormat: %v", err)
+	}
+	spec, format, err = extractSpec(headerMarkdown, sourceFormat)
+	if err != nil || format != sourceFormatMarkdown {
+		t.Fatalf("forced as %q (%v), want markdown", format, err)
+	}
+	output, err := convertOpenAPIToMCP(ConversionRequest{OpenAPISpec: spec, ServerName: "orders", Format: "yaml"}, nil)
+	if err != nil {
+		t.Fatalf("converting the forced document: %v", err)
+	}
+	if output.ToolCount != 1 || !strings.Contains(output.Config, "name: listOrders") {
+		t.Errorf("forced conversion produced %d tool(s):\n%s", output.ToolCount, output.Config)
+	}
+}
+
+func TestConversionSourceFormatRejects(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           ConversionRequest
+		expectedError string
+	}{
+		{
+			name:          "Type that can't be converted",
+			req:           ConversionRequest{SourceType: sourceTypeMCPConfig},
+			expectedError: `source_type "mcp_config" can't be converted, must be one of: openapi, markdown`,
+		},
+		{
+			name:          "Unknown type",
+			req:           ConversionRequest{SourceType: "graphql"},
+			expectedError: `source_type "graphql" can't be converted, must be one of: openapi, markdown`,
+		},
+		{
+			name:          "Conflicting source_format",
+			req:           ConversionRequest{SourceType: sourceTypeOpenAPI, SourceFormat: sourceFormatMarkdown},
+			expectedError: `source_type "openapi" conflicts with source_format "markdown"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := conversionSourceFormat(tc.req)
+			var apiErr *apiError
+			if !errors.As(err, &apiErr) || apiErr.status != http.StatusBadRequest {
+				t.Fatalf("error = %v, want a 400", err)
+			}
+			if err.Error() != tc.expectedError {
+				t.Errorf("error = %q, want %q", err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestSourceTypeCorrectsUploadDetection(t *testing.T) {
+	// An MCP config noting the OpenAPI version it was generated from
+	config := "openapi: 3.0.0\n" +
+		"server:\n" +
+		"  name: orders\n" +
+		"tools:\n" +
+		"  - name: listOrders\n"
+
+	detected, err := uploadFileType(config, "")
+	if err != nil || detected != sourceTypeOpenAPI {
+		t.Fatalf("detected %q (%v), want the wrong guess openapi", detected, err)
+	}
+	forced, err := uploadFileType(config, sourceTypeMCPConfig)
+	if err != nil || forced != sourceTypeMCPConfig {
+		t.Errorf("forced %q (%v), want mcp_config", forced, err)
+	}
+
+	// A Swagger document is stored with the OpenAPI specs
+	forced, err = uploadFileType("swagger: '2.0'\ninfo: {title: Orders, version: '1'}\n", sourceTypeSwagger)
+	if err != nil || forced != sourceTypeOpenAPI {
+		t.Errorf("forced swagger as %q (%v), want openapi", forced, err)
+	}
+
+	// Content that isn't a document at all is still rejected
+	if _, err := uploadFileType("- just\n- a list\n", sourceTypeOpenAPI); err == nil {
+		t.Error("forced upload of a list succeeded, want an error")
+	}
+
+	_, err = uploadFileType(config, "markdown")
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.status != http.StatusBadRequest {
+		t.Fatalf("unsupported source_type error = %v, want a 400", err)
+	}
+	if expected := "source_type must be one of: openapi, swagger, mcp_config"; err.Error() != expected {
+		t.Errorf("error = %q, want %q", err, expected)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// errSpecTooLarge is returned when a spec read from a URI exceeds
+// MAX_SPEC_BYTES.
+var errSpecTooLarge = errors.New("spec exceeds the size limit")
+
+// parseMaxSpecBytes parses MAX_SPEC_BYTES. 0, the default, means unlimited.
+func parseMaxSpecBytes(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("MAX_SPEC_BYTES must be a non-negative integer, got %q", value)
+	}
+	return limit, nil
+}
+
+// checkSpecSize rejects a spec larger than MAX_SPEC_BYTES with a 413, naming
+// the request field it was sent in.
+func (s *ConversionService) checkSpecSize(field, spec string) error {
+	if s.maxSpecBytes > 0 && int64(len(spec)) > s.maxSpecBytes {
+		return newAPIError(http.StatusRequestEntityTooLarge, "%s is %d bytes, the limit is %d", field, len(spec), s.maxSpecBytes)
+	}
+	return nil
+}
+
+// readLimited reads r to the end, stopping with errSpecTooLarge as soon as
+// more than limit bytes were read so an oversized object is never fully
+// buffered. A limit of 0 means unlimited.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, errSpecTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+)
+
+// specURIPattern matches an openapi_spec value that is a single URI rather
+// than an inline document.
+var specURIPattern = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*)://(\S+)$`)
+
+// parseSpecURI splits a gs:// or s3:// URI into scheme, bucket and object.
+// ok is false when value is not a URI at all, in which case it should be
+// treated as inline spec content.
+func parseSpecURI(value string) (scheme, bucket, object string, ok bool, err error) {
+	match := specURIPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return "", "", "", false, nil
+	}
+
+	scheme = strings.ToLower(match[1])
+	if scheme != "gs" && scheme != "s3" {
+		return "", "", "", true, fmt.Errorf("unsupported openapi_spec URI scheme %q, only gs:// and s3:// are supported", scheme)
+	}
+
+	bucket, object, _ = strings.Cut(match[2], "/")
+	if bucket == "" || object == "" {
+		return "", "", "", true, fmt.Errorf("openapi_spec URI must have the form %s://bucket/object", scheme)
+	}
+	return scheme, bucket, object, true, nil
+}
+
+// resolveSpecSource replaces a gs:// or s3:// openapi_spec with the content of
+// the referenced object. Inline specs are returned unchanged.
+func (s *ConversionService) resolveSpecSource(ctx context.Context, spec string) (string, error) {
+	scheme, bucket, object, isURI, err := parseSpecURI(spec)
+	if !isURI {
+		return spec, nil
+	}
+	if err != nil {
+		return "", newAPIError(http.StatusBadRequest, "%v", err)
+	}
+
+	if !containsString(s.specSourceBuckets, scheme+"://"+bucket) {
+		return "", newAPIError(http.StatusForbidden, "bucket %s://%s is not an allowed spec source", scheme, bucket)
+	}
+
+	debugf(ctx, "reading spec from %s://%s/%s", scheme, bucket, object)
+	var data []byte
+	switch scheme {
+	case "gs":
+		data, err = s.readGCSObject(ctx, bucket, object)
+	case "s3":
+		data, err = readS3Object(ctx, bucket, object, s.maxSpecBytes)
+	}
+	if errors.Is(err, errSpecTooLarge) {
+		return "", newAPIError(http.StatusRequestEntityTooLarge, "openapi_spec %s://%s/%s is larger than the limit of %d bytes", scheme, bucket, object, s.maxSpecBytes)
+	}
+	if err != nil {
+		return "", newAPIError(http.StatusBadGateway, "failed to read openapi_spec from %s://%s/%s: %v", scheme, bucket, object, err)
+	}
+
+	return string(data), nil
+}
+
+// readGCSObject reads an object with the service's storage client.
+func (s *ConversionService) readGCSObject(ctx context.Context, bucket, object string) ([]byte, error) {
+	reader, err := s.storageClient.Bucket(bucket).Object(object).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, fmt.Errorf("object does not exist")
+		}
+		return nil, err
+	}
+	defer reader.Close()
+
+	if s.maxSpecBytes > 0 && reader.Attrs.Size > s.maxSpecBytes {
+		return nil, errSpecTooLarge
+	}
+	return readLimited(reader, s.maxSpecBytes)
+}
+
+// readS3Object fetches an object of at most maxBytes from S3. Requests are signed with AWS
+// Signature Version 4 when AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are
+// set, otherwise the object must be publicly readable.
+func readS3Object(ctx context.Context, bucket, object string, maxBytes int64) ([]byte, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	objectURL := &url.URL{
+		Scheme: "https",
+		Host:   fmt.Sprintf("%s.s3.%s.amazonaws.com", bucket, region),
+		Path:   "/" + object,
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if accessKey := os.Getenv("AWS_ACCESS_KEY_ID"); accessKey != "" {
+		signS3Request(req, accessKey, os.Getenv("AWS_SECRET_ACCESS_KEY"), os.Getenv("AWS_SESSION_TOKEN"), region, time.Now().UTC())
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("S3 returned %s", resp.Status)
+	}
+	if maxBytes > 0 && resp.ContentLength > maxBytes {
+		return nil, errSpecTooLarge
+	}
+	return readLimited(resp.Body, maxBytes)
+}
+
+// signS3Request adds AWS Signature Version 4 headers to a bodiless S3 request.
+func signS3Request(req *http.Request, accessKey, secretKey, sessionToken, region string, now time.Time) {
+	const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", emptyPayloadHash)
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.URL.Host, emptyPayloadHash, amzDate)
+	if sessionToken != "" {
+		req.Header.Set("x-amz-security-token", sessionToken)
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += fmt.Sprintf("x-amz-security-token:%s\n", sessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, region)
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// splitByTag splits a bundle into one config per operation tag.
+const splitByTag = "tag"
+
+// splitByModes are the supported values of ConversionRequest.SplitBy.
+var splitByModes = []string{splitByTag}
+
+// untaggedSplit is the split that holds the tools without tags.
+const untaggedSplit = "untagged"
+
+// splitArchiveTime is the modification time of every entry of a split
+// archive, so the same conversion always produces the same bytes.
+var splitArchiveTime = time.Unix(0, 0).UTC()
+
+// SplitManifest is the manifest.json of a split bundle.
+type SplitManifest struct {
+	ServerName string `json:"server_name"`
+	Format     string `json:"format"`
+	// Files maps each tag to the config file holding its tools
+	Files map[string]string `json:"files"`
+}
+
+// configSplit is the config of the tools of one tag.
+type configSplit struct {
+	tag    string
+	config *models.MCPConfig
+}
+
+// splitConfigByTag groups the tools of a config by their first tag, in tag
+// order with untagged tools last. Each split keeps the server settings and
+// only the tag descriptions it uses.
+func splitConfigByTag(config *models.MCPConfig) []configSplit {
+	byTag := make(map[string][]models.Tool)
+	for _, tool := range config.Tools {
+		tag := untaggedSplit
+		if len(tool.Tags) > 0 {
+			tag = tool.Tags[0]
+		}
+		byTag[tag] = append(byTag[tag], tool)
+	}
+
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		if tag != untaggedSplit {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	if _, ok := byTag[untaggedSplit]; ok {
+		tags = append(tags, untaggedSplit)
+	}
+
+	splits := make([]configSplit, 0, len(tags))
+	for _, tag := range tags {
+		split := *config
+		split.Tools = byTag[tag]
+		split.Server.Tags = nil
+		for _, serverTag := range config.Server.Tags {
+			if serverTag.Name == tag {
+				split.Server.Tags = append(split.Server.Tags, serverTag)
+			}
+		}
+		splits = append(splits, configSplit{tag: tag, config: &split})
+	}
+	return splits
+}
+
+// splitFileName turns a tag into a file name made only of lower case
+// letters, digits and dashes, so archive entries can't escape the directory
+// they are unpacked into. used holds the names already taken; a clash gets
+// a numeric suffix.
+func splitFileName(tag, format string, used map[string]bool) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(tag) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			slug.WriteRune(r)
+		} else if slug.Len() > 0 && !strings.HasSuffix(slug.String(), "-") {
+			slug.WriteByte('-')
+		}
+	}
+	base := strings.TrimSuffix(slug.String(), "-")
+	if base == "" {
+		base = "tag"
+	}
+
+	name := fmt.Sprintf("%s.%s", base, format)
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s-%d.%s", base, i, format)
+	}
+	used[name] = true
+	return name
+}
+
+// writeSplitBundle renders one config per tag and packages them with a
+// manifest.json into a tar.gz archive. Entries are written in manifest
+// order with fixed metadata, so the archive is reproducible.
+func writeSplitBundle(req ConversionRequest, config *models.MCPConfig) ([]byte, error) {
+	manifest := SplitManifest{
+		ServerName: req.ServerName,
+		Format:     req.Format,
+		Files:      make(map[string]string),
+	}
+	var entries []bundleFile
+	used := make(map[string]bool)
+	for _, split := range splitConfigByTag(config) {
+		data, err := marshalConfig(split.config, req.Format, req.JSONIndent.String())
+		if err != nil {
+			return nil, err
+		}
+		name := splitFileName(split.tag, req.Format, used)
+		manifest.Files[split.tag] = name
+		entries = append(entries, bundleFile{Name: name, Data: data})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal split manifest: %w", err)
+	}
+	entries = append([]bundleFile{{Name: "manifest.json", Data: manifestData}}, entries...)
+
+	var buffer bytes.Buffer
+	compressor := gzip.NewWriter(&buffer)
+	archive := tar.NewWriter(compressor)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.Name,
+			Mode:     0644,
+			Size:     int64(len(entry.Data)),
+			ModTime:  splitArchiveTime,
+			Typeflag: tar.TypeReg,
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to add %s to bundle: %w", entry.Name, err)
+		}
+		if _, err := archive.Write(entry.Data); err != nil {
+			return nil, fmt.Errorf("failed to write %s to bundle: %w", entry.Name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+	if err := compressor.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"time"
+)
+
+// statsdClient sends metrics to a StatsD server over UDP. A nil client is a
+// valid no-op, which is what the service uses when STATSD_ADDR is unset.
+type statsdClient struct {
+	conn   net.Conn
+	prefix string
+}
+
+// newStatsdClient creates a client for the StatsD server at addr. Every
+// metric name is prefixed with prefix.
+func newStatsdClient(addr, prefix string) (*statsdClient, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to StatsD at %s: %w", addr, err)
+	}
+	return &statsdClient{conn: conn, prefix: prefix}, nil
+}
+
+// Incr increments a counter by one
+func (c *statsdClient) Incr(name string) {
+	if c == nil {
+		return
+	}
+	c.send(fmt.Sprintf("%s%s:1|c", c.prefix, name))
+}
+
+// Timing records a duration in milliseconds
+func (c *statsdClient) Timing(name string, d time.Duration) {
+	if c == nil {
+		return
+	}
+	c.send(fmt.Sprintf("%s%s:%d|ms", c.prefix, name, d.Milliseconds()))
+}
+
+// send writes a single metric packet. Metrics are best effort, so write
+// errors are logged and otherwise ignored.
+func (c *statsdClient) send(packet string) {
+	if _, err := c.conn.Write([]byte(packet)); err != nil {
+		log.Printf("Warning: Failed to send StatsD metric: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// Default storage connection limits. Go keeps only 2 idle connections per
+// host by default, so the parallel writes of a batch keep opening new TLS
+// connections to the storage API; these defaults let a batch reuse them.
+const (
+	defaultStorageMaxConns     = 64
+	defaultStorageMaxIdleConns = 64
+)
+
+// storageTransportFromEnv builds the HTTP transport of the storage client.
+// STORAGE_MAX_CONNS caps the connections to the storage API, 0 meaning no
+// limit, and STORAGE_MAX_IDLE_CONNS is how many of them are kept open
+// between requests.
+func storageTransportFromEnv() (*http.Transport, error) {
+	maxConns, err := storageConnLimitFromEnv("STORAGE_MAX_CONNS", defaultStorageMaxConns)
+	if err != nil {
+		return nil, err
+	}
+	maxIdleConns, err := storageConnLimitFromEnv("STORAGE_MAX_IDLE_CONNS", defaultStorageMaxIdleConns)
+	if err != nil {
+		return nil, err
+	}
+	if maxConns > 0 && maxIdleConns > maxConns {
+		return nil, fmt.Errorf("STORAGE_MAX_IDLE_CONNS (%d) must not exceed STORAGE_MAX_CONNS (%d)", maxIdleConns, maxConns)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxConnsPerHost = maxConns
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConns
+	transport.IdleConnTimeout = 90 * time.Second
+	return transport, nil
+}
+
+func storageConnLimitFromEnv(name string, defaultValue int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid %s %q, must be a non-negative integer", name, value)
+	}
+	return limit, nil
+}
+
+// newStorageClient creates the storage client on top of transport. A client
+// passed with option.WithHTTPClient is used as is, so the transport is
+// wrapped with the credentials the client would otherwise have added:
+// credentialsFile when set, else the default credentials. The storage
+// emulator takes no credentials.
+func newStorageClient(ctx context.Context, transport http.RoundTripper, credentialsFile string) (*storage.Client, error) {
+	if os.Getenv("STORAGE_EMULATOR_HOST") != "" {
+		return storage.NewClient(ctx, option.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+
+	opts := []option.ClientOption{option.WithScopes(storage.ScopeFullControl)}
+	if credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	}
+	authorized, err := htransport.NewTransport(ctx, transport, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage transport: %w", err)
+	}
+	return storage.NewClient(ctx, option.WithHTTPClient(&http.Client{Transport: authorized}))
+}
@@ -0,0 +1,57 @@
+package main
+
+import "fmt"
+
+// StoredFile is the outcome of one object write of a conversion.
+type StoredFile struct {
+	File    string `json:"file"`
+	Success bool   `json:"success"`
+	URL     string `json:"url,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// fileWrites records the object writes of a conversion. A failed write does
+// not stop the others, so the client learns exactly which files to retry.
+type fileWrites struct {
+	files  []StoredFile
+	failed int
+	// first is the first failed write, which decides the response status
+	first error
+}
+
+// objectExistsError is returned for a no_overwrite write of an object that
+// is already stored.
+type objectExistsError struct {
+	url string
+}
+
+func (e *objectExistsError) Error() string {
+	return fmt.Sprintf("object already exists at %s", e.url)
+}
+
+// record adds the result of a write and returns its URL. A write rejected
+// by no_overwrite keeps the URL of the existing object.
+func (w *fileWrites) record(file, url string, err error) string {
+	if err != nil {
+		stored := StoredFile{File: file, Error: err.Error()}
+		if exists, ok := err.(*objectExistsError); ok {
+			stored.URL = exists.url
+		}
+		w.files = append(w.files, stored)
+		w.failed++
+		if w.first == nil {
+			w.first = err
+		}
+		return ""
+	}
+	w.files = append(w.files, StoredFile{File: file, Success: true, URL: url})
+	return url
+}
+
+// err returns an error summarizing the failed writes, or nil.
+func (w *fileWrites) err() error {
+	if w.failed == 0 {
+		return nil
+	}
+	return newAPIError(storageErrorStatus(w.first), "Failed to save %d of %d file(s): %v", w.failed, len(w.files), w.first)
+}
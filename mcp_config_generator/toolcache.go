@@ -0,0 +1,85 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/higress-group/openapi-to-mcpserver/pkg/models"
+)
+
+// ToolCacheStats reports how many tools of a conversion came from the tool
+// cache and how many were converted.
+type ToolCacheStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+// toolCache is an in-memory LRU of converted tools shared by all
+// conversions. Keys are hashes of each operation's definition and the
+// conversion options, so unchanged operations of a resubmitted spec reuse
+// their tool.
+type toolCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type toolCacheEntry struct {
+	key  string
+	tool models.CachedTool
+}
+
+// newToolCache parses TOOL_CACHE_SIZE. It returns nil, meaning no cache,
+// when value is empty or 0.
+func newToolCache(value string) (*toolCache, error) {
+	if value == "" {
+		return nil, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("TOOL_CACHE_SIZE must be a non-negative integer, got %q", value)
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	return &toolCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}, nil
+}
+
+// Get returns the tool stored under key and marks it as recently used.
+func (c *toolCache) Get(key string) (models.CachedTool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return models.CachedTool{}, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*toolCacheEntry).tool, true
+}
+
+// Add stores a tool, evicting the least recently used one when full.
+func (c *toolCache) Add(key string, tool models.CachedTool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*toolCacheEntry).tool = tool
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&toolCacheEntry{key: key, tool: tool})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*toolCacheEntry).key)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// uiPage is the single-page conversion form served at / when ENABLE_UI is
+// true. It only calls /convert, so it needs nothing the API doesn't offer.
+//
+//go:embed ui/index.html
+var uiPage []byte
+
+// uiEnabledFromEnv parses ENABLE_UI.
+func uiEnabledFromEnv() (bool, error) {
+	value := os.Getenv("ENABLE_UI")
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("ENABLE_UI must be true or false")
+	}
+	return enabled, nil
+}
+
+// withUI serves the UI page for GET and HEAD requests to / and passes every
+// other request to next, which keeps the JSON 404 for unknown paths.
+func withUI(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			next(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'self' 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Write(uiPage)
+	}
+}
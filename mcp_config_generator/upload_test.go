@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUploadObjectNames(t *testing.T) {
+	service := &ConversionService{
+		bucketName:       "bucket",
+		storageDryRun:    true,
+		defaultFormat:    "yaml",
+		maxSpecBytes:     200,
+		outputExtensions: map[string]string{"yaml": "yml"},
+	}
+	config := "server:\n  name: orders\ntools:\n  - name: listOrders\n"
+	spec := "openapi: 3.0.0\ninfo:\n  title: Orders\n  version: '1'\npaths: {}\n"
+
+	tests := []struct {
+		name             string
+		request          UploadRequest
+		expectedStatus   int
+		expectedFileName string
+		expectedError    string
+	}{
+		{
+			name:             "MCP config with the OUTPUT_EXTENSIONS default",
+			request:          UploadRequest{FileContent: config, FileName: "orders"},
+			expectedStatus:   http.StatusOK,
+			expectedFileName: "mcp-configs/orders.yml",
+		},
+		{
+			name:             "MCP config with output_extension",
+			request:          UploadRequest{FileContent: config, FileName: "orders", OutputExtension: ".mcp.yaml"},
+			expectedStatus:   http.StatusOK,
+			expectedFileName: "mcp-configs/orders.mcp.yaml",
+		},
+		{
+			name:             "Spec keeps its format",
+			request:          UploadRequest{FileContent: spec, FileName: "orders", OutputExtension: "yml"},
+			expectedStatus:   http.StatusOK,
+			expectedFileName: "openapi/orders.yaml",
+		},
+		{
+			name:           "Invalid output_extension",
+			request:        UploadRequest{FileContent: config, FileName: "orders", OutputExtension: "mcp/yaml"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid output_extension: invalid extension \"mcp/yaml\"",
+		},
+		{
+			name:           "File over MAX_SPEC_BYTES",
+			request:        UploadRequest{FileContent: spec + strings.Repeat("#", 200)},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "file_content is 262 bytes, the limit is 200",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(tc.request)
+			if err != nil {
+				t.Fatalf("failed to marshal the request: %v", err)
+			}
+			recorder := httptest.NewRecorder()
+			service.handleUpload(recorder, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(string(body))))
+
+			var response UploadResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode the response: %v", err)
+			}
+			if recorder.Code != tc.expectedStatus {
+				t.Fatalf("status = %d (%s), want %d", recorder.Code, response.Error, tc.expectedStatus)
+			}
+			if response.FileName != tc.expectedFileName {
+				t.Errorf("file_name = %q, want %q", response.FileName, tc.expectedFileName)
+			}
+			if !strings.HasPrefix(response.Error, tc.expectedError) {
+				t.Errorf("error = %q, want %q", response.Error, tc.expectedError)
+			}
+		})
+	}
+}
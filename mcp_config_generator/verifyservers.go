@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Limits of verify_servers
+const (
+	// serverCheckTimeout bounds each connectivity check
+	serverCheckTimeout = 5 * time.Second
+	// maxVerifiedServers is how many server URLs of a spec are checked
+	maxVerifiedServers = 10
+	// serverCheckConcurrency is how many checks of a spec run at once
+	serverCheckConcurrency = 4
+)
+
+// specServerURLs returns the distinct base URLs declared at document, path and
+// operation level, with server variables replaced by their defaults. URLs
+// that still contain a template or are not absolute http(s) URLs are left
+// out, since there is nothing to connect to.
+func specServerURLs(doc *openapi3.T) []string {
+	if doc == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	add := func(servers openapi3.Servers) {
+		for _, server := range servers {
+			if server == nil {
+				continue
+			}
+			serverURL := server.URL
+			for name, variable := range server.Variables {
+				if variable != nil && variable.Default != "" {
+					serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+				}
+			}
+			if strings.ContainsAny(serverURL, "{}") {
+				continue
+			}
+			parsed, err := url.Parse(serverURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				continue
+			}
+			seen[serverURL] = true
+		}
+	}
+
+	add(doc.Servers)
+	for _, pathItem := range doc.Paths {
+		if pathItem == nil {
+			continue
+		}
+		add(pathItem.Servers)
+		for _, operation := range pathItem.Operations() {
+			if operation.Servers != nil {
+				add(*operation.Servers)
+			}
+		}
+	}
+
+	urls := make([]string, 0, len(seen))
+	for serverURL := range seen {
+		urls = append(urls, serverURL)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// serverVerifier checks the base server URLs of converted specs for
+// verify_servers. Specs are untrusted, so only hosts in VERIFY_SERVER_HOSTS
+// are contacted and only at public addresses, redirects aren't followed and
+// failures are reported without their cause.
+type serverVerifier struct {
+	hosts []string
+	// allowAddress reports whether an address may be connected to
+	allowAddress func(net.IP) bool
+	client       *http.Client
+}
+
+// newServerVerifierFromEnv parses VERIFY_SERVER_HOSTS, the hosts
+// verify_servers may contact. It returns nil, which disables
+// verify_servers, when none are set.
+func newServerVerifierFromEnv() *serverVerifier {
+	hosts := splitList(strings.ToLower(os.Getenv("VERIFY_SERVER_HOSTS")))
+	if len(hosts) == 0 {
+		return nil
+	}
+	return newServerVerifier(hosts, isPublicIP)
+}
+
+func newServerVerifier(hosts []string, allowAddress func(net.IP) bool) *serverVerifier {
+	v := &serverVerifier{hosts: hosts, allowAddress: allowAddress}
+	v.client = &http.Client{
+		Transport: &http.Transport{
+			DialContext:           v.dial,
+			TLSHandshakeTimeout:   serverCheckTimeout,
+			ResponseHeaderTimeout: serverCheckTimeout,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return v
+}
+
+// dial resolves the host itself and connects only when every address is
+// allowed, so a name can't point the check inside the network.
+func (v *serverVerifier) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses for %s", host)
+	}
+	for _, ip := range addrs {
+		if !v.allowAddress(ip.IP) {
+			return nil, fmt.Errorf("%s resolves to the non-public address %s", host, ip.IP)
+		}
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, net.JoinHostPort(addrs[0].IP.String(), port))
+}
+
+// isPublicIP reports whether ip is a routable public address: not
+// loopback, private, link-local such as the metadata server 169.254.169.254,
+// shared (100.64.0.0/10), unspecified or multicast.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil && (ip4[0] == 0 || ip4[0] == 100 && ip4[1]&0xc0 == 64) {
+		return false
+	}
+	return true
+}
+
+// verify sends a HEAD request to the first maxVerifiedServers URLs whose
+// host is allowed, at most serverCheckConcurrency at a time, and returns a
+// warning for each one that can't be reached. Any HTTP response, whatever
+// its status, counts as reachable.
+func (v *serverVerifier) verify(ctx context.Context, urls []string) []string {
+	var warnings []string
+	var checked []string
+	for _, serverURL := range urls {
+		parsed, err := url.Parse(serverURL)
+		if err != nil || !containsString(v.hosts, strings.ToLower(parsed.Hostname())) {
+			warnings = append(warnings, fmt.Sprintf("server %s was not checked, its host is not in VERIFY_SERVER_HOSTS", serverURL))
+			continue
+		}
+		checked = append(checked, serverURL)
+	}
+	if len(checked) > maxVerifiedServers {
+		warnings = append(warnings, fmt.Sprintf("checked only the first %d of %d server URLs", maxVerifiedServers, len(checked)))
+		checked = checked[:maxVerifiedServers]
+	}
+
+	unreachable := make([]string, len(checked))
+	slots := make(chan struct{}, serverCheckConcurrency)
+	var wg sync.WaitGroup
+	for i, serverURL := range checked {
+		wg.Add(1)
+		go func(i int, serverURL string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if err := v.check(ctx, serverURL); err != nil {
+				unreachable[i] = fmt.Sprintf("server %s is unreachable", serverURL)
+			}
+		}(i, serverURL)
+	}
+	wg.Wait()
+
+	for _, warning := range unreachable {
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// check returns an error when serverURL doesn't answer. The error isn't
+// reported to the caller, as it would tell what is reachable from here.
+func (v *serverVerifier) check(ctx context.Context, serverURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, serverCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, serverURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:2800::1":    true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::ffff:10.0.0.1": false,
+		"224.0.0.1":       false,
+	} {
+		if got := isPublicIP(net.ParseIP(address)); got != public {
+			t.Errorf("isPublicIP(%s) = %v, want %v", address, got, public)
+		}
+	}
+}
+
+func TestServerVerifier(t *testing.T) {
+	var redirected int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&redirected, 1)
+	}))
+	defer target.Close()
+	redirecting := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer redirecting.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedURL := closed.URL
+	closed.Close()
+
+	allowAll := func(net.IP) bool { return true }
+	verifier := newServerVerifier([]string{"127.0.0.1"}, allowAll)
+	warnings := verifier.verify(context.Background(), []string{redirecting.URL, closedURL, "http://internal.example.com"})
+	expected := []string{
+		"server http://internal.example.com was not checked, its host is not in VERIFY_SERVER_HOSTS",
+		fmt.Sprintf("server %s is unreachable", closedURL),
+	}
+	if strings.Join(warnings, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("warnings = %q, want %q", warnings, expected)
+	}
+	if atomic.LoadInt32(&redirected) != 0 {
+		t.Error("the redirect was followed")
+	}
+
+	// Loopback addresses aren't contacted, even for an allowed host
+	verifier = newServerVerifier([]string{"127.0.0.1"}, isPublicIP)
+	warnings = verifier.verify(context.Background(), []string{target.URL})
+	if expected := fmt.Sprintf("server %s is unreachable", target.URL); len(warnings) != 1 || warnings[0] != expected {
+		t.Errorf("warnings = %q, want %q", warnings, expected)
+	}
+
+	// Only the first maxVerifiedServers URLs are checked
+	var urls []string
+	for i := 0; i < maxVerifiedServers+2; i++ {
+		urls = append(urls, fmt.Sprintf("%s/v%d", target.URL, i))
+	}
+	verifier = newServerVerifier([]string{"127.0.0.1"}, allowAll)
+	warnings = verifier.verify(context.Background(), urls)
+	if expected := fmt.Sprintf("checked only the first %d of %d server URLs", maxVerifiedServers, len(urls)); len(warnings) != 1 || warnings[0] != expected {
+		t.Errorf("warnings = %q, want %q", warnings, expected)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// errWarmupPending is reported by the readiness check until warm-up is done.
+var errWarmupPending = errors.New("warm-up conversion still running")
+
+// warmupSpec is a tiny spec exercising parameters, a request body, formats
+// and enums, so the first real conversion doesn't pay for lazy
+// initialization and regex compilation in the parser and converter.
+const warmupSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Warm-up", "version": "1.0.0"},
+  "servers": [{"url": "https://warmup.example.com"}],
+  "paths": {
+    "/items/{itemId}": {
+      "get": {
+        "operationId": "getItem",
+        "tags": ["items"],
+        "parameters": [
+          {"name": "itemId", "in": "path", "required": true, "schema": {"type": "string", "format": "uuid"}},
+          {"name": "sort_order", "in": "query", "schema": {"type": "string", "enum": ["asc", "desc"]}}
+        ],
+        "responses": {
+          "200": {
+            "description": "The item",
+            "content": {"application/json": {"schema": {"type": "object", "properties": {"id": {"type": "string"}}}}}
+          }
+        }
+      },
+      "put": {
+        "operationId": "updateItem",
+        "parameters": [{"name": "itemId", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "requestBody": {
+          "content": {"application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}}
+        },
+        "responses": {"204": {"description": "Updated"}}
+      }
+    }
+  }
+}`
+
+// warmup primes the conversion libraries at startup when WARMUP is true.
+// Readiness reports not ready until it has run.
+type warmup struct {
+	done atomic.Bool
+}
+
+// newWarmupFromEnv parses WARMUP. It returns nil when warm-up is disabled.
+func newWarmupFromEnv() (*warmup, error) {
+	value := os.Getenv("WARMUP")
+	if value == "" {
+		return nil, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, errors.New("WARMUP must be true or false")
+	}
+	if !enabled {
+		return nil, nil
+	}
+	return &warmup{}, nil
+}
+
+// run converts the warm-up spec in both output formats without storing
+// anything. A failed warm-up is logged and still marks the instance ready,
+// since it only affects latency.
+func (w *warmup) run() {
+	started := time.Now()
+	for _, format := range supportedFormats {
+		req := ConversionRequest{
+			OpenAPISpec:      warmupSpec,
+			ServerName:       "warmup",
+			Format:           format,
+			IncludePathGlobs: []string{"/items/**"},
+			ParamNameCase:    "camel",
+			FormatMapping:    defaultFormatMapping(),
+		}
+		if _, err := convertOpenAPIToMCP(req, nil); err != nil {
+			log.Printf("Warning: Warm-up conversion failed: %v", err)
+			break
+		}
+	}
+	w.done.Store(true)
+	log.Printf("Warm-up finished in %s", time.Since(started).Round(time.Millisecond))
+}
+
+// ready reports whether warm-up has finished. A nil warmup is always ready.
+func (w *warmup) ready() bool {
+	return w == nil || w.done.Load()
+}
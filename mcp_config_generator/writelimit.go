@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// errStorageWriteRate is returned by saveToStorage when a write could not be
+// scheduled within the limiter's maximum wait or the request's deadline.
+var errStorageWriteRate = errors.New("storage write rate limit reached")
+
+// defaultWriteMaxWait is how long a write waits for its slot unless
+// STORAGE_WRITE_MAX_WAIT is set.
+const defaultWriteMaxWait = 10 * time.Second
+
+// writeLimiter paces storage writes to at most one per interval. Writes over
+// the limit wait for their slot instead of failing, unless the slot comes
+// after maxWait or the context deadline. Incoming requests have no deadline
+// of their own, so maxWait is what bounds the queue.
+type writeLimiter struct {
+	interval time.Duration
+	maxWait  time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newWriteLimiter parses STORAGE_WRITE_RPS and STORAGE_WRITE_MAX_WAIT. It
+// returns nil, meaning no limit, when value is empty.
+func newWriteLimiter(value, maxWaitValue string) (*writeLimiter, error) {
+	if value == "" {
+		return nil, nil
+	}
+	rps, err := strconv.ParseFloat(value, 64)
+	if err != nil || rps <= 0 {
+		return nil, fmt.Errorf("STORAGE_WRITE_RPS must be a positive number, got %q", value)
+	}
+	limiter := &writeLimiter{interval: time.Duration(float64(time.Second) / rps), maxWait: defaultWriteMaxWait}
+	if maxWaitValue != "" {
+		limiter.maxWait, err = time.ParseDuration(maxWaitValue)
+		if err != nil || limiter.maxWait <= 0 {
+			return nil, fmt.Errorf("STORAGE_WRITE_MAX_WAIT must be a positive duration, got %q", maxWaitValue)
+		}
+	}
+	return limiter, nil
+}
+
+// wait blocks until the caller may write.
+func (l *writeLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	deadline := now.Add(l.maxWait)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if slot.After(deadline) {
+		l.mu.Unlock()
+		return fmt.Errorf("%w: next write slot is %s away", errStorageWriteRate, slot.Sub(now).Round(time.Millisecond))
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", errStorageWriteRate, ctx.Err())
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWriteLimiterMaxWait(t *testing.T) {
+	limiter, err := newWriteLimiter("20", "120ms")
+	if err != nil {
+		t.Fatalf("newWriteLimiter: %v", err)
+	}
+
+	// Slots are 50ms apart: the first three fit in the wait, the fourth doesn't
+	started := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.wait(context.Background()); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed < 90*time.Millisecond {
+		t.Errorf("three writes took %s, want them paced 50ms apart", elapsed)
+	}
+	limiter.next = time.Now().Add(200 * time.Millisecond)
+	if err := limiter.wait(context.Background()); !errors.Is(err, errStorageWriteRate) {
+		t.Errorf("write past STORAGE_WRITE_MAX_WAIT = %v, want errStorageWriteRate", err)
+	}
+
+	// A shorter request deadline wins
+	limiter.next = time.Now().Add(60 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.wait(ctx); !errors.Is(err, errStorageWriteRate) {
+		t.Errorf("write past the request deadline = %v, want errStorageWriteRate", err)
+	}
+
+	if _, err := newWriteLimiter("10", "soon"); err == nil {
+		t.Error("newWriteLimiter accepted an invalid STORAGE_WRITE_MAX_WAIT")
+	}
+}